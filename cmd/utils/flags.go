@@ -6,6 +6,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
 	"github.com/Yocoin15/Yocoin_Sources/nov2019"
 	"io/ioutil"
@@ -29,6 +30,7 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/core/vm"
 	"github.com/Yocoin15/Yocoin_Sources/crypto"
 	"github.com/Yocoin15/Yocoin_Sources/dashboard"
+	"github.com/Yocoin15/Yocoin_Sources/graphql"
 	"github.com/Yocoin15/Yocoin_Sources/les"
 	"github.com/Yocoin15/Yocoin_Sources/log"
 	"github.com/Yocoin15/Yocoin_Sources/metrics"
@@ -40,6 +42,7 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/p2p/nat"
 	"github.com/Yocoin15/Yocoin_Sources/p2p/netutil"
 	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
 	whisper "github.com/Yocoin15/Yocoin_Sources/whisper/whisperv6"
 	"github.com/Yocoin15/Yocoin_Sources/yoc"
 	"github.com/Yocoin15/Yocoin_Sources/yoc/downloader"
@@ -112,6 +115,10 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -244,6 +251,10 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolBlocklistFlag = cli.StringFlag{
+		Name:  "txpool.blocklist",
+		Usage: "JSON file of address rules to keep transactions out of the pool and mined blocks",
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -295,6 +306,15 @@ var (
 		Usage: "Percentage of cache memory allowance to use for trie pruning",
 		Value: 25,
 	}
+	CacheSnapshotFlag = cli.IntFlag{
+		Name:  "cache.snapshot",
+		Usage: "Percentage of cache memory allowance to use for the state snapshot (with --snapshot)",
+		Value: 10,
+	}
+	SnapshotFlag = cli.BoolFlag{
+		Name:  "snapshot",
+		Usage: "Maintain a flat state snapshot to accelerate account and storage reads",
+	}
 	TrieCacheGenFlag = cli.IntFlag{
 		Name:  "trie-cache-gens",
 		Usage: "Number of trie node generations to keep in memory",
@@ -345,6 +365,19 @@ var (
 		Name:  "vmdebug",
 		Usage: "Record information useful for VM and contract debugging",
 	}
+	VMTraceIndexFlag = cli.BoolFlag{
+		Name:  "vmtraceindex",
+		Usage: "Index the addresses in the call traces of the blocks for trace_filter (requires --gcmode=archive)",
+	}
+	VMTraceCacheFlag = cli.StringFlag{
+		Name:  "vmtracecache",
+		Usage: "Tracer to trace the imported blocks with in the background, caching the results for the tracing APIs",
+	}
+	VMTraceCacheBlocksFlag = cli.Uint64Flag{
+		Name:  "vmtracecache.blocks",
+		Usage: "Number of recent blocks to keep the background traces of",
+		Value: yoc.DefaultConfig.TraceCacheBlocks,
+	}
 	// Logging and debug settings
 	YocStatsURLFlag = cli.StringFlag{
 		Name:  "yocstats",
@@ -388,6 +421,43 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	RPCBatchLimitFlag = cli.IntFlag{
+		Name:  "rpc.batchlimit",
+		Usage: "Maximum number of requests in a batch (0 = unlimited)",
+		Value: rpc.DefaultLimits.BatchItems,
+	}
+	RPCResponseLimitFlag = cli.IntFlag{
+		Name:  "rpc.responselimit",
+		Usage: "Maximum size in bytes of a (batch) response (0 = unlimited)",
+		Value: rpc.DefaultLimits.ResponseSize,
+	}
+	RPCRateLimitFlag = cli.StringFlag{
+		Name:  "rpc.ratelimit",
+		Usage: "Comma separated requests per second allowed per client and namespace (e.g. 'eth=50,debug=1,*=100')",
+		Value: "",
+	}
+	RPCRateBurstFlag = cli.IntFlag{
+		Name:  "rpc.rateburst",
+		Usage: "Maximum burst of requests per client and namespace (0 = namespace rate)",
+	}
+	RPCHeavyMethodsFlag = cli.StringFlag{
+		Name:  "rpc.heavymethods",
+		Usage: "Comma separated list of expensive methods whose concurrency is capped ('*' suffix matches prefixes)",
+		Value: strings.Join(rpc.DefaultLimits.HeavyMethods, ","),
+	}
+	RPCHeavyLimitFlag = cli.IntFlag{
+		Name:  "rpc.heavylimit",
+		Usage: "Maximum number of expensive methods executing concurrently (0 = unlimited)",
+		Value: rpc.DefaultLimits.HeavyConcurrency,
+	}
+	RPCJWTSecretFlag = cli.StringFlag{
+		Name:  "rpc.jwtsecret",
+		Usage: "File holding the hex encoded 32 byte secret of the JWT tokens accepted on HTTP and WS-RPC",
+	}
+	RPCAPIKeysFlag = cli.StringFlag{
+		Name:  "rpc.apikeys",
+		Usage: "JSON file mapping the API keys accepted on HTTP and WS-RPC to their allowed namespaces",
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -420,6 +490,30 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server (unauthenticated and not rate limited)",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphql.addr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphql.port",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphql.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -733,6 +827,82 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setRPCLimits configures the resource limits of the HTTP and WebSocket RPC
+// endpoints from the set command line flags.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCBatchLimitFlag.Name) {
+		cfg.RPCLimits.BatchItems = ctx.GlobalInt(RPCBatchLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCResponseLimitFlag.Name) {
+		cfg.RPCLimits.ResponseSize = ctx.GlobalInt(RPCResponseLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCRateLimitFlag.Name) {
+		rates := make(map[string]float64)
+		for _, entry := range splitAndTrim(ctx.GlobalString(RPCRateLimitFlag.Name)) {
+			parts := strings.Split(entry, "=")
+			if len(parts) != 2 {
+				Fatalf("Option %s: invalid entry %q, want namespace=rate", RPCRateLimitFlag.Name, entry)
+			}
+			rate, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+			if err != nil {
+				Fatalf("Option %s: invalid rate %q: %v", RPCRateLimitFlag.Name, parts[1], err)
+			}
+			rates[strings.TrimSpace(parts[0])] = rate
+		}
+		cfg.RPCLimits.RateLimits = rates
+	}
+	if ctx.GlobalIsSet(RPCRateBurstFlag.Name) {
+		cfg.RPCLimits.RateBurst = ctx.GlobalInt(RPCRateBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCHeavyMethodsFlag.Name) {
+		cfg.RPCLimits.HeavyMethods = splitAndTrim(ctx.GlobalString(RPCHeavyMethodsFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCHeavyLimitFlag.Name) {
+		cfg.RPCLimits.HeavyConcurrency = ctx.GlobalInt(RPCHeavyLimitFlag.Name)
+	}
+}
+
+// setRPCAuth configures the authentication required from HTTP and WebSocket RPC
+// clients from the set command line flags.
+func setRPCAuth(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCJWTSecretFlag.Name) {
+		cfg.JWTSecret = ctx.GlobalString(RPCJWTSecretFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCAPIKeysFlag.Name) {
+		file := ctx.GlobalString(RPCAPIKeysFlag.Name)
+		blob, err := ioutil.ReadFile(file)
+		if err != nil {
+			Fatalf("Failed to read API keys: %v", err)
+		}
+		var keys map[string][]string
+		if err := json.Unmarshal(blob, &keys); err != nil {
+			Fatalf("Failed to parse API keys %s: %v", file, err)
+		}
+		cfg.APIKeys = keys
+	}
+}
+
+// setGraphQL creates the GraphQL listener interface string from the set
+// command line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -900,6 +1070,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setRPCLimits(ctx, cfg)
+	setRPCAuth(ctx, cfg)
+	setGraphQL(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	switch {
@@ -941,6 +1114,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolBlocklistFlag.Name) {
+		cfg.Blocklist = ctx.GlobalString(TxPoolBlocklistFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1068,6 +1244,9 @@ func SetYocConfig(ctx *cli.Context, stack *node.Node, cfg *yoc.Config) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
 
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -1077,6 +1256,9 @@ func SetYocConfig(ctx *cli.Context, stack *node.Node, cfg *yoc.Config) {
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
+	if ctx.GlobalBool(SnapshotFlag.Name) {
+		cfg.SnapshotCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheSnapshotFlag.Name) / 100
+	}
 	if ctx.GlobalIsSet(MinerThreadsFlag.Name) {
 		cfg.MinerThreads = ctx.GlobalInt(MinerThreadsFlag.Name)
 	}
@@ -1093,6 +1275,15 @@ func SetYocConfig(ctx *cli.Context, stack *node.Node, cfg *yoc.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
 	}
+	if ctx.GlobalIsSet(VMTraceIndexFlag.Name) {
+		cfg.TraceIndex = ctx.GlobalBool(VMTraceIndexFlag.Name)
+	}
+	if ctx.GlobalIsSet(VMTraceCacheFlag.Name) {
+		cfg.TraceCache = ctx.GlobalString(VMTraceCacheFlag.Name)
+	}
+	if ctx.GlobalIsSet(VMTraceCacheBlocksFlag.Name) {
+		cfg.TraceCacheBlocks = ctx.GlobalUint64(VMTraceCacheBlocksFlag.Name)
+	}
 
 	// Override any default configs for hard coded networks.
 	switch {
@@ -1191,6 +1382,14 @@ func RegisterYocStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService is a utility function to construct a new service and
+// register it against a node.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) {
+	if err := graphql.RegisterGraphQLService(stack, endpoint, cors, vhosts, timeouts); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // SetupNetwork configures the system for either the main net or some test network.
 func SetupNetwork(ctx *cli.Context) {
 	// TODO(fjl): move target gas limit into config
@@ -1224,11 +1423,15 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) yocdb.Database {
 		cache   = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 		handles = makeDatabaseHandles()
 	)
-	name := "chaindata"
+	var (
+		chainDb yocdb.Database
+		err     error
+	)
 	if ctx.GlobalBool(LightModeFlag.Name) {
-		name = "lightchaindata"
+		chainDb, err = stack.OpenDatabase("lightchaindata", cache, handles)
+	} else {
+		chainDb, err = stack.OpenDatabaseWithFreezer("chaindata", cache, handles, ctx.GlobalString(AncientFlag.Name), "")
 	}
-	chainDb, err := stack.OpenDatabase(name, cache, handles)
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
@@ -14,12 +14,16 @@ import (
 
 	"github.com/Yocoin15/Yocoin_Sources/cmd/utils"
 	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
 	"github.com/Yocoin15/Yocoin_Sources/console"
 	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
 	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/state/pruner"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/event"
 	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/params"
 	"github.com/Yocoin15/Yocoin_Sources/trie"
 	"github.com/Yocoin15/Yocoin_Sources/yoc/downloader"
 	"github.com/Yocoin15/Yocoin_Sources/yocdb"
@@ -28,6 +32,25 @@ import (
 )
 
 var (
+	verifyFromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Value: 1,
+		Usage: "First block to re-execute",
+	}
+	verifyToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last block to re-execute (default = current head)",
+	}
+	verifyMigrationFlag = cli.StringFlag{
+		Name:  "migration",
+		Value: "on",
+		Usage: `Whether to apply the nov2019 migration ("on" or "off")`,
+	}
+	pruneBloomSizeFlag = cli.Uint64Flag{
+		Name:  "bloomfilter.size",
+		Value: 2048,
+		Usage: "Megabytes of memory allocated to the bloom filter marking the retained state (minimum 256)",
+	}
 	initCommand = cli.Command{
 		Action:    utils.MigrateFlags(initGenesis),
 		Name:      "init",
@@ -156,6 +179,75 @@ Remove blockchain and state databases`,
 The arguments are interpreted as block numbers or hashes.
 Use "ethereum dump 0" to dump the genesis block.`,
 	}
+	verifyRangeCommand = cli.Command{
+		Action:    utils.MigrateFlags(verifyRange),
+		Name:      "verify-range",
+		Usage:     "Re-execute a range of blocks and compare them with the local chain",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.GCModeFlag,
+			verifyFromFlag,
+			verifyToFlag,
+			verifyMigrationFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The verify-range command re-executes the canonical blocks between --from and --to
+on top of the stored state of the block preceding --from, and compares the
+resulting state roots and receipts with the ones of the local chain. The first
+divergent block is reported along with its first divergent transaction and the
+accounts differing between the two post-block states.
+
+With --migration off, the irregular state changes and reward beneficiary
+overrides of the chain configuration are disabled, showing where the migration
+made the chain deviate from plain execution.
+
+The state of the block preceding --from must be available, so verifying old
+ranges requires an archive node.`,
+	}
+	dumpIrregularCommand = cli.Command{
+		Action:    utils.MigrateFlags(dumpIrregular),
+		Name:      "dump-irregular",
+		Usage:     "Dump the balance rewrites applied to the local chain",
+		ArgsUsage: "[<blockNumFirst> <blockNumLast>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.LightModeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The dump-irregular command prints an audit record for every account balance the
+node rewrote outside of transaction execution (irregular state changes) in the
+canonical chain, one JSON object per line. Without arguments the entire chain is
+scanned. Blocks that were fast-synced instead of executed carry no records.`,
+	}
+	pruneStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(pruneState),
+		Name:      "prune-state",
+		Usage:     "Delete the state data not reachable from the recent blocks",
+		ArgsUsage: "[<stateRoot>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			pruneBloomSizeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The prune-state command deletes all the trie nodes and contract codes from the
+database which are not reachable from the retained states. By default the states
+of the last 128 blocks that were flushed to disk are retained (or the most recent
+older one if none were, rewinding the chain to it), along with the genesis state.
+If a state root is given, only that state is retained.
+
+The reachable state is marked in a bloom filter of --bloomfilter.size megabytes,
+which is persisted before anything is deleted. If the deletion is interrupted, it
+is resumed by the next invocation of the command or the next start of the node.
+
+The node must not be running while the state is pruned.`,
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -233,7 +325,7 @@ func importChain(ctx *cli.Context) error {
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	db := chainDb.(*yocdb.LDBDatabase)
+	db := rawdb.KeyValueStore(chainDb).(*yocdb.LDBDatabase)
 
 	stats, err := db.LDB().GetProperty("leveldb.stats")
 	if err != nil {
@@ -324,7 +416,7 @@ func importPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*yocdb.LDBDatabase)
+	diskdb := rawdb.KeyValueStore(utils.MakeChainDatabase(ctx, stack)).(*yocdb.LDBDatabase)
 
 	start := time.Now()
 	if err := utils.ImportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -340,7 +432,7 @@ func exportPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*yocdb.LDBDatabase)
+	diskdb := rawdb.KeyValueStore(utils.MakeChainDatabase(ctx, stack)).(*yocdb.LDBDatabase)
 
 	start := time.Now()
 	if err := utils.ExportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -390,7 +482,7 @@ func copyDb(ctx *cli.Context) error {
 	// Compact the entire database to remove any sync overhead
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = chainDb.(*yocdb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
+	if err = rawdb.KeyValueStore(chainDb).(*yocdb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
@@ -453,6 +545,166 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+// verifyRange re-executes a range of canonical blocks and reports the first one
+// that couldn't be reproduced.
+func verifyRange(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	config := *chain.Config()
+	switch ctx.String(verifyMigrationFlag.Name) {
+	case "on":
+	case "off":
+		config.IrregularStateChanges = nil
+		config.CreditFreezes = nil
+		config.Yochash = withoutBeneficiaries(config.Yochash)
+	default:
+		utils.Fatalf("--%s must be either 'on' or 'off'", verifyMigrationFlag.Name)
+	}
+	from, to := ctx.Uint64(verifyFromFlag.Name), chain.CurrentBlock().NumberU64()
+	if ctx.IsSet(verifyToFlag.Name) {
+		to = ctx.Uint64(verifyToFlag.Name)
+	}
+	start := time.Now()
+	div, err := chain.VerifyChainRange(&config, from, to)
+	if err != nil {
+		utils.Fatalf("Verification failed: %v", err)
+	}
+	if div == nil {
+		fmt.Printf("Blocks %d-%d reproduced exactly in %v\n", from, to, time.Since(start))
+		return nil
+	}
+	printDivergence(div)
+	return fmt.Errorf("block %d diverged", div.Number)
+}
+
+// withoutBeneficiaries returns a copy of the yochash config whose reward schedule
+// pays every coinbase the same.
+func withoutBeneficiaries(config *params.YochashConfig) *params.YochashConfig {
+	if config == nil {
+		return nil
+	}
+	plain := &params.YochashConfig{RewardSchedule: make([]*params.RewardPeriod, len(config.RewardSchedule))}
+	for i, period := range config.RewardSchedule {
+		plain.RewardSchedule[i] = &params.RewardPeriod{
+			Block:              period.Block,
+			Reward:             period.Reward,
+			UncleRewardFormula: period.UncleRewardFormula,
+		}
+	}
+	return plain
+}
+
+// printDivergence prints a human readable report of a divergent block.
+func printDivergence(div *core.BlockDivergence) {
+	fmt.Printf("Block %d (%x) diverged\n", div.Number, div.Hash)
+	fmt.Printf("  canonical root:   %x\n", div.Root)
+	if div.Err != nil {
+		fmt.Printf("  re-execution:     failed: %v\n", div.Err)
+	} else {
+		fmt.Printf("  re-executed root: %x\n", div.NewRoot)
+	}
+	if div.TxIndex < 0 {
+		fmt.Println("  all transaction receipts match, the divergence is outside of transaction execution")
+	} else {
+		fmt.Printf("  first divergent transaction: #%d (%x)\n", div.TxIndex, div.TxHash)
+		for _, pair := range []struct {
+			name    string
+			receipt *types.Receipt
+		}{{"canonical", div.Receipt}, {"re-executed", div.NewReceipt}} {
+			if pair.receipt == nil {
+				fmt.Printf("    %s receipt: unavailable\n", pair.name)
+				continue
+			}
+			fmt.Printf("    %s receipt: status %d, post state %x, cumulative gas %d, logs %d\n",
+				pair.name, pair.receipt.Status, pair.receipt.PostState, pair.receipt.CumulativeGasUsed, len(pair.receipt.Logs))
+		}
+	}
+	if div.DiffErr != nil {
+		fmt.Printf("  state diff unavailable: %v\n", div.DiffErr)
+		return
+	}
+	fmt.Printf("  state diff (%d accounts, canonical -> re-executed):\n", len(div.Accounts))
+	for _, diff := range div.Accounts {
+		account := fmt.Sprintf("%x", diff.Hash)
+		if diff.Address != nil {
+			account = diff.Address.Hex()
+		}
+		fmt.Printf("    %s: %s -> %s\n", account, formatAccount(diff.Old), formatAccount(diff.New))
+	}
+}
+
+// formatAccount returns a one line summary of a state account.
+func formatAccount(account *state.Account) string {
+	if account == nil {
+		return "<missing>"
+	}
+	return fmt.Sprintf("{nonce %d, balance %v, root %x, code %x}", account.Nonce, account.Balance, account.Root, account.CodeHash)
+}
+
+// dumpIrregular prints the audit records of the balance rewrites applied to the
+// canonical chain in the requested block range.
+func dumpIrregular(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	first, last := uint64(0), chain.CurrentBlock().NumberU64()
+	switch len(ctx.Args()) {
+	case 0:
+	case 2:
+		var ferr, lerr error
+		first, ferr = strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+		last, lerr = strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		if ferr != nil || lerr != nil {
+			utils.Fatalf("Dump error in parsing parameters: block number not an integer")
+		}
+	default:
+		utils.Fatalf("This command requires either no arguments or a block range.")
+	}
+	for _, rewrite := range chain.GetBalanceRewrites(first, last) {
+		out, err := json.Marshal(rewrite)
+		if err != nil {
+			utils.Fatalf("Failed to encode balance rewrite: %v", err)
+		}
+		fmt.Println(string(out))
+	}
+	return nil
+}
+
+// pruneState deletes the stale state data from the chain database.
+func pruneState(ctx *cli.Context) error {
+	var root common.Hash
+	switch len(ctx.Args()) {
+	case 0:
+	case 1:
+		blob, err := hexutil.Decode(ctx.Args().First())
+		if err != nil || len(blob) != common.HashLength {
+			utils.Fatalf("Invalid state root: %s", ctx.Args().First())
+		}
+		root = common.BytesToHash(blob)
+	default:
+		utils.Fatalf("This command accepts at most one argument.")
+	}
+	stack := makeFullNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	chainDb := rawdb.KeyValueStore(db).(*yocdb.LDBDatabase)
+
+	var (
+		bloomPath = stack.ResolvePath(pruner.BloomFileName)
+		bloomSize = ctx.Uint64(pruneBloomSizeFlag.Name)
+		start     = time.Now()
+	)
+	if err := pruner.NewPruner(chainDb, bloomPath, bloomSize).Prune(root); err != nil {
+		utils.Fatalf("Failed to prune state: %v", err)
+	}
+	fmt.Printf("State pruning done in %v\n", time.Since(start))
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
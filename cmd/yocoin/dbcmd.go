@@ -0,0 +1,65 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Yocoin15/Yocoin_Sources/cmd/utils"
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	dbCommand = cli.Command{
+		Name:     "db",
+		Usage:    "Low level database operations",
+		Category: "DATABASE COMMANDS",
+		Subcommands: []cli.Command{
+			dbInspectCommand,
+		},
+	}
+	dbInspectCommand = cli.Command{
+		Action:    utils.MigrateFlags(inspect),
+		Name:      "inspect",
+		Usage:     "Inspect the storage size for each type of data in the database",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+		},
+		Description: `
+The inspect command iterates over the entire chain database and classifies every
+entry by its key prefix (headers, bodies, receipts, transaction index, bloombits,
+trie nodes, preimages, snapshots, clique snapshots, les cost statistics, ...),
+printing the number of entries and their total size per category. The sizes of
+the ancient store tables are listed too if one is attached.`,
+	}
+)
+
+func inspect(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	stats, err := rawdb.InspectDatabase(db)
+	if err != nil {
+		utils.Fatalf("Failed to inspect database: %v", err)
+	}
+	var (
+		total common.StorageSize
+		w     = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	)
+	fmt.Fprintln(w, "DATABASE\tCATEGORY\tITEMS\tSIZE")
+	for _, stat := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", stat.Database, stat.Category, stat.Count, stat.Size.String())
+		total += stat.Size
+	}
+	fmt.Fprintf(w, "\t\tTotal\t%s\n", total.String())
+	return w.Flush()
+}
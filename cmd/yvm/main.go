@@ -126,6 +126,7 @@ func init() {
 		disasmCommand,
 		runCommand,
 		stateTestCommand,
+		transitionCommand,
 	}
 }
 
@@ -0,0 +1,442 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+	"github.com/Yocoin15/Yocoin_Sources/common/math"
+	"github.com/Yocoin15/Yocoin_Sources/consensus"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/misc"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+	"github.com/Yocoin15/Yocoin_Sources/tests"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+var (
+	InputAllocFlag = cli.StringFlag{
+		Name:  "input.alloc",
+		Usage: "JSON file with the pre-state allocation",
+		Value: "alloc.json",
+	}
+	InputEnvFlag = cli.StringFlag{
+		Name:  "input.env",
+		Usage: "JSON file with the block environment",
+		Value: "env.json",
+	}
+	InputTxsFlag = cli.StringFlag{
+		Name:  "input.txs",
+		Usage: "File with the transactions, either a JSON list or hex encoded RLP",
+		Value: "txs.json",
+	}
+	OutputBasedirFlag = cli.StringFlag{
+		Name:  "output.basedir",
+		Usage: "Directory to place the output files in",
+	}
+	OutputAllocFlag = cli.StringFlag{
+		Name:  "output.alloc",
+		Usage: "File to write the post-state allocation to, or 'stdout'/'stderr'",
+		Value: "alloc.json",
+	}
+	OutputResultFlag = cli.StringFlag{
+		Name:  "output.result",
+		Usage: "File to write the execution result to, or 'stdout'/'stderr'",
+		Value: "result.json",
+	}
+	ForkFlag = cli.StringFlag{
+		Name:  "state.fork",
+		Usage: "Name of the fork rules to apply",
+		Value: "Byzantium",
+	}
+	ChainIDFlag = cli.Int64Flag{
+		Name:  "state.chainid",
+		Usage: "Chain identifier to sign and verify the transactions with (defaults to the one of the fork)",
+	}
+	RewardFlag = cli.Int64Flag{
+		Name:  "state.reward",
+		Usage: "Block reward to credit the coinbase with, or -1 to skip it",
+		Value: -1,
+	}
+)
+
+var transitionCommand = cli.Command{
+	Action:    transitionCmd,
+	Name:      "transition",
+	Usage:     "executes a full state transition",
+	ArgsUsage: " ",
+	Description: `The transition command applies the given transactions on top of a JSON pre-state
+under the given block environment and fork rules, and writes out the post-state
+allocation along with the receipts, roots and rejected transactions.`,
+	Flags: []cli.Flag{
+		InputAllocFlag,
+		InputEnvFlag,
+		InputTxsFlag,
+		OutputBasedirFlag,
+		OutputAllocFlag,
+		OutputResultFlag,
+		ForkFlag,
+		ChainIDFlag,
+		RewardFlag,
+	},
+}
+
+// transitionEnv is the block environment the transactions are executed in.
+type transitionEnv struct {
+	Coinbase    common.Address                      `json:"currentCoinbase"`
+	Difficulty  *math.HexOrDecimal256               `json:"currentDifficulty"`
+	GasLimit    math.HexOrDecimal64                 `json:"currentGasLimit"`
+	Number      math.HexOrDecimal64                 `json:"currentNumber"`
+	Timestamp   math.HexOrDecimal64                 `json:"currentTimestamp"`
+	BlockHashes map[math.HexOrDecimal64]common.Hash `json:"blockHashes,omitempty"`
+}
+
+// transitionTx is a transaction of the input, either signed already or to be
+// signed with its secret key.
+type transitionTx struct {
+	tx  *types.Transaction
+	key *ecdsa.PrivateKey
+}
+
+// UnmarshalJSON decodes a signed transaction, or the fields of an unsigned one
+// along with the secret key to sign it with.
+func (t *transitionTx) UnmarshalJSON(input []byte) error {
+	var key struct {
+		SecretKey *hexutil.Bytes `json:"secretKey"`
+	}
+	if err := json.Unmarshal(input, &key); err != nil {
+		return err
+	}
+	if key.SecretKey == nil {
+		t.tx = new(types.Transaction)
+		return json.Unmarshal(input, t.tx)
+	}
+	var dec struct {
+		Nonce    hexutil.Uint64  `json:"nonce"`
+		GasPrice *hexutil.Big    `json:"gasPrice"`
+		Gas      hexutil.Uint64  `json:"gas"`
+		To       *common.Address `json:"to"`
+		Value    *hexutil.Big    `json:"value"`
+		Input    hexutil.Bytes   `json:"input"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	priv, err := crypto.ToECDSA(*key.SecretKey)
+	if err != nil {
+		return fmt.Errorf("invalid secret key: %v", err)
+	}
+	price, value := new(big.Int), new(big.Int)
+	if dec.GasPrice != nil {
+		price = dec.GasPrice.ToInt()
+	}
+	if dec.Value != nil {
+		value = dec.Value.ToInt()
+	}
+	if dec.To == nil {
+		t.tx = types.NewContractCreation(uint64(dec.Nonce), value, uint64(dec.Gas), price, dec.Input)
+	} else {
+		t.tx = types.NewTransaction(uint64(dec.Nonce), *dec.To, value, uint64(dec.Gas), price, dec.Input)
+	}
+	t.key = priv
+	return nil
+}
+
+// rejectedTx is a transaction which could not be included in the block.
+type rejectedTx struct {
+	Index int    `json:"index"`
+	Err   string `json:"error"`
+}
+
+// transitionResult is the outcome of the state transition.
+type transitionResult struct {
+	StateRoot   common.Hash    `json:"stateRoot"`
+	TxRoot      common.Hash    `json:"txRoot"`
+	ReceiptRoot common.Hash    `json:"receiptRoot"`
+	LogsHash    common.Hash    `json:"logsHash"`
+	Bloom       types.Bloom    `json:"logsBloom"`
+	Receipts    types.Receipts `json:"receipts"`
+	Rejected    []rejectedTx   `json:"rejected"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+}
+
+// transitionChain is the chain context of the state transition, resolving the
+// block hashes of the environment for the BLOCKHASH opcode.
+type transitionChain struct {
+	engine consensus.Engine
+	hashes map[uint64]common.Hash
+}
+
+// Engine implements core.ChainContext, returning a fake engine attributing the
+// blocks to the coinbase of their header, the one of the environment.
+func (c *transitionChain) Engine() consensus.Engine {
+	return c.engine
+}
+
+// GetHeader implements core.ChainContext, returning a header only linking to
+// its parent if the block hash is known.
+func (c *transitionChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if number == 0 || c.hashes[number] != hash {
+		return nil
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(number), ParentHash: c.hashes[number-1]}
+}
+
+func transitionCmd(ctx *cli.Context) error {
+	// Configure the yocoin logger
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(ctx.GlobalInt(VerbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
+
+	// Resolve the fork rules to apply
+	fork := ctx.String(ForkFlag.Name)
+	rules, ok := tests.Forks[fork]
+	if !ok {
+		return tests.UnsupportedForkError{Name: fork}
+	}
+	config := *rules
+	if ctx.IsSet(ChainIDFlag.Name) {
+		config.ChainID = big.NewInt(ctx.Int64(ChainIDFlag.Name))
+	}
+	// Load the pre-state, the environment and the transactions
+	var (
+		alloc core.GenesisAlloc
+		env   transitionEnv
+	)
+	if err := readJSONFile(ctx.String(InputAllocFlag.Name), &alloc); err != nil {
+		return fmt.Errorf("failed reading alloc: %v", err)
+	}
+	if err := readJSONFile(ctx.String(InputEnvFlag.Name), &env); err != nil {
+		return fmt.Errorf("failed reading env: %v", err)
+	}
+	if env.Difficulty == nil {
+		return errors.New("missing currentDifficulty in env")
+	}
+	txs, err := readTransitionTxs(ctx.String(InputTxsFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed reading txs: %v", err)
+	}
+	// Configure the YVM logger
+	vmConfig := vm.Config{}
+	if ctx.GlobalBool(MachineFlag.Name) {
+		logConfig := &vm.LogConfig{
+			DisableMemory: ctx.GlobalBool(DisableMemoryFlag.Name),
+			DisableStack:  ctx.GlobalBool(DisableStackFlag.Name),
+		}
+		vmConfig = vm.Config{Debug: true, Tracer: NewJSONLogger(logConfig, os.Stderr)}
+	}
+	statedb, result, err := applyTransition(&config, alloc, &env, txs, ctx.Int64(RewardFlag.Name), vmConfig)
+	if err != nil {
+		return err
+	}
+
+	// Write out the post-state and the execution result
+	dump := statedb.RawDump()
+	post, err := dumpAlloc(&dump)
+	if err != nil {
+		return err
+	}
+	basedir := ctx.String(OutputBasedirFlag.Name)
+	if err := writeJSONFile(basedir, ctx.String(OutputAllocFlag.Name), post); err != nil {
+		return err
+	}
+	return writeJSONFile(basedir, ctx.String(OutputResultFlag.Name), result)
+}
+
+// applyTransition executes the transactions on top of a fresh state created from
+// the pre-state allocation, rejecting those which can't be included in the block.
+func applyTransition(config *params.ChainConfig, alloc core.GenesisAlloc, env *transitionEnv, txs []*transitionTx, reward int64, vmConfig vm.Config) (*state.StateDB, *transitionResult, error) {
+	statedb := tests.MakePreState(yocdb.NewMemDatabase(), alloc)
+
+	chain := &transitionChain{
+		engine: yochash.NewFaker(),
+		hashes: make(map[uint64]common.Hash, len(env.BlockHashes)),
+	}
+	for number, hash := range env.BlockHashes {
+		chain.hashes[uint64(number)] = hash
+	}
+	header := &types.Header{
+		Coinbase:   env.Coinbase,
+		Difficulty: (*big.Int)(env.Difficulty),
+		GasLimit:   uint64(env.GasLimit),
+		Number:     new(big.Int).SetUint64(uint64(env.Number)),
+		Time:       new(big.Int).SetUint64(uint64(env.Timestamp)),
+	}
+	if env.Number > 0 {
+		header.ParentHash = chain.hashes[uint64(env.Number)-1]
+	}
+	if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(header.Number) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+	misc.ApplyIrregularStateChanges(config, statedb, header.Number)
+
+	var (
+		signer   = types.MakeSigner(config, header.Number)
+		gaspool  = new(core.GasPool).AddGas(header.GasLimit)
+		gasUsed  uint64
+		included types.Transactions
+		receipts = types.Receipts{}
+		rejected = []rejectedTx{}
+	)
+	for i, t := range txs {
+		tx := t.tx
+		if t.key != nil {
+			signed, err := types.SignTx(tx, signer, t.key)
+			if err != nil {
+				rejected = append(rejected, rejectedTx{Index: i, Err: err.Error()})
+				continue
+			}
+			tx = signed
+		}
+		var (
+			snapshot = statedb.Snapshot()
+			gas      = gaspool.Gas()
+		)
+		statedb.Prepare(tx.Hash(), common.Hash{}, len(included))
+		receipt, _, err := core.ApplyTransaction(config, chain, &env.Coinbase, gaspool, statedb, header, tx, &gasUsed, vmConfig)
+		if err != nil {
+			// Undo any partial changes of the rejected transaction
+			statedb.RevertToSnapshot(snapshot)
+			*gaspool = core.GasPool(gas)
+
+			log.Info("Rejected transaction", "index", i, "hash", tx.Hash(), "err", err)
+			rejected = append(rejected, rejectedTx{Index: i, Err: err.Error()})
+			continue
+		}
+		included = append(included, tx)
+		receipts = append(receipts, receipt)
+	}
+	if reward >= 0 {
+		statedb.AddBalance(env.Coinbase, big.NewInt(reward))
+	}
+	root, err := statedb.Commit(config.IsEIP158(header.Number))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to commit state: %v", err)
+	}
+	var logs []*types.Log
+	for _, receipt := range receipts {
+		logs = append(logs, receipt.Logs...)
+	}
+	blob, err := rlp.EncodeToBytes(logs)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &transitionResult{
+		StateRoot:   root,
+		TxRoot:      types.DeriveSha(included),
+		ReceiptRoot: types.DeriveSha(receipts),
+		LogsHash:    crypto.Keccak256Hash(blob),
+		Bloom:       types.CreateBloom(receipts),
+		Receipts:    receipts,
+		Rejected:    rejected,
+		GasUsed:     hexutil.Uint64(gasUsed),
+	}
+	return statedb, result, nil
+}
+
+// readJSONFile decodes the JSON content of a file.
+func readJSONFile(path string, v interface{}) error {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(src, v)
+}
+
+// readTransitionTxs loads the transactions to execute, either from a JSON list
+// or from the hex encoding of their RLP list, optionally quoted.
+func readTransitionTxs(path string) ([]*transitionTx, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	src = bytes.TrimSpace(src)
+	if bytes.HasPrefix(src, []byte("[")) {
+		var txs []*transitionTx
+		if err := json.Unmarshal(src, &txs); err != nil {
+			return nil, err
+		}
+		return txs, nil
+	}
+	blob, err := hexutil.Decode(strings.Trim(string(src), `"`))
+	if err != nil {
+		return nil, err
+	}
+	var signed types.Transactions
+	if err := rlp.DecodeBytes(blob, &signed); err != nil {
+		return nil, err
+	}
+	txs := make([]*transitionTx, len(signed))
+	for i, tx := range signed {
+		txs[i] = &transitionTx{tx: tx}
+	}
+	return txs, nil
+}
+
+// dumpAlloc converts a state dump into a genesis allocation.
+func dumpAlloc(dump *state.Dump) (core.GenesisAlloc, error) {
+	alloc := make(core.GenesisAlloc, len(dump.Accounts))
+	for addr, account := range dump.Accounts {
+		balance, ok := new(big.Int).SetString(account.Balance, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %q of %s", account.Balance, addr)
+		}
+		genesis := core.GenesisAccount{
+			Balance: balance,
+			Nonce:   account.Nonce,
+			Code:    common.Hex2Bytes(account.Code),
+		}
+		if len(account.Storage) > 0 {
+			genesis.Storage = make(map[common.Hash]common.Hash, len(account.Storage))
+			for key, value := range account.Storage {
+				// Storage values are stored RLP encoded in the trie
+				var content []byte
+				if err := rlp.DecodeBytes(common.Hex2Bytes(value), &content); err != nil {
+					return nil, fmt.Errorf("invalid storage %s of %s: %v", key, addr, err)
+				}
+				genesis.Storage[common.HexToHash(key)] = common.BytesToHash(content)
+			}
+		}
+		alloc[common.HexToAddress(addr)] = genesis
+	}
+	return alloc, nil
+}
+
+// writeJSONFile writes the indented JSON encoding of a value into a file of the
+// base directory, or to the standard output or error.
+func writeJSONFile(basedir, name string, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "stdout":
+		_, err = fmt.Fprintln(os.Stdout, string(out))
+	case "stderr":
+		_, err = fmt.Fprintln(os.Stderr, string(out))
+	default:
+		err = ioutil.WriteFile(filepath.Join(basedir, name), out, 0644)
+	}
+	return err
+}
@@ -0,0 +1,192 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+	"github.com/Yocoin15/Yocoin_Sources/common/math"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+	"github.com/Yocoin15/Yocoin_Sources/tests"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+var (
+	testKey, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr     = crypto.PubkeyToAddress(testKey.PublicKey)
+	testRecv     = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testCoinbase = common.HexToAddress("0x2000000000000000000000000000000000000002")
+)
+
+// newTransitionEnv returns the block environment of the tests.
+func newTransitionEnv() *transitionEnv {
+	return &transitionEnv{
+		Coinbase:   testCoinbase,
+		Difficulty: (*math.HexOrDecimal256)(big.NewInt(0x20000)),
+		GasLimit:   math.HexOrDecimal64(params.GenesisGasLimit),
+		Number:     1,
+		Timestamp:  1000,
+	}
+}
+
+// secretKeyTx returns the JSON input of a transaction to be signed with testKey.
+func secretKeyTx(nonce uint64, to common.Address, value int64) string {
+	return fmt.Sprintf(`{"secretKey": "%s", "nonce": "%#x", "gasPrice": "0x1", "gas": "%#x", "to": "%s", "value": "%#x", "input": "0x"}`,
+		hexutil.Encode(crypto.FromECDSA(testKey)), nonce, params.TxGas, to.Hex(), value)
+}
+
+// readTestTxs writes the transactions input into a temporary file and reads it
+// back the way the transition command does.
+func readTestTxs(t *testing.T, input string) []*transitionTx {
+	dir, err := ioutil.TempDir("", "yvm-transition-")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "txs")
+	if err := ioutil.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatalf("failed to write transactions: %v", err)
+	}
+	txs, err := readTransitionTxs(path)
+	if err != nil {
+		t.Fatalf("failed to read transactions: %v", err)
+	}
+	return txs
+}
+
+// Tests that transactions are applied whether signed in JSON or RLP encoding or
+// signed with their secret key, and that invalid ones are rejected.
+func TestTransition(t *testing.T) {
+	config := tests.Forks["Byzantium"]
+	signer := types.MakeSigner(config, big.NewInt(1))
+
+	signed := make([]*types.Transaction, 3)
+	for i, nonce := range []uint64{0, 1, 5} {
+		tx := types.NewTransaction(nonce, testRecv, big.NewInt(1000), params.TxGas, big.NewInt(1), nil)
+		signed[i], _ = types.SignTx(tx, signer, testKey)
+	}
+	first, err := json.Marshal(signed[0])
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	blob, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		t.Fatalf("failed to encode transactions: %v", err)
+	}
+	inputs := map[string]string{
+		"json": fmt.Sprintf("[%s, %s, %s]", first, secretKeyTx(1, testRecv, 1000), secretKeyTx(5, testRecv, 1000)),
+		"rlp":  fmt.Sprintf("%q", hexutil.Encode(blob)),
+	}
+	alloc := core.GenesisAlloc{testAddr: {Balance: big.NewInt(params.YOC)}}
+
+	var roots []common.Hash
+	for name, input := range inputs {
+		statedb, result, err := applyTransition(config, alloc, newTransitionEnv(), readTestTxs(t, input), 1000, vm.Config{})
+		if err != nil {
+			t.Fatalf("%s: failed to apply transition: %v", name, err)
+		}
+		if len(result.Receipts) != 2 || uint64(result.GasUsed) != 2*params.TxGas {
+			t.Errorf("%s: included mismatch: have %d receipts, %d gas", name, len(result.Receipts), result.GasUsed)
+		}
+		if len(result.Rejected) != 1 || result.Rejected[0].Index != 2 {
+			t.Errorf("%s: rejected mismatch: have %+v", name, result.Rejected)
+		}
+		if result.TxRoot != types.DeriveSha(types.Transactions(signed[:2])) {
+			t.Errorf("%s: transaction root mismatch", name)
+		}
+		// The post-state must reflect the included transactions only
+		dump := statedb.RawDump()
+		post, err := dumpAlloc(&dump)
+		if err != nil {
+			t.Fatalf("%s: failed to convert post-state: %v", name, err)
+		}
+		fee := int64(2 * params.TxGas)
+		if have := post[testAddr]; have.Nonce != 2 || have.Balance.Cmp(big.NewInt(params.YOC-2000-fee)) != 0 {
+			t.Errorf("%s: sender mismatch: have nonce %d, balance %v", name, have.Nonce, have.Balance)
+		}
+		if have := post[testRecv].Balance; have.Cmp(big.NewInt(2000)) != 0 {
+			t.Errorf("%s: recipient balance mismatch: have %v, want 2000", name, have)
+		}
+		if have := post[testCoinbase].Balance; have.Cmp(big.NewInt(fee+1000)) != 0 {
+			t.Errorf("%s: coinbase balance mismatch: have %v, want %d", name, have, fee+1000)
+		}
+		// Recreating the state from the post-state must yield the same root
+		if root := tests.MakePreState(yocdb.NewMemDatabase(), post).IntermediateRoot(true); root != result.StateRoot {
+			t.Errorf("%s: post-state root mismatch: have %x, want %x", name, root, result.StateRoot)
+		}
+		roots = append(roots, result.StateRoot)
+	}
+	if roots[0] != roots[1] {
+		t.Errorf("state root mismatch between encodings: %x != %x", roots[0], roots[1])
+	}
+}
+
+// Tests that the fork rules are applied: touching an empty account leaves it in
+// the state before EIP158 only.
+func TestTransitionForks(t *testing.T) {
+	empty := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	input := fmt.Sprintf("[%s]", secretKeyTx(0, empty, 0))
+	alloc := core.GenesisAlloc{testAddr: {Balance: big.NewInt(params.YOC)}}
+
+	roots := make(map[string]common.Hash)
+	for fork, kept := range map[string]bool{"Homestead": true, "Byzantium": false} {
+		statedb, result, err := applyTransition(tests.Forks[fork], alloc, newTransitionEnv(), readTestTxs(t, input), -1, vm.Config{})
+		if err != nil {
+			t.Fatalf("%s: failed to apply transition: %v", fork, err)
+		}
+		if len(result.Rejected) != 0 {
+			t.Fatalf("%s: transactions rejected: %+v", fork, result.Rejected)
+		}
+		if have := statedb.Exist(empty); have != kept {
+			t.Errorf("%s: empty account existence mismatch: have %v, want %v", fork, have, kept)
+		}
+		roots[fork] = result.StateRoot
+	}
+	if roots["Homestead"] == roots["Byzantium"] {
+		t.Errorf("state root unchanged by the fork rules: %x", roots["Homestead"])
+	}
+}
+
+// Tests that the irregular state changes and credit freezes of the block are
+// applied before its transactions.
+func TestTransitionIrregularStateChanges(t *testing.T) {
+	config := *tests.Forks["Byzantium"]
+	config.IrregularStateChanges = []*params.IrregularStateChange{{
+		Block: big.NewInt(1),
+		Moves: []params.IrregularBalance{{From: testRecv, To: testAddr, Amount: big.NewInt(params.YOC)}},
+	}}
+	config.CreditFreezes = []*params.CreditFreeze{{Address: testCoinbase, Block: big.NewInt(1)}}
+
+	// The sender is only funded by the irregular state change of the block
+	input := fmt.Sprintf("[%s]", secretKeyTx(0, testRecv, 1000))
+	alloc := core.GenesisAlloc{testRecv: {Balance: big.NewInt(params.YOC)}}
+
+	statedb, result, err := applyTransition(&config, alloc, newTransitionEnv(), readTestTxs(t, input), 1000, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to apply transition: %v", err)
+	}
+	if len(result.Rejected) != 0 {
+		t.Fatalf("transactions rejected: %+v", result.Rejected)
+	}
+	if have := statedb.GetBalance(testRecv); have.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want 1000", have)
+	}
+	if have := statedb.GetBalance(testCoinbase); have.Sign() != 0 {
+		t.Errorf("frozen coinbase credited: have %v", have)
+	}
+}
@@ -0,0 +1,69 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package misc
+
+import (
+	"math/big"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+)
+
+// ApplyIrregularStateChanges modifies the state database according to every
+// irregular state change the chain configuration schedules for the given block,
+// moving balances between the listed accounts and then zeroing the frozen ones.
+// Afterwards it freezes the credits to the accounts the configuration freezes in
+// the block, for the rest of the block. The returned audit records carry the
+// block number but no block hash.
+func ApplyIrregularStateChanges(config *params.ChainConfig, statedb *state.StateDB, number *big.Int) []*types.BalanceRewrite {
+	statedb.FreezeCredits(nil)
+
+	var rewrites []*types.BalanceRewrite
+	for _, change := range config.IrregularStateChangesAt(number) {
+		rewrites = append(rewrites, ApplyIrregularStateChange(statedb, change)...)
+	}
+	statedb.FreezeCredits(config.CreditFreezesAt(number))
+	return rewrites
+}
+
+// ApplyIrregularStateChange modifies the state database according to a single
+// irregular state change, returning an audit record for every balance touched.
+func ApplyIrregularStateChange(statedb *state.StateDB, change *params.IrregularStateChange) []*types.BalanceRewrite {
+	var rewrites []*types.BalanceRewrite
+	record := func(addr common.Address, reason string, old *big.Int) {
+		rewrites = append(rewrites, &types.BalanceRewrite{
+			BlockNumber: change.Block.Uint64(),
+			Address:     addr,
+			Reason:      reason,
+			OldBalance:  old,
+			NewBalance:  new(big.Int).Set(statedb.GetBalance(addr)),
+		})
+	}
+	for _, move := range change.Moves {
+		amount := new(big.Int).Set(statedb.GetBalance(move.From))
+		if move.Amount != nil && move.Amount.Cmp(amount) < 0 {
+			amount.Set(move.Amount)
+		}
+		if !statedb.Exist(move.To) {
+			statedb.CreateAccount(move.To)
+		}
+		old := new(big.Int).Set(statedb.GetBalance(move.From))
+		statedb.SubBalance(move.From, amount)
+		record(move.From, types.RewriteMoveOut, old)
+
+		old = new(big.Int).Set(statedb.GetBalance(move.To))
+		statedb.AddBalance(move.To, amount)
+		record(move.To, types.RewriteMoveIn, old)
+	}
+	for _, addr := range change.Freezes {
+		if statedb.Exist(addr) {
+			old := new(big.Int).Set(statedb.GetBalance(addr))
+			statedb.SetBalance(addr, new(big.Int))
+			record(addr, types.RewriteFreeze, old)
+		}
+	}
+	return rewrites
+}
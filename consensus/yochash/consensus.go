@@ -7,8 +7,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"github.com/Yocoin15/Yocoin_Sources/log"
-	"github.com/Yocoin15/Yocoin_Sources/nov2019"
 	"math/big"
 	"runtime"
 	"time"
@@ -25,10 +23,10 @@ import (
 
 // Yochash proof-of-work protocol constants.
 var (
-	FrontierBlockReward    *big.Int = big.NewInt(5e+18) // Block reward in wei for successfully mining a block
-	ByzantiumBlockReward   *big.Int = big.NewInt(3e+18) // Block reward in wei for successfully mining a block upward from Byzantium
-	maxUncles                       = 2                 // Maximum number of uncles allowed in a single block
-	allowedFutureBlockTime          = 15 * time.Second  // Max time from current time allowed for blocks, before they're considered future blocks
+	FrontierBlockReward    *big.Int = params.FrontierBlockReward  // Block reward in wei for successfully mining a block
+	ByzantiumBlockReward   *big.Int = params.ByzantiumBlockReward // Block reward in wei for successfully mining a block upward from Byzantium
+	maxUncles                       = 2                           // Maximum number of uncles allowed in a single block
+	allowedFutureBlockTime          = 15 * time.Second            // Max time from current time allowed for blocks, before they're considered future blocks
 )
 
 // Various error messages to mark blocks invalid. These should be private to
@@ -519,51 +517,28 @@ var (
 
 // AccumulateRewards credits the coinbase of the given block with the mining
 // reward. The total reward consists of the static block reward and rewards for
-// included uncles. The coinbase of each uncle block is also rewarded.
+// included uncles. The coinbase of each uncle block is also rewarded. Both are
+// defined by the reward period of the chain's schedule the block falls into.
 func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
 	// Select the correct block reward based on chain progression
-	blockReward := FrontierBlockReward
-	if config.IsByzantium(header.Number) {
-		blockReward = ByzantiumBlockReward
-	}
-
-	if nov2019.GetNov2019MigrationManager().Is1810(header.Coinbase) {
-		// тоже ограничимся
-		if nov2019.GetNov2019MigrationManager().IfOnSuitableHeight(header.Number.Int64(), common.Address{}) {
-			newBlockReward, cb1Shot := nov2019.GetNov2019MigrationManager().Get1810()
-
-			blockReward = blockReward.Set(newBlockReward)
-			if newBlockReward.Int64() < 0 && !state.HasSuicided(header.Coinbase) {
-				log.Info(nov2019.LOG_PREFIX+"scde about to trigger", "nov2019", true)
-				state.GetJournal().Append(state.
-					MakeSuicideChange(header.Coinbase, state.HasSuicided(header.Coinbase), state.GetBalance(header.Coinbase)))
-			} else if newBlockReward.Int64() < 0 && state.HasSuicided(header.Coinbase) {
-				log.Info(nov2019.LOG_PREFIX+"scde happened, closing one1 mode", "nov2019", true)
-				if cb1Shot != nil {
-					cb1Shot()
-				}
-			} else {
-				if cb1Shot != nil {
-					cb1Shot()
-				}
-			}
-
-		}
-	}
+	period := config.RewardPeriodAt(header.Number)
+	blockReward := period.Reward
 
 	// Accumulate the rewards for the miner and any included uncles
-	reward := new(big.Int).Set(blockReward)
-
-	r := new(big.Int)
-	for _, uncle := range uncles {
-		r.Add(uncle.Number, big8)
-		r.Sub(r, header.Number)
-		r.Mul(r, blockReward)
-		r.Div(r, big8)
-		state.AddBalance(uncle.Coinbase, r, header.Number)
-
-		r.Div(blockReward, big32)
-		reward.Add(reward, r) // TODO а что если награда будет отрицательной? :)
+	reward := new(big.Int).Set(period.BlockReward(header.Coinbase))
+
+	if period.UncleFormula() == params.UncleRewardStandard {
+		r := new(big.Int)
+		for _, uncle := range uncles {
+			r.Add(uncle.Number, big8)
+			r.Sub(r, header.Number)
+			r.Mul(r, blockReward)
+			r.Div(r, big8)
+			state.AddBalance(uncle.Coinbase, r)
+
+			r.Div(blockReward, big32)
+			reward.Add(reward, r)
+		}
 	}
-	state.AddBalance(header.Coinbase, reward, header.Number)
+	state.AddBalance(header.Coinbase, reward)
 }
@@ -10,9 +10,12 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/common/math"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
 )
 
 type diffTest struct {
@@ -71,3 +74,39 @@ func TestCalcDifficulty(t *testing.T) {
 		}
 	}
 }
+
+// Tests that block and uncle rewards follow the reward schedule of the chain.
+func TestAccumulateRewards(t *testing.T) {
+	var (
+		miner   = common.Address{0x01}
+		special = common.Address{0x02}
+		uncler  = common.Address{0x03}
+	)
+	config := &params.ChainConfig{Yochash: &params.YochashConfig{RewardSchedule: []*params.RewardPeriod{
+		{Block: big.NewInt(0), Reward: big.NewInt(3200), Beneficiaries: []*params.RewardBeneficiary{{Coinbase: special, Reward: big.NewInt(10000)}}},
+		{Block: big.NewInt(100), Reward: big.NewInt(3200), UncleRewardFormula: params.UncleRewardNone},
+	}}}
+	tests := []struct {
+		number   int64
+		coinbase common.Address
+		miner    int64
+		uncle    int64
+	}{
+		{10, miner, 3200 + 100, 3200 * 7 / 8},    // Standard formula, uncle one block back
+		{10, special, 10000 + 100, 3200 * 7 / 8}, // Beneficiary override, uncles still use the period reward
+		{100, miner, 3200, 0},                    // No uncle rewards
+	}
+	for i, tt := range tests {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(yocdb.NewMemDatabase()))
+		header := &types.Header{Number: big.NewInt(tt.number), Coinbase: tt.coinbase}
+		uncle := &types.Header{Number: big.NewInt(tt.number - 1), Coinbase: uncler}
+
+		accumulateRewards(config, statedb, header, []*types.Header{uncle})
+		if balance := statedb.GetBalance(tt.coinbase); balance.Int64() != tt.miner {
+			t.Errorf("test %d: miner reward mismatch: have %v, want %v", i, balance, tt.miner)
+		}
+		if balance := statedb.GetBalance(uncler); balance.Int64() != tt.uncle {
+			t.Errorf("test %d: uncle reward mismatch: have %v, want %v", i, balance, tt.uncle)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+)
+
+// AddressPolicy decides which accounts transactions are allowed to touch at a
+// given block height.
+type AddressPolicy interface {
+	// Check returns ErrBlockedAddress if a transaction from the given sender to
+	// the given recipient (nil for contract creations) is rejected at number.
+	Check(number *big.Int, from common.Address, to *common.Address) error
+
+	// Rules returns the rules of the policy in force at number.
+	Rules(number *big.Int) []*params.AddressRule
+}
+
+// addressPolicy is an AddressPolicy enforcing a static list of address rules.
+type addressPolicy struct {
+	rules []*params.AddressRule
+}
+
+// NewAddressPolicy creates an address policy enforcing the union of the given
+// rule lists.
+func NewAddressPolicy(rules ...[]*params.AddressRule) AddressPolicy {
+	policy := new(addressPolicy)
+	for _, list := range rules {
+		policy.rules = append(policy.rules, list...)
+	}
+	return policy
+}
+
+// Check implements AddressPolicy, rejecting the transaction if any rule active
+// at number blocks it.
+func (p *addressPolicy) Check(number *big.Int, from common.Address, to *common.Address) error {
+	for _, rule := range p.rules {
+		if rule.Active(number) && rule.Blocks(from, to) {
+			return ErrBlockedAddress
+		}
+	}
+	return nil
+}
+
+// Rules implements AddressPolicy, returning the rules active at number.
+func (p *addressPolicy) Rules(number *big.Int) []*params.AddressRule {
+	rules := make([]*params.AddressRule, 0, len(p.rules))
+	for _, rule := range p.rules {
+		if rule.Active(number) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// LoadAddressRules reads a JSON list of address rules from the given file.
+func LoadAddressRules(file string) ([]*params.AddressRule, error) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*params.AddressRule
+	if err := json.Unmarshal(blob, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// checkTransactionPolicy verifies that a transaction included at the given block
+// number is permitted by the address policy.
+func checkTransactionPolicy(policy AddressPolicy, signer types.Signer, number *big.Int, tx *types.Transaction) error {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return ErrInvalidSender
+	}
+	return policy.Check(number, from, tx.To())
+}
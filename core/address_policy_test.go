@@ -0,0 +1,139 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/event"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// Tests that address rules reject the right transactions in the right blocks.
+func TestAddressPolicyCheck(t *testing.T) {
+	var (
+		blocked = common.Address{0x01}
+		other   = common.Address{0x02}
+		exempt  = common.Address{0x03}
+	)
+	policy := NewAddressPolicy([]*params.AddressRule{
+		{Address: blocked, Direction: params.AddressRuleFrom, Block: big.NewInt(10), Until: big.NewInt(20), Exempt: []common.Address{exempt}},
+	}, []*params.AddressRule{
+		{Address: other, Direction: params.AddressRuleTo},
+	})
+	tests := []struct {
+		number int64
+		from   common.Address
+		to     *common.Address
+		err    error
+	}{
+		{9, blocked, &exempt, nil},                          // Rule not yet active
+		{10, blocked, &common.Address{}, ErrBlockedAddress}, // Rule active, sending blocked
+		{10, blocked, nil, ErrBlockedAddress},               // Contract creations are blocked too
+		{10, blocked, &exempt, nil},                         // Exempt counterparty
+		{10, exempt, &blocked, nil},                         // Receiving still allowed
+		{20, blocked, &common.Address{}, nil},               // Rule expired
+		{0, exempt, &other, ErrBlockedAddress},              // Receiving blocked since genesis
+		{0, other, &exempt, nil},                            // Sending still allowed
+	}
+	for i, tt := range tests {
+		if err := policy.Check(big.NewInt(tt.number), tt.from, tt.to); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if rules := policy.Rules(big.NewInt(15)); len(rules) != 2 {
+		t.Errorf("active rule count mismatch: have %d, want %d", len(rules), 2)
+	}
+	if rules := policy.Rules(big.NewInt(25)); len(rules) != 1 {
+		t.Errorf("expired rule count mismatch: have %d, want %d", len(rules), 1)
+	}
+}
+
+// Tests that the transaction pool rejects transactions touching restricted accounts.
+func TestTransactionPoolAddressRules(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(yocdb.NewMemDatabase()))
+	statedb.AddBalance(from, big.NewInt(1000000))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := *params.TestChainConfig
+	config.AddressRules = []*params.AddressRule{{Address: common.Address{}, Direction: params.AddressRuleTo}}
+
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != ErrBlockedAddress {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrBlockedAddress)
+	}
+	if pending, queued := pool.Stats(); pending+queued != 0 {
+		t.Fatalf("blocked transaction accepted: pending %d, queued %d", pending, queued)
+	}
+}
+
+// Tests that the transaction pool also rejects transactions touching accounts
+// restricted by the local rules of the node only.
+func TestTransactionPoolLocalAddressRules(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(yocdb.NewMemDatabase()))
+	statedb.AddBalance(from, big.NewInt(1000000))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.AddressRules = []*params.AddressRule{{Address: from, Direction: params.AddressRuleFrom}}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != ErrBlockedAddress {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrBlockedAddress)
+	}
+	if rules := pool.AddressPolicy().Rules(big.NewInt(1)); len(rules) != 1 {
+		t.Fatalf("active rule count mismatch: have %d, want %d", len(rules), 1)
+	}
+}
+
+// Tests that blocks containing transactions touching restricted accounts are
+// rejected by the block validator.
+func TestBlockValidatorAddressRules(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		from    = crypto.PubkeyToAddress(key.PublicKey)
+		blocked = common.Address{0xff}
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{from: {Balance: big.NewInt(1000000000)}}}
+		db      = yocdb.NewMemDatabase()
+		genesis = gspec.MustCommit(db)
+		signer  = types.HomesteadSigner{}
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, yochash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(from), blocked, big.NewInt(1), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	config := *params.TestChainConfig
+	config.AddressRules = []*params.AddressRule{{Address: blocked, Block: big.NewInt(3)}}
+
+	db = yocdb.NewMemDatabase()
+	(&Genesis{Config: &config, Alloc: gspec.Alloc}).MustCommit(db)
+
+	chain, _ := NewBlockChain(db, nil, &config, yochash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:2]); err != nil {
+		t.Fatalf("failed to import blocks before the rule: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks[2:]); err != ErrBlockedAddress {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrBlockedAddress)
+	}
+}
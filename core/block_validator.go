@@ -9,9 +9,14 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/consensus"
 	"github.com/Yocoin15/Yocoin_Sources/core/state"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/metrics"
 	"github.com/Yocoin15/Yocoin_Sources/params"
 )
 
+// blockedBlockCounter counts the blocks rejected for containing transactions
+// restricted by the consensus address rules.
+var blockedBlockCounter = metrics.NewRegisteredCounter("chain/blocked", nil)
+
 // BlockValidator is responsible for validating block headers, uncles and
 // processed state.
 //
@@ -20,6 +25,7 @@ type BlockValidator struct {
 	config *params.ChainConfig // Chain configuration options
 	bc     *BlockChain         // Canonical block chain
 	engine consensus.Engine    // Consensus engine used for validating
+	policy AddressPolicy       // Consensus address rules transactions must obey
 }
 
 // NewBlockValidator returns a new block validator which is safe for re-use
@@ -28,6 +34,7 @@ func NewBlockValidator(config *params.ChainConfig, blockchain *BlockChain, engin
 		config: config,
 		engine: engine,
 		bc:     blockchain,
+		policy: NewAddressPolicy(config.AddressRules),
 	}
 	return validator
 }
@@ -57,6 +64,16 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if hash := types.DeriveSha(block.Transactions()); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
+	// Ensure no transaction touches an account restricted at this height
+	if len(v.config.AddressRules) > 0 {
+		signer := types.MakeSigner(v.config, header.Number)
+		for _, tx := range block.Transactions() {
+			if err := checkTransactionPolicy(v.policy, signer, header.Number, tx); err != nil {
+				blockedBlockCounter.Inc(1)
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -10,6 +10,7 @@ import (
 	"io"
 	"math/big"
 	mrand "math/rand"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,8 +18,10 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/common/mclock"
 	"github.com/Yocoin15/Yocoin_Sources/consensus"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/misc"
 	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
 	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/state/snapshot"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/core/vm"
 	"github.com/Yocoin15/Yocoin_Sources/crypto"
@@ -37,6 +40,8 @@ var (
 	blockInsertTimer = metrics.NewRegisteredTimer("chain/inserts", nil)
 
 	ErrNoGenesis = errors.New("Genesis not found in chain")
+
+	errInsertionInterrupted = errors.New("insertion is interrupted")
 )
 
 const (
@@ -57,6 +62,7 @@ type CacheConfig struct {
 	Disabled      bool          // Whether to disable trie write caching (archive node)
 	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
 	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+	SnapshotLimit int           // Memory allowance (MB) to use for caching snapshot entries in memory (0 = snapshot disabled)
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -99,6 +105,7 @@ type BlockChain struct {
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
+	snaps        *snapshot.Tree // Snapshot tree for fast trie leaf access
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	blockCache   *lru.Cache     // Cache for the most recent entire blocks
@@ -164,6 +171,28 @@ func NewBlockChain(db yocdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	// The head block may have been rolled back below the ancient chain (e.g. a
+	// crash during fast sync before the head marker was updated), truncate the
+	// dangling ancient data in that case
+	if frozen, err := bc.db.Ancients(); err == nil && frozen > 0 {
+		var (
+			needRewind bool
+			low        uint64
+		)
+		if fullBlock := bc.CurrentBlock(); fullBlock.Hash() != bc.genesisBlock.Hash() && fullBlock.NumberU64() < frozen-1 {
+			needRewind, low = true, fullBlock.NumberU64()
+		}
+		if fastBlock := bc.CurrentFastBlock(); fastBlock.NumberU64() < frozen-1 {
+			if !needRewind || fastBlock.NumberU64() < low {
+				low = fastBlock.NumberU64()
+			}
+			needRewind = true
+		}
+		if needRewind {
+			log.Error("Truncating ancient chain", "from", bc.CurrentHeader().Number.Uint64(), "to", low)
+			bc.SetHead(low)
+		}
+	}
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -177,6 +206,10 @@ func NewBlockChain(db yocdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			}
 		}
 	}
+	// Load any existing snapshot, regenerating it if loading failed
+	if bc.cacheConfig.SnapshotLimit > 0 {
+		bc.snaps = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, bc.CurrentBlock().Root(), true)
+	}
 	// Take ownership of this particular state
 	go bc.update()
 	return bc, nil
@@ -262,6 +295,13 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.hc.CurrentHeader()
 
+	// Drop any frozen block data above the new head from the ancient store
+	if frozen, err := bc.db.Ancients(); err == nil && frozen > head+1 {
+		if err := bc.db.TruncateAncients(head + 1); err != nil {
+			log.Crit("Failed to truncate ancient data", "number", head, "err", err)
+		}
+	}
+
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
@@ -370,7 +410,7 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache)
+	return state.NewWithSnapshot(root, bc.stateCache, bc.snaps)
 }
 
 // Reset purges the entire blockchain, restoring it to its genesis state.
@@ -595,6 +635,36 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	return rawdb.ReadReceipts(bc.db, hash, *number)
 }
 
+// GetBalanceRewrites retrieves the audit records of the balance rewrites applied
+// outside of transaction execution to the canonical blocks in the inclusive
+// range [from, to]. Only blocks executed locally carry such records, and only
+// the blocks of the configured irregular state changes are looked up.
+func (bc *BlockChain) GetBalanceRewrites(from, to uint64) []*types.BalanceRewrite {
+	var numbers []uint64
+	for _, change := range bc.chainConfig.IrregularStateChanges {
+		if change.Block == nil || !change.Block.IsUint64() {
+			continue
+		}
+		if number := change.Block.Uint64(); number >= from && number <= to {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	var rewrites []*types.BalanceRewrite
+	for i, number := range numbers {
+		if i > 0 && numbers[i-1] == number {
+			continue
+		}
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		if hash == (common.Hash{}) {
+			break
+		}
+		rewrites = append(rewrites, rawdb.ReadBalanceRewrites(bc.db, hash, number)...)
+	}
+	return rewrites
+}
+
 // GetBlocksFromHash returns the block corresponding to hash and up to n-1 ancestors.
 // [deprecated by eth/62]
 func (bc *BlockChain) GetBlocksFromHash(hash common.Hash, n int) (blocks []*types.Block) {
@@ -644,6 +714,14 @@ func (bc *BlockChain) Stop() {
 
 	bc.wg.Wait()
 
+	// Ensure that the entirety of the state snapshot is journalled to disk.
+	var snapBase common.Hash
+	if bc.snaps != nil {
+		var err error
+		if snapBase, err = bc.snaps.Journal(bc.CurrentBlock().Root()); err != nil {
+			log.Error("Failed to journal state snapshot", "err", err)
+		}
+	}
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -662,6 +740,13 @@ func (bc *BlockChain) Stop() {
 				}
 			}
 		}
+		// The snapshot generation resumes from the base of the journal on restart
+		if snapBase != (common.Hash{}) {
+			log.Info("Writing snapshot state to disk", "root", snapBase)
+			if err := triedb.Commit(snapBase, true); err != nil {
+				log.Error("Failed to commit recent state trie", "err", err)
+			}
+		}
 		for !bc.triegc.Empty() {
 			triedb.Dereference(bc.triegc.PopItem().(common.Hash))
 		}
@@ -763,8 +848,9 @@ func SetReceiptsData(config *params.ChainConfig, block *types.Block, receipts ty
 }
 
 // InsertReceiptChain attempts to complete an already existing header chain with
-// transaction and receipt data.
-func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain []types.Receipts) (int, error) {
+// transaction and receipt data. Blocks at or below the ancient limit are written
+// straight into the ancient store, if the chain database has one.
+func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain []types.Receipts, ancientLimit uint64) (int, error) {
 	bc.wg.Add(1)
 	defer bc.wg.Done()
 
@@ -782,68 +868,151 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		stats = struct{ processed, ignored int32 }{}
 		start = time.Now()
 		bytes = 0
-		batch = bc.db.NewBatch()
 	)
-	for i, block := range blockChain {
-		receipts := receiptChain[i]
-		// Short circuit insertion if shutting down or processing failed
-		if atomic.LoadInt32(&bc.procInterrupt) == 1 {
-			return 0, nil
+	// updateHead updates the head fast sync block if the inserted blocks are better
+	updateHead := func(head *types.Block) {
+		bc.mu.Lock()
+		defer bc.mu.Unlock()
+
+		if td := bc.GetTd(head.Hash(), head.NumberU64()); td != nil { // Rewind may have occurred, skip in that case
+			currentFastBlock := bc.CurrentFastBlock()
+			if bc.GetTd(currentFastBlock.Hash(), currentFastBlock.NumberU64()).Cmp(td) < 0 {
+				rawdb.WriteHeadFastBlockHash(bc.db, head.Hash())
+				bc.currentFastBlock.Store(head)
+			}
 		}
-		// Short circuit if the owner header is unknown
-		if !bc.HasHeader(block.Hash(), block.NumberU64()) {
-			return i, fmt.Errorf("containing header #%d [%x…] unknown", block.Number(), block.Hash().Bytes()[:4])
+	}
+	// writeAncient writes blockchain and corresponding receipt chain into the
+	// ancient store, deleting any copies left in the key-value store.
+	writeAncient := func(blockChain types.Blocks, receiptChain []types.Receipts) (int, error) {
+		frozen, err := bc.db.Ancients()
+		if err != nil {
+			return 0, err
 		}
-		// Skip if the entire data is already known
-		if bc.HasBlock(block.Hash(), block.NumberU64()) {
-			stats.ignored++
-			continue
+		// Ensure genesis is in ancients, it's never inserted through here
+		if first := blockChain[0]; first.NumberU64() == 1 && frozen == 0 {
+			bytes += rawdb.WriteAncientBlock(bc.db, bc.genesisBlock, nil, bc.genesisBlock.Difficulty())
+			frozen = 1
 		}
-		// Compute all the non-consensus fields of the receipts
-		if err := SetReceiptsData(bc.chainConfig, block, receipts); err != nil {
-			return i, fmt.Errorf("failed to set receipts data: %v", err)
+		batch := bc.db.NewBatch()
+		for i, block := range blockChain {
+			// Short circuit insertion if shutting down or processing failed
+			if atomic.LoadInt32(&bc.procInterrupt) == 1 {
+				return 0, errInsertionInterrupted
+			}
+			// Short circuit if the owner header is unknown
+			if !bc.HasHeader(block.Hash(), block.NumberU64()) {
+				return i, fmt.Errorf("containing header #%d [%x…] unknown", block.Number(), block.Hash().Bytes()[:4])
+			}
+			// Skip if the block was already frozen
+			if block.NumberU64() < frozen {
+				stats.ignored++
+				continue
+			}
+			// Compute all the non-consensus fields of the receipts
+			if err := SetReceiptsData(bc.chainConfig, block, receiptChain[i]); err != nil {
+				return i, fmt.Errorf("failed to set receipts data: %v", err)
+			}
+			td := bc.GetTd(block.Hash(), block.NumberU64())
+			if td == nil {
+				return i, consensus.ErrUnknownAncestor
+			}
+			bytes += rawdb.WriteAncientBlock(bc.db, block, receiptChain[i], td)
+			rawdb.WriteTxLookupEntries(batch, block)
+
+			stats.processed++
 		}
-		// Write all the data out into the database
-		rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
-		rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
-		rawdb.WriteTxLookupEntries(batch, block)
+		// Flush all the ancient data to disk before deleting anything
+		if err := bc.db.Sync(); err != nil {
+			return 0, err
+		}
+		updateHead(blockChain[len(blockChain)-1])
 
-		stats.processed++
+		// Delete the frozen block data from the key-value store
+		for _, block := range blockChain {
+			rawdb.DeleteBlockWithoutNumber(batch, block.Hash(), block.NumberU64())
+			rawdb.DeleteCanonicalHash(batch, block.NumberU64())
+		}
+		if err := batch.Write(); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	// writeLive writes blockchain and corresponding receipt chain into the
+	// key-value store.
+	writeLive := func(blockChain types.Blocks, receiptChain []types.Receipts) (int, error) {
+		batch := bc.db.NewBatch()
+		for i, block := range blockChain {
+			// Short circuit insertion if shutting down or processing failed
+			if atomic.LoadInt32(&bc.procInterrupt) == 1 {
+				return 0, errInsertionInterrupted
+			}
+			// Short circuit if the owner header is unknown
+			if !bc.HasHeader(block.Hash(), block.NumberU64()) {
+				return i, fmt.Errorf("containing header #%d [%x…] unknown", block.Number(), block.Hash().Bytes()[:4])
+			}
+			// Skip if the entire data is already known
+			if bc.HasBlock(block.Hash(), block.NumberU64()) {
+				stats.ignored++
+				continue
+			}
+			// Compute all the non-consensus fields of the receipts
+			if err := SetReceiptsData(bc.chainConfig, block, receiptChain[i]); err != nil {
+				return i, fmt.Errorf("failed to set receipts data: %v", err)
+			}
+			// Write all the data out into the database
+			rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
+			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receiptChain[i])
+			rawdb.WriteTxLookupEntries(batch, block)
+
+			stats.processed++
 
-		if batch.ValueSize() >= yocdb.IdealBatchSize {
+			if batch.ValueSize() >= yocdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return 0, err
+				}
+				bytes += batch.ValueSize()
+				batch.Reset()
+			}
+		}
+		if batch.ValueSize() > 0 {
+			bytes += batch.ValueSize()
 			if err := batch.Write(); err != nil {
 				return 0, err
 			}
-			bytes += batch.ValueSize()
-			batch.Reset()
 		}
+		updateHead(blockChain[len(blockChain)-1])
+		return 0, nil
 	}
-	if batch.ValueSize() > 0 {
-		bytes += batch.ValueSize()
-		if err := batch.Write(); err != nil {
-			return 0, err
+	// Split the chain into the ancient and live segments and write them out
+	index := 0
+	for ; index < len(blockChain) && blockChain[index].NumberU64() <= ancientLimit; index++ {
+	}
+	if index > 0 {
+		if n, err := writeAncient(blockChain[:index], receiptChain[:index]); err != nil {
+			if err == errInsertionInterrupted {
+				return 0, nil
+			}
+			return n, err
 		}
 	}
-
-	// Update the head fast sync block if better
-	bc.mu.Lock()
-	head := blockChain[len(blockChain)-1]
-	if td := bc.GetTd(head.Hash(), head.NumberU64()); td != nil { // Rewind may have occurred, skip in that case
-		currentFastBlock := bc.CurrentFastBlock()
-		if bc.GetTd(currentFastBlock.Hash(), currentFastBlock.NumberU64()).Cmp(td) < 0 {
-			rawdb.WriteHeadFastBlockHash(bc.db, head.Hash())
-			bc.currentFastBlock.Store(head)
+	if index < len(blockChain) {
+		if n, err := writeLive(blockChain[index:], receiptChain[index:]); err != nil {
+			if err == errInsertionInterrupted {
+				return 0, nil
+			}
+			return index + n, err
 		}
 	}
-	bc.mu.Unlock()
-
-	log.Info("Imported new block receipts",
-		"count", stats.processed,
-		"elapsed", common.PrettyDuration(time.Since(start)),
-		"number", head.Number(),
-		"hash", head.Hash(),
-		"size", common.StorageSize(bytes),
-		"ignored", stats.ignored)
+	head := blockChain[len(blockChain)-1]
+	context := []interface{}{
+		"count", stats.processed, "elapsed", common.PrettyDuration(time.Since(start)),
+		"number", head.Number(), "hash", head.Hash(), "size", common.StorageSize(bytes),
+	}
+	if stats.ignored > 0 {
+		context = append(context, []interface{}{"ignored", stats.ignored}...)
+	}
+	log.Info("Imported new block receipts", context...)
 	return 0, nil
 }
 
@@ -874,6 +1043,8 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	if ptd == nil {
 		return NonStatTy, consensus.ErrUnknownAncestor
 	}
+	rewrites := bc.balanceRewrites(block)
+
 	// Make sure no inconsistent state is leaked during insertion
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
@@ -943,6 +1114,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 		}
 	}
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	if len(rewrites) > 0 {
+		rawdb.WriteBalanceRewrites(batch, block.Hash(), block.NumberU64(), rewrites)
+	}
 
 	// If the total difficulty is higher than our known, add it to the canonical chain
 	// Second clause in the if statement reduces the vulnerability to selfish mining.
@@ -975,11 +1149,54 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	// Set new head.
 	if status == CanonStatTy {
 		bc.insert(block)
+		bc.updateSnapshot(block.Root())
 	}
 	bc.futureBlocks.Remove(block.Hash())
 	return status, nil
 }
 
+// updateSnapshot moves the head of the snapshot tree to the given canonical state,
+// flattening the layers beyond the in-memory trie horizon. If the state couldn't
+// be tracked by the tree, as its parent wasn't, the snapshot is regenerated.
+func (bc *BlockChain) updateSnapshot(root common.Hash) {
+	if bc.snaps == nil {
+		return
+	}
+	if bc.snaps.Snapshot(root) == nil {
+		bc.snaps.Rebuild(root)
+		return
+	}
+	if err := bc.snaps.Cap(root, triesInMemory); err != nil {
+		log.Warn("Failed to cap snapshot tree", "root", root, "layers", triesInMemory, "err", err)
+	}
+}
+
+// balanceRewrites replays the irregular state changes scheduled for the block on
+// top of its parent state to produce the audit records of the balances they
+// rewrote. The state database passed to WriteBlockWithState cannot be used as it
+// already contains the effects of the block's transactions.
+func (bc *BlockChain) balanceRewrites(block *types.Block) []*types.BalanceRewrite {
+	if len(bc.chainConfig.IrregularStateChangesAt(block.Number())) == 0 {
+		return nil
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil
+	}
+	statedb, err := state.New(parent.Root, bc.stateCache)
+	if err != nil {
+		log.Error("Failed to audit irregular state changes", "number", block.Number(), "hash", block.Hash(), "err", err)
+		return nil
+	}
+	rewrites := misc.ApplyIrregularStateChanges(bc.chainConfig, statedb, block.Number())
+	for _, rewrite := range rewrites {
+		rewrite.BlockHash = block.Hash()
+		log.Info("Applied irregular balance rewrite", "number", rewrite.BlockNumber, "hash", rewrite.BlockHash, "address", rewrite.Address,
+			"reason", rewrite.Reason, "old", rewrite.OldBalance, "new", rewrite.NewBalance)
+	}
+	return rewrites
+}
+
 // InsertChain attempts to insert the given batch of blocks in to the canonical
 // chain or, otherwise, create a fork. If an error is returned it will return
 // the index number of the failing block as well an error describing what went
@@ -1130,7 +1347,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		} else {
 			parent = chain[i-1]
 		}
-		state, err := state.New(parent.Root(), bc.stateCache)
+		state, err := state.NewWithSnapshot(parent.Root(), bc.stateCache, bc.snaps)
 		if err != nil {
 			return i, events, coalescedLogs, err
 		}
@@ -5,8 +5,10 @@ package core
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"math/rand"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -641,7 +643,7 @@ func TestFastVsFullChains(t *testing.T) {
 	if n, err := fast.InsertHeaderChain(headers, 1); err != nil {
 		t.Fatalf("failed to insert header %d: %v", n, err)
 	}
-	if n, err := fast.InsertReceiptChain(blocks, receipts); err != nil {
+	if n, err := fast.InsertReceiptChain(blocks, receipts, 0); err != nil {
 		t.Fatalf("failed to insert receipt %d: %v", n, err)
 	}
 	// Iterate over all chain data components, and cross reference
@@ -673,6 +675,83 @@ func TestFastVsFullChains(t *testing.T) {
 	}
 }
 
+// Tests that fast importing a chain with an ancient limit moves the old blocks
+// and receipts straight into the freezer, while keeping them fully retrievable.
+func TestFastChainWithAncients(t *testing.T) {
+	// Configure and generate a sample block chain
+	var (
+		gendb   = yocdb.NewMemDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(1000000000)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{address: {Balance: funds}}}
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	blocks, receipts := GenerateChain(gspec.Config, genesis, yochash.NewFaker(), gendb, 128, func(i int, block *BlockGen) {
+		block.SetCoinbase(common.Address{0x00})
+
+		if i%3 == 2 {
+			tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x00}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+			if err != nil {
+				panic(err)
+			}
+			block.AddTx(tx)
+		}
+	})
+	// Fast import the chain into a freezer backed database
+	frdir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temp freezer dir: %v", err)
+	}
+	defer os.RemoveAll(frdir)
+
+	fastDb, err := rawdb.NewDatabaseWithFreezer(yocdb.NewMemDatabase(), frdir, "")
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend: %v", err)
+	}
+	gspec.MustCommit(fastDb)
+	fast, _ := NewBlockChain(fastDb, nil, gspec.Config, yochash.NewFaker(), vm.Config{})
+	defer fast.Stop()
+
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if n, err := fast.InsertHeaderChain(headers, 1); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	ancientLimit := uint64(len(blocks) / 2)
+	if n, err := fast.InsertReceiptChain(blocks, receipts, ancientLimit); err != nil {
+		t.Fatalf("failed to insert receipt %d: %v", n, err)
+	}
+	if frozen, _ := fastDb.Ancients(); frozen != ancientLimit+1 {
+		t.Fatalf("frozen item count mismatch: have %d, want %d", frozen, ancientLimit+1)
+	}
+	if num := fast.CurrentFastBlock().NumberU64(); num != blocks[len(blocks)-1].NumberU64() {
+		t.Errorf("fast head mismatch: have #%d, want #%d", num, blocks[len(blocks)-1].NumberU64())
+	}
+	// Iterate over all chain data components, and cross reference
+	for i, block := range blocks {
+		num, hash := block.NumberU64(), block.Hash()
+
+		if fblock := fast.GetBlockByHash(hash); fblock == nil || fblock.Hash() != hash {
+			t.Errorf("block #%d [%x]: block mismatch: have %v, want %v", num, hash, fblock, block)
+		}
+		if fhash := rawdb.ReadCanonicalHash(fastDb, num); fhash != hash {
+			t.Errorf("block #%d: canonical hash mismatch: have %x, want %x", num, fhash, hash)
+		}
+		if freceipts := rawdb.ReadReceipts(fastDb, hash, num); types.DeriveSha(freceipts) != types.DeriveSha(receipts[i]) {
+			t.Errorf("block #%d [%x]: receipts mismatch: have %v, want %v", num, hash, freceipts, receipts[i])
+		}
+		if num <= ancientLimit {
+			if blob := rawdb.ReadBodyRLP(rawdb.KeyValueStore(fastDb), hash, num); len(blob) > 0 {
+				t.Errorf("block #%d [%x]: frozen body left in key-value store", num, hash)
+			}
+		}
+	}
+}
+
 // Tests that various import methods move the chain head pointers to the correct
 // positions.
 func TestLightVsFastVsFullChainHeads(t *testing.T) {
@@ -732,7 +811,7 @@ func TestLightVsFastVsFullChainHeads(t *testing.T) {
 	if n, err := fast.InsertHeaderChain(headers, 1); err != nil {
 		t.Fatalf("failed to insert header %d: %v", n, err)
 	}
-	if n, err := fast.InsertReceiptChain(blocks, receipts); err != nil {
+	if n, err := fast.InsertReceiptChain(blocks, receipts, 0); err != nil {
 		t.Fatalf("failed to insert receipt %d: %v", n, err)
 	}
 	assert(t, "fast", fast, height, height, 0)
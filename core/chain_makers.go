@@ -177,6 +177,7 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(b.header.Number) == 0 {
 			misc.ApplyDAOHardFork(statedb)
 		}
+		misc.ApplyIrregularStateChanges(config, statedb, b.header.Number)
 		// Execute any user modifications to the block and finalize it
 		if gen != nil {
 			gen(i, b)
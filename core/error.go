@@ -19,4 +19,8 @@ var (
 	// ErrNonceTooHigh is returned if the nonce of a transaction is higher than the
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
+
+	// ErrBlockedAddress is returned if the sender or the recipient of a transaction
+	// is restricted by the address policy at the height it would be included at.
+	ErrBlockedAddress = errors.New("address blocked by policy")
 )
@@ -0,0 +1,170 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package forkid
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+)
+
+// TestCreation tests that different genesis and fork rule combinations result in
+// the correct fork ID.
+func TestCreation(t *testing.T) {
+	type testcase struct {
+		head uint64
+		want ID
+	}
+	tests := []struct {
+		config  *params.ChainConfig
+		genesis common.Hash
+		cases   []testcase
+	}{
+		// Mainnet test cases
+		{
+			params.MainnetChainConfig,
+			params.MainnetGenesisHash,
+			[]testcase{
+				{0, ID{Hash: checksumToBytes(0x3ab6db94), Next: 3001492}},       // Unsynced
+				{3001491, ID{Hash: checksumToBytes(0x3ab6db94), Next: 3001492}}, // Last block before the November 2019 credit freezes
+				{3001492, ID{Hash: checksumToBytes(0xe096783e), Next: 3500001}}, // First November 2019 block
+				{3500000, ID{Hash: checksumToBytes(0xe096783e), Next: 3500001}}, // Last block with the affected account frozen
+				{3500001, ID{Hash: checksumToBytes(0x16183a1e), Next: 0}},       // First block with the affected account credited again
+				{5000000, ID{Hash: checksumToBytes(0x16183a1e), Next: 0}},       // Future block
+			},
+		},
+		// Testnet test cases
+		{
+			params.TestnetChainConfig,
+			params.TestnetGenesisHash,
+			[]testcase{
+				{0, ID{Hash: checksumToBytes(0x3ab6db94), Next: 10}},            // Unsynced, last Frontier, Homestead and Tangerine block
+				{9, ID{Hash: checksumToBytes(0x3ab6db94), Next: 10}},            // Last Tangerine block
+				{10, ID{Hash: checksumToBytes(0xa0fb8b8c), Next: 1700000}},      // First Spurious block
+				{1699999, ID{Hash: checksumToBytes(0xa0fb8b8c), Next: 1700000}}, // Last Spurious block
+				{1700000, ID{Hash: checksumToBytes(0x35485d12), Next: 0}},       // First Byzantium block
+				{5000000, ID{Hash: checksumToBytes(0x35485d12), Next: 0}},       // Future Byzantium block
+			},
+		},
+	}
+	for i, tt := range tests {
+		for j, ttt := range tt.cases {
+			if have := newID(tt.config, tt.genesis, ttt.head); have != ttt.want {
+				t.Errorf("test %d, case %d: fork ID mismatch: have %x, want %x", i, j, have, ttt.want)
+			}
+		}
+	}
+}
+
+// TestGatherForks tests that the config driven rule changes are counted as forks.
+func TestGatherForks(t *testing.T) {
+	config := &params.ChainConfig{
+		HomesteadBlock: big.NewInt(0),
+		EIP155Block:    big.NewInt(10),
+		IrregularStateChanges: []*params.IrregularStateChange{
+			{Block: big.NewInt(20)},
+		},
+		AddressRules: []*params.AddressRule{
+			{Block: big.NewInt(20), Until: big.NewInt(40)},
+			{Until: big.NewInt(30)},
+		},
+		CreditFreezes: []*params.CreditFreeze{
+			{Block: big.NewInt(40), Until: big.NewInt(45)},
+		},
+		Yochash: &params.YochashConfig{
+			RewardSchedule: []*params.RewardPeriod{
+				{Block: big.NewInt(0), Reward: big.NewInt(1)},
+				{Block: big.NewInt(50), Reward: big.NewInt(2)},
+			},
+		},
+	}
+	have := gatherForks(config)
+	want := []uint64{10, 20, 30, 40, 45, 50}
+	if len(have) != len(want) {
+		t.Fatalf("fork count mismatch: have %v, want %v", have, want)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Fatalf("fork list mismatch: have %v, want %v", have, want)
+		}
+	}
+}
+
+// TestValidation tests that a local peer correctly validates and accepts a remote
+// fork ID.
+func TestValidation(t *testing.T) {
+	tests := []struct {
+		head uint64
+		id   ID
+		err  error
+	}{
+		// Local is on the Spurious fork, remote announces the same. No future fork is known.
+		{10, ID{Hash: checksumToBytes(0xa0fb8b8c), Next: 0}, nil},
+
+		// Local is on the Spurious fork, remote announces the same. Remote also announces
+		// the next fork at block 1700000, which is known locally.
+		{10, ID{Hash: checksumToBytes(0xa0fb8b8c), Next: 1700000}, nil},
+
+		// Local is on the Spurious fork, remote announces the same. Remote also announces
+		// an unknown next fork, but it's not yet passed locally.
+		{10, ID{Hash: checksumToBytes(0xa0fb8b8c), Next: math.MaxUint64}, nil},
+
+		// Local is on the Spurious fork, remote announces the same. Remote also announces
+		// a next fork at block 100, which was already passed locally.
+		{1000, ID{Hash: checksumToBytes(0xa0fb8b8c), Next: 100}, ErrLocalIncompatibleOrStale},
+
+		// Local is on Byzantium, remote announces Spurious with the upcoming Byzantium
+		// fork. Remote is simply out of sync, accept.
+		{1700000, ID{Hash: checksumToBytes(0xa0fb8b8c), Next: 1700000}, nil},
+
+		// Local is on Byzantium, remote announces Tangerine with the upcoming Spurious
+		// fork. Remote is definitely out of sync, but it may catch up, accept.
+		{1700000, ID{Hash: checksumToBytes(0x3ab6db94), Next: 10}, nil},
+
+		// Local is on Spurious, remote announces Byzantium. Local is out of sync, accept.
+		{10, ID{Hash: checksumToBytes(0x35485d12), Next: 0}, nil},
+
+		// Local is on Byzantium, remote announces Spurious without the Byzantium fork.
+		// Remote is stale and will sync onto the wrong chain, reject.
+		{1700000, ID{Hash: checksumToBytes(0xa0fb8b8c), Next: 0}, ErrRemoteStale},
+
+		// Local is on Byzantium, remote announces a completely unknown checksum.
+		// Remote is on a different chain or runs different rules, reject.
+		{1700000, ID{Hash: checksumToBytes(0xafec6b27), Next: 0}, ErrLocalIncompatibleOrStale},
+	}
+	for i, tt := range tests {
+		filter := newFilter(params.TestnetChainConfig, params.TestnetGenesisHash, func() uint64 { return tt.head })
+		if err := filter(tt.id); err != tt.err {
+			t.Errorf("test %d: validation error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that IDs are properly RLP encoded (specifically important because we
+// use uint32 to store the hash, but we need to encode it as [4]byte).
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		id   ID
+		want []byte
+	}{
+		{ID{Hash: checksumToBytes(0), Next: 0}, common.Hex2Bytes("c6840000000080")},
+		{ID{Hash: checksumToBytes(0xdeadbeef), Next: 0xBADDCAFE}, common.Hex2Bytes("ca84deadbeef84baddcafe")},
+		{ID{Hash: checksumToBytes(math.MaxUint32), Next: math.MaxUint64}, common.Hex2Bytes("ce84ffffffff88ffffffffffffffff")},
+	}
+	for i, tt := range tests {
+		have, err := rlp.EncodeToBytes(tt.id)
+		if err != nil {
+			t.Errorf("test %d: failed to encode forkid: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(have, tt.want) {
+			t.Errorf("test %d: RLP mismatch: have %x, want %x", i, have, tt.want)
+		}
+	}
+}
@@ -125,6 +125,18 @@ func (e *GenesisMismatchError) Error() string {
 	return fmt.Sprintf("database already contains an incompatible genesis block (have %x, new %x)", e.Stored[:8], e.New[:8])
 }
 
+// IrregularStateChangeError is raised when the local chain has already been
+// processed past a block whose irregular state changes differ between the stored
+// and the new chain configuration. Unlike other configuration mismatches this is
+// not fixed by rewinding, the node refuses to start until the operator resolves it.
+type IrregularStateChangeError struct {
+	Block *big.Int
+}
+
+func (e *IrregularStateChangeError) Error() string {
+	return fmt.Sprintf("database was processed with different irregular state changes at block %v", e.Block)
+}
+
 // SetupGenesisBlock writes or updates the genesis block in db.
 // The block that will be used is:
 //
@@ -136,12 +148,20 @@ func (e *GenesisMismatchError) Error() string {
 // The stored chain configuration will be updated if it is compatible (i.e. does not
 // specify a fork block below the local head block). In case of a conflict, the
 // error is a *params.ConfigCompatError and the new, unwritten config is returned.
+// Conflicting irregular state changes below the head yield an *IrregularStateChangeError,
+// unless the stored config has none, in which case a *params.ConfigCompatError is
+// returned to rewind the chain and process it with the new changes.
 //
 // The returned chain configuration is never nil.
 func SetupGenesisBlock(db yocdb.Database, genesis *Genesis) (*params.ChainConfig, common.Hash, error) {
 	if genesis != nil && genesis.Config == nil {
 		return params.AllYochashProtocolChanges, common.Hash{}, errGenesisNoConfig
 	}
+	if genesis != nil {
+		if err := genesis.Config.CheckRewardSchedule(); err != nil {
+			return genesis.Config, common.Hash{}, fmt.Errorf("invalid reward schedule: %v", err)
+		}
+	}
 
 	// Just commit the new block if there is no stored genesis block.
 	stored := rawdb.ReadCanonicalHash(db, 0)
@@ -190,6 +210,13 @@ func SetupGenesisBlock(db yocdb.Database, genesis *Genesis) (*params.ChainConfig
 	if height == nil {
 		return newcfg, stored, fmt.Errorf("missing block number for head header hash")
 	}
+	// Configs without irregular state changes, e.g. ones stored before they were
+	// configurable, are checked for compatibility like forks instead.
+	if storedcfg.IrregularStateChanges != nil {
+		if block := storedcfg.CheckIrregularStateChanges(newcfg, *height); block != nil && *height != 0 {
+			return newcfg, stored, &IrregularStateChangeError{block}
+		}
+	}
 	compatErr := storedcfg.CheckCompatible(newcfg, *height)
 	if compatErr != nil && *height != 0 && compatErr.RewindTo != 0 {
 		return newcfg, stored, compatErr
@@ -219,7 +246,7 @@ func (g *Genesis) ToBlock(db yocdb.Database) *types.Block {
 	}
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
 	for addr, account := range g.Alloc {
-		statedb.AddBalance(addr, account.Balance, big.NewInt(0))
+		statedb.AddBalance(addr, account.Balance)
 		statedb.SetCode(addr, account.Code)
 		statedb.SetNonce(addr, account.Nonce)
 		for key, value := range account.Storage {
@@ -0,0 +1,211 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// Tests that irregular state changes are applied exactly once, at the beginning
+// of their scheduled block, both when generating and when importing a chain.
+func TestIrregularStateChange(t *testing.T) {
+	var (
+		drained  = common.Address{0x01}
+		restored = common.Address{0x02}
+		frozen   = common.Address{0x03}
+	)
+	config := *params.TestChainConfig
+	config.IrregularStateChanges = []*params.IrregularStateChange{{
+		Block: big.NewInt(2),
+		Moves: []params.IrregularBalance{
+			{From: drained, To: restored, Amount: big.NewInt(300)},
+		},
+		Freezes: []common.Address{drained, frozen},
+	}}
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			drained: {Balance: big.NewInt(1000)},
+			frozen:  {Balance: big.NewInt(500)},
+		},
+	}
+	db := yocdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(&config, genesis, yochash.NewFaker(), db, 4, func(i int, gen *BlockGen) {})
+
+	// Import the chain into a fresh node and check the resulting balances
+	db = yocdb.NewMemDatabase()
+	gspec.MustCommit(db)
+
+	chain, _ := NewBlockChain(db, nil, &config, yochash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to import pre-change block: %v", err)
+	}
+	statedb, _ := chain.State()
+	if balance := statedb.GetBalance(drained); balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("pre-change drained balance mismatch: have %v, want %v", balance, 1000)
+	}
+	if _, err := chain.InsertChain(blocks[1:]); err != nil {
+		t.Fatalf("failed to import post-change blocks: %v", err)
+	}
+	statedb, _ = chain.State()
+	for addr, want := range map[common.Address]int64{drained: 0, restored: 300, frozen: 0} {
+		if balance := statedb.GetBalance(addr); balance.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("post-change balance mismatch for %x: have %v, want %v", addr, balance, want)
+		}
+	}
+	// Check that an audit record was persisted for every rewritten balance
+	want := []struct {
+		addr     common.Address
+		reason   string
+		old, new int64
+	}{
+		{drained, types.RewriteMoveOut, 1000, 700},
+		{restored, types.RewriteMoveIn, 0, 300},
+		{drained, types.RewriteFreeze, 700, 0},
+		{frozen, types.RewriteFreeze, 500, 0},
+	}
+	rewrites := chain.GetBalanceRewrites(0, 4)
+	if len(rewrites) != len(want) {
+		t.Fatalf("rewrite count mismatch: have %d, want %d", len(rewrites), len(want))
+	}
+	for i, rewrite := range rewrites {
+		if rewrite.BlockNumber != 2 || rewrite.BlockHash != blocks[1].Hash() {
+			t.Errorf("rewrite %d: block mismatch: have %d/%x, want %d/%x", i, rewrite.BlockNumber, rewrite.BlockHash, 2, blocks[1].Hash())
+		}
+		if rewrite.Address != want[i].addr || rewrite.Reason != want[i].reason {
+			t.Errorf("rewrite %d: account mismatch: have %x/%s, want %x/%s", i, rewrite.Address, rewrite.Reason, want[i].addr, want[i].reason)
+		}
+		if rewrite.OldBalance.Int64() != want[i].old || rewrite.NewBalance.Int64() != want[i].new {
+			t.Errorf("rewrite %d: balance mismatch: have %v->%v, want %v->%v", i, rewrite.OldBalance, rewrite.NewBalance, want[i].old, want[i].new)
+		}
+	}
+	if rewrites := chain.GetBalanceRewrites(3, 4); len(rewrites) != 0 {
+		t.Errorf("unexpected rewrites outside of the change block: %v", rewrites)
+	}
+}
+
+// Tests that credits to frozen accounts, be they transfers or block rewards, are
+// discarded within their freezes only.
+func TestCreditFreeze(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		bank   = crypto.PubkeyToAddress(key.PublicKey)
+		frozen = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		miner  = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	)
+	config := *params.TestChainConfig
+	config.CreditFreezes = []*params.CreditFreeze{
+		{Address: frozen, Block: big.NewInt(2), Until: big.NewInt(4)},
+		{Address: miner, Block: big.NewInt(2), Until: big.NewInt(3)},
+	}
+	gspec := &Genesis{
+		Config: &config,
+		Alloc:  GenesisAlloc{bank: {Balance: big.NewInt(params.YOC)}},
+	}
+	db := yocdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+	signer := types.NewEIP155Signer(config.ChainID)
+	blocks, _ := GenerateChain(&config, genesis, yochash.NewFaker(), db, 4, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(miner)
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(bank), frozen, big.NewInt(1000), params.TxGas, big.NewInt(1), nil), signer, key)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		gen.AddTx(tx)
+	})
+	// Import the chain into a fresh node block by block and check the balances
+	db = yocdb.NewMemDatabase()
+	gspec.MustCommit(db)
+
+	chain, _ := NewBlockChain(db, nil, &config, yochash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	reward := new(big.Int).Add(params.ByzantiumBlockReward, big.NewInt(int64(params.TxGas)))
+	for i, credits := range []struct{ frozen, miner int64 }{{1, 1}, {1, 1}, {1, 2}, {2, 3}} {
+		if _, err := chain.InsertChain(blocks[i : i+1]); err != nil {
+			t.Fatalf("block %d: failed to import: %v", i+1, err)
+		}
+		statedb, _ := chain.State()
+		if have, want := statedb.GetBalance(frozen), big.NewInt(1000*credits.frozen); have.Cmp(want) != 0 {
+			t.Errorf("block %d: frozen balance mismatch: have %v, want %v", i+1, have, want)
+		}
+		if have, want := statedb.GetBalance(miner), new(big.Int).Mul(reward, big.NewInt(credits.miner)); have.Cmp(want) != 0 {
+			t.Errorf("block %d: miner balance mismatch: have %v, want %v", i+1, have, want)
+		}
+	}
+}
+
+// Tests that a node refuses to start if the irregular state changes it was
+// configured with differ from the ones its local chain was processed with.
+func TestIrregularStateChangeMismatch(t *testing.T) {
+	config := *params.TestChainConfig
+	config.IrregularStateChanges = []*params.IrregularStateChange{{
+		Block:   big.NewInt(2),
+		Freezes: []common.Address{{0x01}},
+	}}
+	gspec := &Genesis{Config: &config}
+
+	db := yocdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(&config, genesis, yochash.NewFaker(), db, 4, func(i int, gen *BlockGen) {})
+
+	chain, _ := NewBlockChain(db, nil, &config, yochash.NewFaker(), vm.Config{})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	chain.Stop()
+
+	// Restarting with the same configuration must succeed
+	if _, _, err := SetupGenesisBlock(db, gspec); err != nil {
+		t.Fatalf("failed to set up genesis with matching config: %v", err)
+	}
+	// Restarting without the irregular change must be refused
+	altered := config
+	altered.IrregularStateChanges = nil
+
+	_, _, err := SetupGenesisBlock(db, &Genesis{Config: &altered})
+	if err, ok := err.(*IrregularStateChangeError); !ok || err.Block.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("error mismatch: have %v, want irregular state change at block 2", err)
+	}
+}
+
+// Tests that a chain config stored before irregular state changes existed is not
+// taken as processed with the new ones, the chain has to be rewound to them.
+func TestIrregularStateChangeUpgrade(t *testing.T) {
+	config := *params.TestChainConfig
+	config.IrregularStateChanges = []*params.IrregularStateChange{{
+		Block:   big.NewInt(5),
+		Freezes: []common.Address{{0x01}},
+	}}
+	db := yocdb.NewMemDatabase()
+	legacy := &Genesis{Config: params.TestChainConfig}
+	legacy.MustCommit(db)
+
+	// Pretend the chain was already synced past the new state change
+	head := &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(1)}
+	rawdb.WriteHeader(db, head)
+	rawdb.WriteHeadHeaderHash(db, head.Hash())
+
+	_, _, err := SetupGenesisBlock(db, &Genesis{Config: &config})
+	if err, ok := err.(*params.ConfigCompatError); !ok || err.RewindTo != 4 {
+		t.Fatalf("error mismatch: have %v, want rewind to block 4", err)
+	}
+	stored := rawdb.ReadChainConfig(db, legacy.ToBlock(nil).Hash())
+	if stored.IrregularStateChanges != nil {
+		t.Errorf("stored config upgraded before the rewind: %v", stored.IrregularStateChanges)
+	}
+}
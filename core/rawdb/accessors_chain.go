@@ -10,13 +10,35 @@ import (
 
 	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
 	"github.com/Yocoin15/Yocoin_Sources/log"
 	"github.com/Yocoin15/Yocoin_Sources/rlp"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
 )
 
+// readAncient retrieves an item from the ancient store if the database has one
+// attached, returning nil otherwise.
+func readAncient(db DatabaseReader, kind string, number uint64) []byte {
+	if ancients, ok := db.(yocdb.AncientReader); ok {
+		data, _ := ancients.Ancient(kind, number)
+		return data
+	}
+	return nil
+}
+
+// isCanon is an internal utility method, to check whether the given number/hash
+// is part of the ancient (canon) set.
+func isCanon(db DatabaseReader, number uint64, hash common.Hash) bool {
+	data := readAncient(db, freezerHashTable, number)
+	return len(data) > 0 && bytes.Equal(data, hash[:])
+}
+
 // ReadCanonicalHash retrieves the hash assigned to a canonical block number.
 func ReadCanonicalHash(db DatabaseReader, number uint64) common.Hash {
-	data, _ := db.Get(headerHashKey(number))
+	data := readAncient(db, freezerHashTable, number)
+	if len(data) == 0 {
+		data, _ = db.Get(headerHashKey(number))
+	}
 	if len(data) == 0 {
 		return common.Hash{}
 	}
@@ -37,6 +59,24 @@ func DeleteCanonicalHash(db DatabaseDeleter, number uint64) {
 	}
 }
 
+// ReadAllHashes retrieves all the hashes assigned to blocks at a certain heights,
+// both canonical and reorged forks included, which are still in the key-value
+// store.
+func ReadAllHashes(db yocdb.Iteratee, number uint64) []common.Hash {
+	prefix := headerKeyPrefix(number)
+
+	hashes := make([]common.Hash, 0, 1)
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	for it.Next() {
+		if key := it.Key(); len(key) == len(prefix)+common.HashLength {
+			hashes = append(hashes, common.BytesToHash(key[len(key)-common.HashLength:]))
+		}
+	}
+	return hashes
+}
+
 // ReadHeaderNumber returns the header number assigned to a hash.
 func ReadHeaderNumber(db DatabaseReader, hash common.Hash) *uint64 {
 	data, _ := db.Get(headerNumberKey(hash))
@@ -115,12 +155,21 @@ func WriteFastTrieProgress(db DatabaseWriter, count uint64) {
 
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
-	data, _ := db.Get(headerKey(number, hash))
+	// First try to look up the data in ancient database. Extra hash comparison
+	// is necessary since ancient database only maintains the canonical data.
+	data := readAncient(db, freezerHeaderTable, number)
+	if len(data) > 0 && crypto.Keccak256Hash(data) == hash {
+		return data
+	}
+	data, _ = db.Get(headerKey(number, hash))
 	return data
 }
 
 // HasHeader verifies the existence of a block header corresponding to the hash.
 func HasHeader(db DatabaseReader, hash common.Hash, number uint64) bool {
+	if isCanon(db, number, hash) {
+		return true
+	}
 	if has, err := db.Has(headerKey(number, hash)); !has || err != nil {
 		return false
 	}
@@ -177,6 +226,13 @@ func DeleteHeader(db DatabaseDeleter, hash common.Hash, number uint64) {
 
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	// Ancient bodies can only be verified through the canonical hash table
+	if isCanon(db, number, hash) {
+		data := readAncient(db, freezerBodiesTable, number)
+		if len(data) > 0 {
+			return data
+		}
+	}
 	data, _ := db.Get(blockBodyKey(number, hash))
 	return data
 }
@@ -190,6 +246,9 @@ func WriteBodyRLP(db DatabaseWriter, hash common.Hash, number uint64, rlp rlp.Ra
 
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db DatabaseReader, hash common.Hash, number uint64) bool {
+	if isCanon(db, number, hash) {
+		return true
+	}
 	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
 		return false
 	}
@@ -226,9 +285,22 @@ func DeleteBody(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadTdRLP retrieves a block's total difficulty corresponding to the hash in
+// its raw RLP database encoding.
+func ReadTdRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	if isCanon(db, number, hash) {
+		data := readAncient(db, freezerDifficultyTable, number)
+		if len(data) > 0 {
+			return data
+		}
+	}
+	data, _ := db.Get(headerTDKey(number, hash))
+	return data
+}
+
 // ReadTd retrieves a block's total difficulty corresponding to the hash.
 func ReadTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
-	data, _ := db.Get(headerTDKey(number, hash))
+	data := ReadTdRLP(db, hash, number)
 	if len(data) == 0 {
 		return nil
 	}
@@ -258,10 +330,23 @@ func DeleteTd(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadReceiptsRLP retrieves all the transaction receipts belonging to a block
+// in their raw RLP database encoding.
+func ReadReceiptsRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	if isCanon(db, number, hash) {
+		data := readAncient(db, freezerReceiptTable, number)
+		if len(data) > 0 {
+			return data
+		}
+	}
+	data, _ := db.Get(blockReceiptsKey(number, hash))
+	return data
+}
+
 // ReadReceipts retrieves all the transaction receipts belonging to a block.
 func ReadReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
 	// Retrieve the flattened receipt slice
-	data, _ := db.Get(blockReceiptsKey(number, hash))
+	data := ReadReceiptsRLP(db, hash, number)
 	if len(data) == 0 {
 		return nil
 	}
@@ -302,6 +387,41 @@ func DeleteReceipts(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadBalanceRewrites retrieves the audit records of all the balance rewrites
+// applied outside of transaction execution while processing a block.
+func ReadBalanceRewrites(db DatabaseReader, hash common.Hash, number uint64) []*types.BalanceRewrite {
+	data, _ := db.Get(blockRewritesKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	rewrites := []*types.BalanceRewrite{}
+	if err := rlp.DecodeBytes(data, &rewrites); err != nil {
+		log.Error("Invalid balance rewrite array RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return rewrites
+}
+
+// WriteBalanceRewrites stores the audit records of all the balance rewrites
+// applied outside of transaction execution while processing a block.
+func WriteBalanceRewrites(db DatabaseWriter, hash common.Hash, number uint64, rewrites []*types.BalanceRewrite) {
+	bytes, err := rlp.EncodeToBytes(rewrites)
+	if err != nil {
+		log.Crit("Failed to encode block balance rewrites", "err", err)
+	}
+	if err := db.Put(blockRewritesKey(number, hash), bytes); err != nil {
+		log.Crit("Failed to store block balance rewrites", "err", err)
+	}
+}
+
+// DeleteBalanceRewrites removes all balance rewrite records associated with a
+// block hash.
+func DeleteBalanceRewrites(db DatabaseDeleter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockRewritesKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block balance rewrites", "err", err)
+	}
+}
+
 // ReadBlock retrieves an entire block corresponding to the hash, assembling it
 // back from the stored header and body. If either the header or body could not
 // be retrieved nil is returned.
@@ -326,14 +446,58 @@ func WriteBlock(db DatabaseWriter, block *types.Block) {
 	WriteHeader(db, block.Header())
 }
 
+// WriteAncientBlock writes entire block data into ancient store and returns the
+// total written data size.
+func WriteAncientBlock(db yocdb.AncientWriter, block *types.Block, receipts types.Receipts, td *big.Int) int {
+	// Encode all block components to RLP format.
+	headerBlob, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		log.Crit("Failed to RLP encode block header", "err", err)
+	}
+	bodyBlob, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		log.Crit("Failed to RLP encode body", "err", err)
+	}
+	storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		storageReceipts[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	receiptBlob, err := rlp.EncodeToBytes(storageReceipts)
+	if err != nil {
+		log.Crit("Failed to RLP encode block receipts", "err", err)
+	}
+	tdBlob, err := rlp.EncodeToBytes(td)
+	if err != nil {
+		log.Crit("Failed to RLP encode block total difficulty", "err", err)
+	}
+	// Write all blob to flatten files.
+	err = db.AppendAncient(block.NumberU64(), block.Hash().Bytes(), headerBlob, bodyBlob, receiptBlob, tdBlob)
+	if err != nil {
+		log.Crit("Failed to write block data to ancient store", "err", err)
+	}
+	return len(headerBlob) + len(bodyBlob) + len(receiptBlob) + len(tdBlob) + common.HashLength
+}
+
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteBalanceRewrites(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
 }
 
+// DeleteBlockWithoutNumber removes the block data moved into the ancient store,
+// keeping the hash to number mapping and the balance rewrite records around.
+func DeleteBlockWithoutNumber(db DatabaseDeleter, hash common.Hash, number uint64) {
+	DeleteReceipts(db, hash, number)
+	if err := db.Delete(headerKey(number, hash)); err != nil {
+		log.Crit("Failed to delete header", "err", err)
+	}
+	DeleteBody(db, hash, number)
+	DeleteTd(db, hash, number)
+}
+
 // FindCommonAncestor returns the last common ancestor of two block headers
 func FindCommonAncestor(db DatabaseReader, a, b *types.Header) *types.Header {
 	for bn := b.Number.Uint64(); a.Number.Uint64() > bn; {
@@ -5,7 +5,9 @@ package rawdb
 
 import (
 	"bytes"
+	"io/ioutil"
 	"math/big"
+	"os"
 	"testing"
 
 	"github.com/Yocoin15/Yocoin_Sources/common"
@@ -304,3 +306,108 @@ func TestBlockReceiptStorage(t *testing.T) {
 		t.Fatalf("deleted receipts returned: %v", rs)
 	}
 }
+
+// Tests that balance rewrite records associated with a certain block hash can be
+// stored and retrieved.
+func TestBalanceRewriteStorage(t *testing.T) {
+	db := yocdb.NewMemDatabase()
+
+	rewrites := []*types.BalanceRewrite{
+		{BlockNumber: 1, Address: common.Address{0x11}, Reason: types.RewriteMoveOut, OldBalance: big.NewInt(10), NewBalance: big.NewInt(4)},
+		{BlockNumber: 1, Address: common.Address{0x22}, Reason: types.RewriteMoveIn, OldBalance: big.NewInt(0), NewBalance: big.NewInt(6)},
+	}
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	if rs := ReadBalanceRewrites(db, hash, 1); len(rs) != 0 {
+		t.Fatalf("non existent rewrites returned: %v", rs)
+	}
+	WriteBalanceRewrites(db, hash, 1, rewrites)
+	rs := ReadBalanceRewrites(db, hash, 1)
+	if len(rs) != len(rewrites) {
+		t.Fatalf("rewrite count mismatch: have %d, want %d", len(rs), len(rewrites))
+	}
+	for i := range rewrites {
+		rlpHave, _ := rlp.EncodeToBytes(rs[i])
+		rlpWant, _ := rlp.EncodeToBytes(rewrites[i])
+
+		if !bytes.Equal(rlpHave, rlpWant) {
+			t.Fatalf("rewrite #%d: rewrite mismatch: have %v, want %v", i, rs[i], rewrites[i])
+		}
+	}
+	DeleteBalanceRewrites(db, hash, 1)
+	if rs := ReadBalanceRewrites(db, hash, 1); len(rs) != 0 {
+		t.Fatalf("deleted rewrites returned: %v", rs)
+	}
+}
+
+// Tests that blocks moved into the ancient store can be retrieved through the
+// regular accessors without any data remaining in the key-value store.
+func TestAncientStorage(t *testing.T) {
+	frdir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temp freezer dir: %v", err)
+	}
+	defer os.RemoveAll(frdir)
+
+	kvdb := yocdb.NewMemDatabase()
+	db, err := NewDatabaseWithFreezer(kvdb, frdir, "")
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend: %v", err)
+	}
+	defer db.Close()
+
+	// Create a test block chain to freeze
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Extra: []byte("genesis")})
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:      big.NewInt(1),
+		ParentHash:  genesis.Hash(),
+		Extra:       []byte("test block"),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+	})
+	hash, number := block.Hash(), block.NumberU64()
+
+	if blob := ReadHeaderRLP(db, hash, number); len(blob) > 0 {
+		t.Fatalf("non existent header returned")
+	}
+	if blob := ReadBodyRLP(db, hash, number); len(blob) > 0 {
+		t.Fatalf("non existent body returned")
+	}
+	// Write both blocks into the ancient store
+	WriteAncientBlock(db, genesis, nil, big.NewInt(1))
+	WriteAncientBlock(db, block, nil, big.NewInt(2))
+
+	if frozen, _ := db.Ancients(); frozen != 2 {
+		t.Fatalf("frozen item count mismatch: have %d, want %d", frozen, 2)
+	}
+	if have := ReadCanonicalHash(db, number); have != hash {
+		t.Fatalf("canonical hash mismatch: have %x, want %x", have, hash)
+	}
+	if entry := ReadBlock(db, hash, number); entry == nil || entry.Hash() != hash {
+		t.Fatalf("frozen block mismatch: have %v, want %v", entry, block)
+	}
+	if td := ReadTd(db, hash, number); td == nil || td.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("frozen td mismatch: have %v, want %v", td, 2)
+	}
+	if !HasHeader(db, hash, number) || !HasBody(db, hash, number) {
+		t.Fatalf("frozen block not reported as present")
+	}
+	// Ensure nothing leaked into the key-value store and mismatching hashes miss
+	if blob := ReadHeaderRLP(kvdb, hash, number); len(blob) > 0 {
+		t.Fatalf("frozen header present in key-value store")
+	}
+	fakeHash := common.BytesToHash([]byte{0x01, 0x02, 0x03})
+	if blob := ReadHeaderRLP(db, fakeHash, number); len(blob) > 0 {
+		t.Fatalf("invalid header returned")
+	}
+	if blob := ReadBodyRLP(db, fakeHash, number); len(blob) > 0 {
+		t.Fatalf("invalid body returned")
+	}
+	// Truncate the ancients and ensure the block is gone
+	if err := db.TruncateAncients(1); err != nil {
+		t.Fatalf("failed to truncate ancients: %v", err)
+	}
+	if entry := ReadBlock(db, hash, number); entry != nil {
+		t.Fatalf("truncated block returned: %v", entry)
+	}
+}
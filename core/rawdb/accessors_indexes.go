@@ -4,6 +4,8 @@
 package rawdb
 
 import (
+	"encoding/binary"
+
 	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/log"
@@ -92,3 +94,65 @@ func WriteBloomBits(db DatabaseWriter, bit uint, section uint64, head common.Has
 		log.Crit("Failed to store bloom bits", "err", err)
 	}
 }
+
+// ReadTraceBloom retrieves the bloom of the addresses appearing in the call
+// traces of a block, and whether it was indexed at all.
+func ReadTraceBloom(db DatabaseReader, hash common.Hash, number uint64) (types.Bloom, bool) {
+	data, _ := db.Get(traceBloomKey(number, hash))
+	if len(data) != types.BloomByteLength {
+		return types.Bloom{}, false
+	}
+	return types.BytesToBloom(data), true
+}
+
+// WriteTraceBloom stores the bloom of the addresses appearing in the call traces
+// of a block.
+func WriteTraceBloom(db DatabaseWriter, hash common.Hash, number uint64, bloom types.Bloom) {
+	if err := db.Put(traceBloomKey(number, hash), bloom.Bytes()); err != nil {
+		log.Crit("Failed to store trace bloom", "err", err)
+	}
+}
+
+// ReadBlockTraces retrieves the cached traces of the block at a given height,
+// along with the hash of the block traced.
+func ReadBlockTraces(db DatabaseReader, number uint64) (common.Hash, []byte) {
+	data, _ := db.Get(traceCacheKey(number))
+	if len(data) < common.HashLength {
+		return common.Hash{}, nil
+	}
+	return common.BytesToHash(data[:common.HashLength]), data[common.HashLength:]
+}
+
+// WriteBlockTraces stores the traces of a block into the cache, replacing any
+// traces of a different block at the same height.
+func WriteBlockTraces(db DatabaseWriter, hash common.Hash, number uint64, traces []byte) {
+	if err := db.Put(traceCacheKey(number), append(hash.Bytes(), traces...)); err != nil {
+		log.Crit("Failed to store block traces", "err", err)
+	}
+}
+
+// DeleteBlockTraces removes the cached traces of the block at a given height.
+func DeleteBlockTraces(db DatabaseDeleter, number uint64) {
+	if err := db.Delete(traceCacheKey(number)); err != nil {
+		log.Crit("Failed to delete block traces", "err", err)
+	}
+}
+
+// ReadTraceCacheTail retrieves the number of the oldest block possibly having
+// traces in the cache.
+func ReadTraceCacheTail(db DatabaseReader) *uint64 {
+	data, _ := db.Get(traceCacheTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteTraceCacheTail stores the number of the oldest block possibly having
+// traces in the cache.
+func WriteTraceCacheTail(db DatabaseWriter, number uint64) {
+	if err := db.Put(traceCacheTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store trace cache tail", "err", err)
+	}
+}
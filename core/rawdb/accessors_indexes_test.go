@@ -53,3 +53,28 @@ func TestLookupStorage(t *testing.T) {
 		}
 	}
 }
+
+// Tests that cached block traces can be stored, replaced and deleted.
+func TestBlockTracesStorage(t *testing.T) {
+	db := yocdb.NewMemDatabase()
+
+	if hash, traces := ReadBlockTraces(db, 314); hash != (common.Hash{}) || traces != nil {
+		t.Fatalf("non existent traces returned: %x %s", hash, traces)
+	}
+	WriteBlockTraces(db, common.Hash{1}, 314, []byte(`[{"result":1}]`))
+	WriteBlockTraces(db, common.Hash{2}, 314, []byte(`[{"result":2}]`))
+	if hash, traces := ReadBlockTraces(db, 314); hash != (common.Hash{2}) || string(traces) != `[{"result":2}]` {
+		t.Fatalf("traces mismatch: have %x %s, want %x %s", hash, traces, common.Hash{2}, `[{"result":2}]`)
+	}
+	DeleteBlockTraces(db, 314)
+	if hash, traces := ReadBlockTraces(db, 314); hash != (common.Hash{}) || traces != nil {
+		t.Fatalf("deleted traces returned: %x %s", hash, traces)
+	}
+	if tail := ReadTraceCacheTail(db); tail != nil {
+		t.Fatalf("non existent tail returned: %d", *tail)
+	}
+	WriteTraceCacheTail(db, 42)
+	if tail := ReadTraceCacheTail(db); tail == nil || *tail != 42 {
+		t.Fatalf("tail mismatch: have %v, want 42", tail)
+	}
+}
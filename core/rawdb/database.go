@@ -0,0 +1,289 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package rawdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// freezerdb is a database wrapper that enables freezer data retrievals.
+type freezerdb struct {
+	yocdb.Database
+	ancients *freezer
+}
+
+// Close implements yocdb.Database, closing both the fast key-value store as
+// well as the slow ancient tables.
+func (frdb *freezerdb) Close() {
+	if err := frdb.ancients.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	frdb.Database.Close()
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (frdb *freezerdb) HasAncient(kind string, number uint64) (bool, error) {
+	return frdb.ancients.HasAncient(kind, number)
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (frdb *freezerdb) Ancient(kind string, number uint64) ([]byte, error) {
+	return frdb.ancients.Ancient(kind, number)
+}
+
+// Ancients returns the length of the frozen items.
+func (frdb *freezerdb) Ancients() (uint64, error) {
+	return frdb.ancients.Ancients()
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (frdb *freezerdb) AncientSize(kind string) (uint64, error) {
+	return frdb.ancients.AncientSize(kind)
+}
+
+// AppendAncient injects all binary blobs belong to block at the end of the
+// append-only immutable table files.
+func (frdb *freezerdb) AppendAncient(number uint64, hash, header, body, receipts, td []byte) error {
+	return frdb.ancients.AppendAncient(number, hash, header, body, receipts, td)
+}
+
+// TruncateAncients discards any recent data above the provided threshold number.
+func (frdb *freezerdb) TruncateAncients(items uint64) error {
+	return frdb.ancients.TruncateAncients(items)
+}
+
+// Sync flushes all data tables to disk.
+func (frdb *freezerdb) Sync() error {
+	return frdb.ancients.Sync()
+}
+
+// NewDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store with a freezer moving immutable chain segments into cold
+// storage.
+func NewDatabaseWithFreezer(db yocdb.Database, freezer string, namespace string) (yocdb.Database, error) {
+	// Create the idle freezer instance
+	frdb, err := newFreezer(freezer, namespace)
+	if err != nil {
+		return nil, err
+	}
+	// Since the freezer can be stored separately from the user's key-value database,
+	// there's a fairly high probability that the user requests invalid combinations
+	// of the freezer and database. Ensure that we don't shoot ourselves in the foot
+	// by serving up conflicting data, leading to both datastores getting corrupted.
+	if kvgenesis, _ := db.Get(headerHashKey(0)); len(kvgenesis) > 0 {
+		if frozen, _ := frdb.Ancients(); frozen > 0 {
+			// If the freezer already contains something, ensure that the genesis blocks
+			// match, otherwise we might mix up freezers across chains and destroy both
+			// the freezer and the key-value store.
+			if frgenesis, _ := frdb.Ancient(freezerHashTable, 0); !bytes.Equal(kvgenesis, frgenesis) {
+				frdb.Close()
+				return nil, fmt.Errorf("genesis mismatch: %#x (leveldb) != %#x (ancients)", kvgenesis, frgenesis)
+			}
+			// Key-value store and freezer belong to the same network. Ensure that they
+			// are contiguous, otherwise we might end up with a non-functional freezer.
+			if kvhash, _ := db.Get(headerHashKey(frozen)); len(kvhash) == 0 {
+				// Subsequent header after the freezer limit is missing from the database.
+				// Reject startup is the database has a more recent head.
+				if number := ReadHeaderNumber(db, ReadHeadHeaderHash(db)); number != nil && *number > frozen-1 {
+					frdb.Close()
+					return nil, fmt.Errorf("gap (#%d) in the chain between ancients and leveldb", frozen)
+				}
+				// Database contains only older data than the freezer, this happens if the
+				// state was wiped and reinited from an existing freezer.
+			}
+			// Otherwise, key-value store continues where the freezer left off, all is fine.
+		} else {
+			// If the freezer is empty, ensure nothing was moved yet from the key-value
+			// store, otherwise we'll end up missing data. We check block #1 to decide
+			// if we froze anything previously or not, but do take care of databases with
+			// only the genesis block.
+			if ReadHeadHeaderHash(db) != common.BytesToHash(kvgenesis) {
+				// Key-value store contains more data than the genesis block, make sure we
+				// didn't freeze anything yet.
+				if kvblob, _ := db.Get(headerHashKey(1)); len(kvblob) == 0 {
+					frdb.Close()
+					return nil, errors.New("ancient chain segments already extracted, please set --datadir.ancient to the correct path")
+				}
+				// Block #1 is still in the database, we're allowed to init a new freezer
+			}
+			// Otherwise, the head header is still the genesis, we're allowed to init a new
+			// freezer.
+		}
+	}
+	// Freezer is consistent with the key-value database, permit combining the two
+	go frdb.freeze(db)
+
+	return &freezerdb{
+		Database: db,
+		ancients: frdb,
+	}, nil
+}
+
+// KeyValueStore returns the key-value store backing a chain database, stripping
+// off the ancient store if one is attached.
+func KeyValueStore(db yocdb.Database) yocdb.Database {
+	if frdb, ok := db.(*freezerdb); ok {
+		return frdb.Database
+	}
+	return db
+}
+
+// DatabaseStat is the number and the total size of the database entries falling
+// into a single category.
+type DatabaseStat struct {
+	Database string
+	Category string
+	Count    uint64
+	Size     common.StorageSize
+}
+
+// add accounts an entry of the given size to the stat.
+func (s *DatabaseStat) add(size common.StorageSize) {
+	s.Count++
+	s.Size += size
+}
+
+// InspectDatabase traverses the entire database, classifying every entry by the
+// key schema and summing up the entry counts and sizes of each category. If the
+// database has an ancient store attached, the sizes of its tables are reported
+// too.
+func InspectDatabase(db yocdb.Database) ([]DatabaseStat, error) {
+	it := db.NewIteratorWithPrefix(nil)
+	defer it.Release()
+
+	var (
+		count  uint64
+		start  = time.Now()
+		logged = time.Now()
+
+		// Key-value store statistics
+		headers     = DatabaseStat{Database: "Key-Value store", Category: "Headers"}
+		bodies      = DatabaseStat{Database: "Key-Value store", Category: "Bodies"}
+		receipts    = DatabaseStat{Database: "Key-Value store", Category: "Receipt lists"}
+		tds         = DatabaseStat{Database: "Key-Value store", Category: "Difficulties"}
+		numHashes   = DatabaseStat{Database: "Key-Value store", Category: "Block number->hash"}
+		hashNumbers = DatabaseStat{Database: "Key-Value store", Category: "Block hash->number"}
+		rewrites    = DatabaseStat{Database: "Key-Value store", Category: "Balance rewrites"}
+		txLookups   = DatabaseStat{Database: "Key-Value store", Category: "Transaction index"}
+		bloomBits   = DatabaseStat{Database: "Key-Value store", Category: "Bloombit index"}
+		traceBlooms = DatabaseStat{Database: "Key-Value store", Category: "Trace bloom index"}
+		traceCache  = DatabaseStat{Database: "Key-Value store", Category: "Trace cache"}
+		tries       = DatabaseStat{Database: "Key-Value store", Category: "Trie nodes"}
+		preimages   = DatabaseStat{Database: "Key-Value store", Category: "Trie preimages"}
+		accountSnap = DatabaseStat{Database: "Key-Value store", Category: "Account snapshot"}
+		storageSnap = DatabaseStat{Database: "Key-Value store", Category: "Storage snapshot"}
+		cliqueSnaps = DatabaseStat{Database: "Key-Value store", Category: "Clique snapshots"}
+		lesStats    = DatabaseStat{Database: "Key-Value store", Category: "LES cost statistics"}
+		chainIndex  = DatabaseStat{Database: "Key-Value store", Category: "Chain indexers"}
+		configs     = DatabaseStat{Database: "Key-Value store", Category: "Chain configs"}
+		metadata    = DatabaseStat{Database: "Key-Value store", Category: "Singleton metadata"}
+		unaccounted = DatabaseStat{Database: "Key-Value store", Category: "Unaccounted"}
+	)
+	// Singleton entries tracking the chain and snapshot metadata
+	singletons := [][]byte{
+		databaseVerisionKey, headHeaderKey, headBlockKey, headFastBlockKey, fastTrieProgressKey,
+		snapshotRootKey, snapshotJournalKey, snapshotGeneratorKey,
+	}
+	for it.Next() {
+		var (
+			key  = it.Key()
+			size = common.StorageSize(len(key) + len(it.Value()))
+		)
+		switch {
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
+			headers.add(size)
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength+len(headerTDSuffix)) && bytes.HasSuffix(key, headerTDSuffix):
+			tds.add(size)
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+len(headerHashSuffix)) && bytes.HasSuffix(key, headerHashSuffix):
+			numHashes.add(size)
+		case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
+			hashNumbers.add(size)
+		case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
+			bodies.add(size)
+		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
+			receipts.add(size)
+		case bytes.HasPrefix(key, blockRewritesPrefix) && len(key) == (len(blockRewritesPrefix)+8+common.HashLength):
+			rewrites.add(size)
+		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
+			txLookups.add(size)
+		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
+			bloomBits.add(size)
+		case bytes.HasPrefix(key, traceBloomPrefix) && len(key) == (len(traceBloomPrefix)+8+common.HashLength):
+			traceBlooms.add(size)
+		case bytes.HasPrefix(key, TraceCachePrefix):
+			traceCache.add(size)
+		case bytes.HasPrefix(key, preimagePrefix) && len(key) == (len(preimagePrefix)+common.HashLength):
+			preimages.add(size)
+		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
+			accountSnap.add(size)
+		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
+			storageSnap.add(size)
+		case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
+			configs.add(size)
+		case bytes.HasPrefix(key, []byte("clique-")) && len(key) == 7+common.HashLength:
+			// Clique snapshots are stored by the consensus engine, see consensus/clique
+			cliqueSnaps.add(size)
+		case bytes.HasPrefix(key, []byte("_requestCostStats")):
+			// Request cost statistics are stored by the light server, see les/server.go
+			lesStats.add(size)
+		case len(key) == common.HashLength:
+			tries.add(size)
+		case bytes.HasPrefix(key, []byte("i")):
+			chainIndex.add(size)
+		default:
+			var accounted bool
+			for _, meta := range singletons {
+				if bytes.Equal(key, meta) {
+					metadata.add(size)
+					accounted = true
+					break
+				}
+			}
+			if !accounted {
+				unaccounted.add(size)
+			}
+		}
+		count++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	stats := []DatabaseStat{
+		headers, bodies, receipts, tds, numHashes, hashNumbers, rewrites, txLookups,
+		bloomBits, traceBlooms, traceCache, tries, preimages, accountSnap, storageSnap,
+		cliqueSnaps, lesStats, chainIndex, configs, metadata, unaccounted,
+	}
+	// Report the ancient tables too if the database has any
+	if frozen, err := db.Ancients(); err == nil {
+		for _, table := range []struct {
+			kind     string
+			category string
+		}{
+			{freezerHeaderTable, "Headers"},
+			{freezerBodiesTable, "Bodies"},
+			{freezerReceiptTable, "Receipt lists"},
+			{freezerDifficultyTable, "Difficulties"},
+			{freezerHashTable, "Block number->hash"},
+		} {
+			size, err := db.AncientSize(table.kind)
+			if err != nil {
+				return nil, err
+			}
+			stats = append(stats, DatabaseStat{Database: "Ancient store", Category: table.category, Count: frozen, Size: common.StorageSize(size)})
+		}
+	}
+	return stats, nil
+}
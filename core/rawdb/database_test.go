@@ -0,0 +1,69 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// Tests that the database inspection classifies the entries by their key schema.
+func TestInspectDatabase(t *testing.T) {
+	db := yocdb.NewMemDatabase()
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: []byte("test block")})
+	WriteBlock(db, block)
+	WriteTd(db, block.Hash(), block.NumberU64(), big.NewInt(1))
+	WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
+	WriteHeadBlockHash(db, block.Hash())
+	WritePreimages(db, 1, map[common.Hash][]byte{{0x01}: {0x02}, {0x03}: {0x04}})
+	WriteAccountSnapshot(db, common.Hash{0x05}, []byte{0x06})
+	WriteTraceBloom(db, block.Hash(), block.NumberU64(), types.Bloom{})
+
+	traces := yocdb.NewTable(db, string(TraceCachePrefix))
+	WriteBlockTraces(traces, block.Hash(), block.NumberU64(), []byte("[]"))
+	WriteTraceCacheTail(traces, block.NumberU64())
+
+	db.Put(common.Hash{0x07}.Bytes(), []byte{0x08})                               // trie node
+	db.Put(append([]byte("clique-"), common.Hash{0x09}.Bytes()...), []byte{0x0a}) // clique snapshot
+	db.Put([]byte("unknown key"), []byte{0x0b})
+
+	stats, err := InspectDatabase(db)
+	if err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	want := map[string]uint64{
+		"Headers":            1,
+		"Bodies":             1,
+		"Receipt lists":      1,
+		"Difficulties":       1,
+		"Block number->hash": 1,
+		"Block hash->number": 1,
+		"Trie preimages":     2,
+		"Account snapshot":   1,
+		"Trace bloom index":  1,
+		"Trace cache":        2,
+		"Trie nodes":         1,
+		"Clique snapshots":   1,
+		"Singleton metadata": 1,
+		"Unaccounted":        1,
+	}
+	for _, stat := range stats {
+		if stat.Database != "Key-Value store" {
+			t.Errorf("unexpected database %q reported", stat.Database)
+			continue
+		}
+		if stat.Count != want[stat.Category] {
+			t.Errorf("%s: item count mismatch: have %d, want %d", stat.Category, stat.Count, want[stat.Category])
+		}
+		if (stat.Count == 0) != (stat.Size == 0) {
+			t.Errorf("%s: size %v inconsistent with item count %d", stat.Category, stat.Size, stat.Count)
+		}
+	}
+}
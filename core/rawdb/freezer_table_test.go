@@ -0,0 +1,174 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Yocoin15/Yocoin_Sources/metrics"
+)
+
+func init() {
+	rand.Seed(time.Now().Unix())
+}
+
+// getChunk returns a chunk of data of the given size, filled with the given byte.
+func getChunk(size int, b int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(b)
+	}
+	return data
+}
+
+// newTestTable opens a freezer table with fresh metrics in the given directory.
+func newTestTable(dir, name string, maxFileSize uint32, noCompression bool) (*freezerTable, error) {
+	return newCustomTable(dir, name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NewCounter(), maxFileSize, noCompression)
+}
+
+// Tests that items can be appended to a freezer table and read back, both
+// within a single data file and across multiple ones.
+func TestFreezerBasics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Set cutoff at 50 bytes, each item is 15 bytes so three fit per file
+	f, err := newTestTable(dir, fmt.Sprintf("unittest-%d", rand.Uint64()), 50, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for x := 0; x < 255; x++ {
+		if err := f.Append(uint64(x), getChunk(15, x)); err != nil {
+			t.Fatalf("failed to append item %d: %v", x, err)
+		}
+	}
+	for y := 0; y < 255; y++ {
+		exp := getChunk(15, y)
+		got, err := f.Retrieve(uint64(y))
+		if err != nil {
+			t.Fatalf("failed to retrieve item %d: %v", y, err)
+		}
+		if !bytes.Equal(got, exp) {
+			t.Fatalf("item %d mismatch: have %x, want %x", y, got, exp)
+		}
+	}
+	// Check that out-of-bounds and out-of-order accesses are rejected
+	if _, err := f.Retrieve(255); err != errOutOfBounds {
+		t.Errorf("out of bounds retrieval error mismatch: have %v, want %v", err, errOutOfBounds)
+	}
+	if err := f.Append(300, getChunk(15, 0)); err == nil {
+		t.Errorf("out of order append accepted")
+	}
+}
+
+// Tests that a table truncated externally (e.g. a crash mid-write leaving the
+// index ahead of the data) is repaired back to a consistent state on open.
+func TestFreezerRepairDanglingHead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fname := fmt.Sprintf("dangling_headtest-%d", rand.Uint64())
+
+	// Fill a table and close it
+	{
+		f, err := newTestTable(dir, fname, 50, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for x := 0; x < 255; x++ {
+			f.Append(uint64(x), getChunk(15, x))
+		}
+		f.Close()
+	}
+	// Chop a few bytes off the index, invalidating the last entry
+	idxFile, err := os.OpenFile(filepath.Join(dir, fmt.Sprintf("%s.ridx", fname)), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := idxFile.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idxFile.Truncate(stat.Size() - 4)
+	idxFile.Close()
+
+	// Reopen the table, the partial entry must be discarded
+	f, err := newTestTable(dir, fname, 50, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Retrieve(253); err != nil {
+		t.Errorf("item before the damage unreadable: %v", err)
+	}
+	if _, err := f.Retrieve(254); err == nil {
+		t.Errorf("damaged item still readable")
+	}
+	// The table must accept the missing item again
+	if err := f.Append(254, getChunk(15, 254)); err != nil {
+		t.Fatalf("failed to re-append damaged item: %v", err)
+	}
+	if got, err := f.Retrieve(254); err != nil || !bytes.Equal(got, getChunk(15, 254)) {
+		t.Errorf("re-appended item mismatch: have %x, want %x (err %v)", got, getChunk(15, 254), err)
+	}
+}
+
+// Tests that truncating a table discards the trailing items, including any data
+// files that become unused, and that the table survives a reopen.
+func TestFreezerTruncate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fname := fmt.Sprintf("truncation-%d", rand.Uint64())
+
+	f, err := newTestTable(dir, fname, 50, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 30; x++ {
+		f.Append(uint64(x), getChunk(15, x))
+	}
+	if err := f.truncate(10); err != nil {
+		t.Fatalf("failed to truncate table: %v", err)
+	}
+	if _, err := f.Retrieve(10); err == nil {
+		t.Errorf("truncated item still readable")
+	}
+	f.Close()
+
+	// Reopen and make sure the truncation persisted
+	f, err = newTestTable(dir, fname, 50, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.items != 10 {
+		t.Fatalf("item count mismatch after reopen: have %d, want %d", f.items, 10)
+	}
+	for y := 0; y < 10; y++ {
+		if got, err := f.Retrieve(uint64(y)); err != nil || !bytes.Equal(got, getChunk(15, y)) {
+			t.Errorf("item %d mismatch: have %x, want %x (err %v)", y, got, getChunk(15, y), err)
+		}
+	}
+}
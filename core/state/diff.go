@@ -0,0 +1,179 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+)
+
+// AccountState is the balance, nonce, code and a subset of the storage slots of
+// an account at a given point in time.
+type AccountState struct {
+	Balance *big.Int
+	Nonce   uint64
+	Code    []byte
+	Storage map[common.Hash]common.Hash
+}
+
+// AccountDiff is the state of an account before and after the modifications
+// tracked by the journal. Pre is nil for created accounts and Post is nil for
+// deleted ones.
+type AccountDiff struct {
+	Pre  *AccountState
+	Post *AccountState
+}
+
+// accountOrigin collects the original values of an account while replaying the
+// journal. Values not modified by any entry are taken from the current state.
+type accountOrigin struct {
+	created bool         // Whether the account didn't exist originally
+	reset   *stateObject // Original account overwritten by a creation, if any
+
+	balance *big.Int
+	nonce   *uint64
+	code    []byte
+	hasCode bool
+	storage map[common.Hash]common.Hash
+}
+
+// Diff returns the accounts modified since the state was last finalised along
+// with their original and current values, as recorded by the journal entries.
+// Only the modified storage slots are included, and accounts ending up with
+// their original values are omitted. Suicided accounts, and empty ones if
+// deleteEmptyObjects is set, are reported deleted the way Finalise would.
+func (self *StateDB) Diff(deleteEmptyObjects bool) map[common.Address]*AccountDiff {
+	origins := make(map[common.Address]*accountOrigin)
+	origin := func(addr common.Address) *accountOrigin {
+		if o, ok := origins[addr]; ok {
+			return o
+		}
+		o := &accountOrigin{storage: make(map[common.Hash]common.Hash)}
+		origins[addr] = o
+		return o
+	}
+	// Replay the journal, keeping the first recorded value of every field
+	for _, entry := range self.journal.entries {
+		switch ch := entry.(type) {
+		case createObjectChange:
+			if _, ok := origins[*ch.account]; !ok {
+				origin(*ch.account).created = true
+			}
+		case resetObjectChange:
+			o := origin(ch.prev.address)
+			if o.created || o.reset != nil {
+				break
+			}
+			if ch.prev.deleted {
+				o.created = true
+				break
+			}
+			o.reset = ch.prev
+			if o.balance == nil {
+				o.balance = new(big.Int).Set(ch.prev.Balance())
+			}
+			if o.nonce == nil {
+				nonce := ch.prev.Nonce()
+				o.nonce = &nonce
+			}
+			if !o.hasCode {
+				o.code, o.hasCode = ch.prev.Code(self.db), true
+			}
+		case suicideChange:
+			if o := origin(*ch.account); o.balance == nil {
+				o.balance = new(big.Int).Set(ch.prevbalance)
+			}
+		case balanceChange:
+			if o := origin(*ch.account); o.balance == nil {
+				o.balance = new(big.Int).Set(ch.prev)
+			}
+		case nonceChange:
+			if o := origin(*ch.account); o.nonce == nil {
+				nonce := ch.prev
+				o.nonce = &nonce
+			}
+		case codeChange:
+			if o := origin(*ch.account); !o.hasCode {
+				o.code, o.hasCode = ch.prevcode, true
+			}
+		case storageChange:
+			o := origin(*ch.account)
+			if _, ok := o.storage[ch.key]; ok {
+				break
+			}
+			if o.reset != nil {
+				o.storage[ch.key] = o.reset.GetState(self.db, ch.key)
+			} else {
+				o.storage[ch.key] = ch.prevalue
+			}
+		case touchChange:
+			origin(*ch.account)
+		}
+	}
+	// Assemble the original and current state of the modified accounts
+	diff := make(map[common.Address]*AccountDiff)
+	for addr, o := range origins {
+		obj := self.stateObjects[addr]
+		if obj != nil && obj.deleted {
+			obj = nil
+		}
+		var post *AccountState
+		if obj != nil && !obj.suicided && !(deleteEmptyObjects && obj.empty()) {
+			post = &AccountState{
+				Balance: new(big.Int).Set(obj.Balance()),
+				Nonce:   obj.Nonce(),
+				Code:    obj.Code(self.db),
+				Storage: make(map[common.Hash]common.Hash, len(o.storage)),
+			}
+			for key := range o.storage {
+				post.Storage[key] = obj.GetState(self.db, key)
+			}
+		}
+		var pre *AccountState
+		if !o.created && obj != nil {
+			pre = &AccountState{
+				Balance: o.balance,
+				Code:    o.code,
+				Storage: o.storage,
+			}
+			if pre.Balance == nil {
+				pre.Balance = new(big.Int).Set(obj.Balance())
+			}
+			if o.nonce != nil {
+				pre.Nonce = *o.nonce
+			} else {
+				pre.Nonce = obj.Nonce()
+			}
+			if !o.hasCode {
+				pre.Code = obj.Code(self.db)
+			}
+		}
+		if pre == nil && post == nil {
+			continue
+		}
+		if pre != nil && post != nil && pre.equal(post) {
+			continue
+		}
+		diff[addr] = &AccountDiff{Pre: pre, Post: post}
+	}
+	return diff
+}
+
+// equal reports whether two account states hold the same values.
+func (s *AccountState) equal(other *AccountState) bool {
+	if s.Balance.Cmp(other.Balance) != 0 || s.Nonce != other.Nonce || !bytes.Equal(s.Code, other.Code) {
+		return false
+	}
+	if len(s.Storage) != len(other.Storage) {
+		return false
+	}
+	for key, value := range s.Storage {
+		if other.Storage[key] != value {
+			return false
+		}
+	}
+	return true
+}
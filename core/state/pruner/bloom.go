@@ -0,0 +1,145 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package pruner
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+)
+
+// bloomHashes is the number of bit positions a key is mapped to in the filter.
+const bloomHashes = 4
+
+// stateBloom is a bloom filter used during the state pruning to record all
+// the trie nodes and contract codes reachable from the retained state roots.
+//
+// The keys inserted are all Keccak256 hashes, so the filter doesn't rehash
+// them, but uses distinct 8 byte slices of the key as independent hashes.
+// False positives only cause some stale data to survive the pruning, they
+// never lead to the deletion of live data.
+type stateBloom struct {
+	roots []common.Hash // State roots the filter was built for
+	bits  []byte        // Bit array of the filter
+}
+
+// bloomHeader is the RLP encoded header of a persisted state bloom, followed by
+// the raw bit array of the filter.
+type bloomHeader struct {
+	Roots  []common.Hash
+	Hashes uint64
+	Size   uint64
+}
+
+// newStateBloom creates a bloom filter of the given size in megabytes.
+func newStateBloom(size uint64, roots []common.Hash) *stateBloom {
+	return &stateBloom{
+		roots: roots,
+		bits:  make([]byte, size*1024*1024),
+	}
+}
+
+// positions returns the bit positions the given hash maps to.
+func (bloom *stateBloom) positions(hash []byte) [bloomHashes]uint64 {
+	var (
+		pos  [bloomHashes]uint64
+		bits = uint64(len(bloom.bits)) * 8
+	)
+	for i := range pos {
+		pos[i] = binary.BigEndian.Uint64(hash[i*8:]) % bits
+	}
+	return pos
+}
+
+// Put inserts a hash into the filter.
+func (bloom *stateBloom) Put(hash []byte) {
+	for _, pos := range bloom.positions(hash) {
+		bloom.bits[pos/8] |= 1 << (pos % 8)
+	}
+}
+
+// Contain returns whether the hash might be in the filter. False is returned
+// only if the hash was never inserted.
+func (bloom *stateBloom) Contain(hash []byte) bool {
+	for _, pos := range bloom.positions(hash) {
+		if bloom.bits[pos/8]&(1<<(pos%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Commit persists the filter into the given file. The filter is first written
+// into a temporary file which is atomically moved in place, so an interrupted
+// commit never leaves a partial filter behind.
+func (bloom *stateBloom) Commit(filename string) error {
+	tmp := filename + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	gz := gzip.NewWriter(f)
+	header := bloomHeader{Roots: bloom.roots, Hashes: bloomHashes, Size: uint64(len(bloom.bits))}
+	if err := rlp.Encode(gz, &header); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := gz.Write(bloom.bits); err != nil {
+		f.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// loadStateBloom reads a filter previously persisted by Commit.
+func loadStateBloom(filename string) (*stateBloom, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	r := bufio.NewReader(gz)
+	var header bloomHeader
+	if err := rlp.NewStream(r, 0).Decode(&header); err != nil {
+		return nil, err
+	}
+	if header.Hashes != bloomHashes {
+		return nil, errors.New("unsupported state bloom hash count")
+	}
+	bloom := &stateBloom{
+		roots: header.Roots,
+		bits:  make([]byte, header.Size),
+	}
+	if _, err := io.ReadFull(r, bloom.bits); err != nil {
+		return nil, err
+	}
+	return bloom, nil
+}
@@ -0,0 +1,294 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+// Package pruner implements the offline deletion of stale state data.
+package pruner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+	"github.com/Yocoin15/Yocoin_Sources/trie"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	// BloomFileName is the file name the state bloom of a pruning in progress
+	// is persisted with, so that an interrupted deletion can be resumed.
+	BloomFileName = "statebloom.bf.gz"
+
+	// MinBloomSize is the minimum size of the state bloom in megabytes.
+	MinBloomSize = 256
+
+	// retainedBlocks is the number of recent blocks whose states are retained
+	// if they were flushed to disk, matching the in-memory trie GC horizon.
+	retainedBlocks = 128
+)
+
+var (
+	// emptyRoot is the known root hash of an empty trie.
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+	// emptyCode is the known hash of the empty EVM bytecode.
+	emptyCode = crypto.Keccak256(nil)
+)
+
+// Pruner deletes all the state data from the database which isn't reachable
+// from a set of retained state roots. It must run while the node is offline:
+// the reachable trie nodes and contract codes are marked in a bloom filter,
+// then every unmarked hash keyed entry is deleted from the database.
+type Pruner struct {
+	db        *yocdb.LDBDatabase
+	bloomPath string
+	bloomSize uint64
+}
+
+// NewPruner creates a state pruner for the given database, persisting its
+// bloom filter of bloomSize megabytes into bloomPath.
+func NewPruner(db *yocdb.LDBDatabase, bloomPath string, bloomSize uint64) *Pruner {
+	if bloomSize < MinBloomSize {
+		log.Warn("Sanitizing state bloom size", "provided(MB)", bloomSize, "updated(MB)", MinBloomSize)
+		bloomSize = MinBloomSize
+	}
+	return &Pruner{
+		db:        db,
+		bloomPath: bloomPath,
+		bloomSize: bloomSize,
+	}
+}
+
+// Prune deletes all the state data not reachable from the given state root. If
+// the root is empty, the states of the recent blocks found on disk are retained
+// instead. A previously interrupted pruning is resumed first, without starting
+// a new one.
+func (p *Pruner) Prune(root common.Hash) error {
+	if common.FileExist(p.bloomPath) {
+		return RecoverPruning(p.db, p.bloomPath)
+	}
+	var roots []common.Hash
+	if root != (common.Hash{}) {
+		if !p.hasState(root) {
+			return fmt.Errorf("state %x not available", root)
+		}
+		roots = append(roots, root)
+	} else {
+		var err error
+		if roots, err = p.retainedRoots(); err != nil {
+			return err
+		}
+	}
+	bloom := newStateBloom(p.bloomSize, roots)
+	if err := p.mark(bloom); err != nil {
+		return err
+	}
+	// Persist the filter, from here on the pruning can only be resumed
+	if err := bloom.Commit(p.bloomPath); err != nil {
+		return err
+	}
+	return prune(p.db, bloom, p.bloomPath)
+}
+
+// RecoverPruning resumes a pruning interrupted during the deletion of the stale
+// state data. It's a noop if no pruning was in progress. As the deletion must not
+// be mixed with new state writes, it's meant to be called on node startup before
+// the chain is opened.
+func RecoverPruning(db *yocdb.LDBDatabase, bloomPath string) error {
+	if !common.FileExist(bloomPath) {
+		return nil
+	}
+	bloom, err := loadStateBloom(bloomPath)
+	if err != nil {
+		return fmt.Errorf("failed to load state bloom: %v", err)
+	}
+	log.Info("Resuming interrupted state pruning", "roots", len(bloom.roots))
+	return prune(db, bloom, bloomPath)
+}
+
+// hasState returns whether the root node of the given state is present on disk.
+func (p *Pruner) hasState(root common.Hash) bool {
+	ok, _ := p.db.Has(root[:])
+	return ok
+}
+
+// retainedRoots collects the state roots of the recent canonical blocks that are
+// present on disk. If none of the recent states were flushed, the most recent
+// state found below them is retained, to which the chain is rewound on the next
+// start. The genesis state is retained too if available.
+func (p *Pruner) retainedRoots() ([]common.Hash, error) {
+	head := rawdb.ReadHeadBlockHash(p.db)
+	number := rawdb.ReadHeaderNumber(p.db, head)
+	if number == nil {
+		return nil, errors.New("head block missing")
+	}
+	var (
+		roots  []common.Hash
+		oldest uint64
+	)
+	for n := *number; ; n-- {
+		header := rawdb.ReadHeader(p.db, rawdb.ReadCanonicalHash(p.db, n), n)
+		if header == nil {
+			return nil, fmt.Errorf("canonical header #%d missing", n)
+		}
+		if p.hasState(header.Root) {
+			roots, oldest = append(roots, header.Root), n
+		}
+		if n == 0 || (*number-n+1 >= retainedBlocks && len(roots) > 0) {
+			break
+		}
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("no state available on disk")
+	}
+	if *number-oldest >= retainedBlocks {
+		log.Warn("Recent states not on disk, chain will be rewound", "head", *number, "state", oldest)
+	}
+	if genesis := rawdb.ReadHeader(p.db, rawdb.ReadCanonicalHash(p.db, 0), 0); genesis != nil && oldest != 0 && p.hasState(genesis.Root) {
+		roots = append(roots, genesis.Root)
+	}
+	log.Info("Selected states to retain", "head", *number, "oldest", oldest, "states", len(roots))
+	return roots, nil
+}
+
+// mark inserts the hashes of all the trie nodes and contract codes reachable
+// from the roots of the filter into it.
+func (p *Pruner) mark(bloom *stateBloom) error {
+	var (
+		triedb   = trie.NewDatabase(p.db)
+		storages = make(map[common.Hash]struct{})
+		nodes    int
+		codes    int
+		start    = time.Now()
+		logged   = time.Now()
+	)
+	// markTrie marks all the nodes of a trie, invoking onLeaf for every leaf
+	markTrie := func(root common.Hash, onLeaf func(blob []byte) error) error {
+		t, err := trie.New(root, triedb)
+		if err != nil {
+			return err
+		}
+		it := t.NodeIterator(nil)
+		for it.Next(true) {
+			if hash := it.Hash(); hash != (common.Hash{}) {
+				bloom.Put(hash[:])
+				nodes++
+			}
+			if it.Leaf() && onLeaf != nil {
+				if err := onLeaf(it.LeafBlob()); err != nil {
+					return err
+				}
+			}
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Marking retained state", "nodes", nodes, "codes", codes, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+		return it.Error()
+	}
+	for _, root := range bloom.roots {
+		err := markTrie(root, func(blob []byte) error {
+			var account state.Account
+			if err := rlp.DecodeBytes(blob, &account); err != nil {
+				return err
+			}
+			if account.Root != emptyRoot {
+				if _, ok := storages[account.Root]; !ok {
+					storages[account.Root] = struct{}{}
+					if err := markTrie(account.Root, nil); err != nil {
+						return err
+					}
+				}
+			}
+			if !bytes.Equal(account.CodeHash, emptyCode) {
+				bloom.Put(account.CodeHash)
+				codes++
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark state %x: %v", root, err)
+		}
+	}
+	log.Info("Marked retained state", "nodes", nodes, "codes", codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// prune deletes the state data not marked in the filter, removes the persisted
+// filter and compacts the database.
+func prune(db *yocdb.LDBDatabase, bloom *stateBloom, bloomPath string) error {
+	if err := sweep(db, bloom); err != nil {
+		return err
+	}
+	// Deletion complete, there's nothing left to resume
+	if err := os.Remove(bloomPath); err != nil {
+		return err
+	}
+	start := time.Now()
+	log.Info("Compacting database")
+	if err := db.LDB().CompactRange(util.Range{}); err != nil {
+		return err
+	}
+	log.Info("Compacted database", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// sweep deletes all the trie nodes and contract codes from the database that
+// are not marked in the filter. Both are keyed by their bare hash, which no
+// other database entry is.
+func sweep(db *yocdb.LDBDatabase, bloom *stateBloom) error {
+	var (
+		batch  = db.NewBatch()
+		it     = db.NewIterator()
+		count  int
+		size   common.StorageSize
+		start  = time.Now()
+		logged = time.Now()
+	)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != common.HashLength || bloom.Contain(key) {
+			continue
+		}
+		count++
+		size += common.StorageSize(len(key) + len(it.Value()))
+		batch.Delete(key)
+
+		if batch.ValueSize() >= yocdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			// Keys are uniformly distributed hashes, estimate the progress from the position
+			var eta time.Duration
+			if done := binary.BigEndian.Uint64(key[:8]); done > 0 {
+				elapsed := time.Since(start)
+				eta = time.Duration(float64(elapsed) * (float64(math.MaxUint64)/float64(done) - 1))
+			}
+			log.Info("Pruning state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)), "eta", common.PrettyDuration(eta))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Pruned state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
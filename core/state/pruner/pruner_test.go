@@ -0,0 +1,167 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package pruner
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+var (
+	testKey, _   = crypto.GenerateKey()
+	testAddress  = crypto.PubkeyToAddress(testKey.PublicKey)
+	testContract = common.Address{0xcc}
+	testCode     = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+)
+
+// newTestChain creates an archive chain in a temporary database, every block of
+// which transfers funds to a new account, and returns the database along with
+// the imported blocks.
+func newTestChain(t *testing.T, n int) (*yocdb.LDBDatabase, string, []*types.Block) {
+	dir, err := ioutil.TempDir("", "yoc-pruner-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	db, err := yocdb.NewLDBDatabase(filepath.Join(dir, "chaindata"), 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			testAddress:  {Balance: big.NewInt(1000000000)},
+			testContract: {Balance: big.NewInt(1), Code: testCode, Storage: map[common.Hash]common.Hash{{0x01}: {0x01}}},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, yochash.NewFaker(), db, n, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(testAddress), common.Address{0x10, byte(i)}, big.NewInt(1000), params.TxGas, nil, nil), types.HomesteadSigner{}, testKey)
+		gen.AddTx(tx)
+	})
+	chain, _ := core.NewBlockChain(db, &core.CacheConfig{Disabled: true}, params.TestChainConfig, yochash.NewFaker(), vm.Config{})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	chain.Stop()
+
+	return db, dir, blocks
+}
+
+// checkPruned verifies that the state of the given block is complete, while the
+// states of the other ones were deleted.
+func checkPruned(t *testing.T, db *yocdb.LDBDatabase, retained *types.Block, blocks []*types.Block) {
+	statedb, err := state.New(retained.Root(), state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("retained state missing: %v", err)
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+	}
+	if it.Error != nil {
+		t.Fatalf("retained state incomplete: %v", it.Error)
+	}
+	if code := statedb.GetCode(testContract); string(code) != string(testCode) {
+		t.Errorf("contract code mismatch: have %x, want %x", code, testCode)
+	}
+	if value := statedb.GetState(testContract, common.Hash{0x01}); value != (common.Hash{0x01}) {
+		t.Errorf("contract storage mismatch: have %x, want %x", value, common.Hash{0x01})
+	}
+	for _, block := range blocks {
+		if block.Root() == retained.Root() {
+			continue
+		}
+		if ok, _ := db.Has(block.Root().Bytes()); ok {
+			t.Errorf("state of block #%d not pruned", block.NumberU64())
+		}
+	}
+}
+
+// Tests that pruning retains the entire state of the requested root and deletes
+// the state roots of all other blocks.
+func TestPrune(t *testing.T) {
+	db, dir, blocks := newTestChain(t, 8)
+	defer os.RemoveAll(dir)
+	defer db.Close()
+
+	head := blocks[len(blocks)-1]
+	pruner := &Pruner{db: db, bloomPath: filepath.Join(dir, BloomFileName), bloomSize: 1}
+	if err := pruner.Prune(head.Root()); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	checkPruned(t, db, head, blocks[:len(blocks)-1])
+
+	if common.FileExist(pruner.bloomPath) {
+		t.Errorf("state bloom not removed after pruning")
+	}
+}
+
+// Tests that the roots of the recent blocks are retained if no root is given.
+func TestPruneRetainedRoots(t *testing.T) {
+	db, dir, blocks := newTestChain(t, 4)
+	defer os.RemoveAll(dir)
+	defer db.Close()
+
+	pruner := &Pruner{db: db, bloomPath: filepath.Join(dir, BloomFileName), bloomSize: 1}
+	roots, err := pruner.retainedRoots()
+	if err != nil {
+		t.Fatalf("failed to select retained roots: %v", err)
+	}
+	// All blocks are recent, expect the head down to the genesis
+	if len(roots) != len(blocks)+1 {
+		t.Fatalf("retained root count mismatch: have %d, want %d", len(roots), len(blocks)+1)
+	}
+	for i, root := range roots[:len(blocks)] {
+		if want := blocks[len(blocks)-1-i].Root(); root != want {
+			t.Errorf("retained root %d mismatch: have %x, want %x", i, root, want)
+		}
+	}
+}
+
+// Tests that an interrupted pruning is resumed with its persisted state bloom.
+func TestRecoverPruning(t *testing.T) {
+	db, dir, blocks := newTestChain(t, 8)
+	defer os.RemoveAll(dir)
+	defer db.Close()
+
+	// Mark the middle state and persist the bloom, as if the deletion was interrupted
+	var (
+		retained  = blocks[3]
+		bloomPath = filepath.Join(dir, BloomFileName)
+		pruner    = &Pruner{db: db, bloomPath: bloomPath, bloomSize: 1}
+		bloom     = newStateBloom(pruner.bloomSize, []common.Hash{retained.Root()})
+	)
+	if err := pruner.mark(bloom); err != nil {
+		t.Fatalf("failed to mark state: %v", err)
+	}
+	if err := bloom.Commit(bloomPath); err != nil {
+		t.Fatalf("failed to persist state bloom: %v", err)
+	}
+	// Resuming must delete everything but the marked state, even if a new pruning
+	// is requested for a different root
+	if err := pruner.Prune(blocks[len(blocks)-1].Root()); err != nil {
+		t.Fatalf("failed to resume pruning: %v", err)
+	}
+	checkPruned(t, db, retained, blocks)
+
+	if common.FileExist(bloomPath) {
+		t.Errorf("state bloom not removed after pruning")
+	}
+	// With no pruning in progress, recovery is a noop
+	if err := RecoverPruning(db, bloomPath); err != nil {
+		t.Fatalf("failed to recover without pruning in progress: %v", err)
+	}
+}
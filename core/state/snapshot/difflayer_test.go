@@ -0,0 +1,166 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package snapshot
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// randomHash generates a random blob of data and returns it as a hash.
+func randomHash() common.Hash {
+	var hash common.Hash
+	if n, err := rand.Read(hash[:]); n != common.HashLength || err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// emptyLayer returns an empty disk layer to stack diff layers onto.
+func emptyLayer() *diskLayer {
+	return &diskLayer{
+		diskdb: yocdb.NewMemDatabase(),
+		cache:  newCleanCache(1),
+	}
+}
+
+// Tests that merging something into a disk layer persists it into the database
+// and invalidates any previously written and cached values, while deleted
+// accounts take their storage with them.
+func TestMergeBasics(t *testing.T) {
+	var (
+		destructs = make(map[common.Hash]struct{})
+		accounts  = make(map[common.Hash][]byte)
+		storage   = make(map[common.Hash]map[common.Hash][]byte)
+	)
+	// Fill up a parent
+	for i := 0; i < 100; i++ {
+		h := randomHash()
+		data := randomHash().Bytes()
+
+		accounts[h] = data
+		if rand.Intn(4) == 0 {
+			destructs[h] = struct{}{}
+		}
+		if rand.Intn(2) == 0 {
+			accStorage := make(map[common.Hash][]byte)
+			value := make([]byte, 32)
+			rand.Read(value)
+			accStorage[randomHash()] = value
+			storage[h] = accStorage
+		}
+	}
+	// Add some (identical) layers on top
+	parent := newDiffLayer(emptyLayer(), common.Hash{}, copyDestructs(destructs), copyAccounts(accounts), copyStorage(storage))
+	child := newDiffLayer(parent, common.Hash{}, copyDestructs(destructs), copyAccounts(accounts), copyStorage(storage))
+	child = newDiffLayer(child, common.Hash{}, copyDestructs(destructs), copyAccounts(accounts), copyStorage(storage))
+	child = newDiffLayer(child, common.Hash{}, copyDestructs(destructs), copyAccounts(accounts), copyStorage(storage))
+	child = newDiffLayer(child, common.Hash{}, copyDestructs(destructs), copyAccounts(accounts), copyStorage(storage))
+
+	// And flatten
+	merged := (child.flatten()).(*diffLayer)
+
+	{ // Check account lists
+		if have, want := len(merged.accountData), len(accounts); have != want {
+			t.Errorf("accountData wrong: have %v, want %v", have, want)
+		}
+		for hash, data := range accounts {
+			if have, err := merged.AccountRLP(hash); err != nil || !bytes.Equal(have, data) {
+				t.Errorf("account %x mismatch: have %x, want %x (err %v)", hash, have, data, err)
+			}
+		}
+	}
+	{ // Check destruct set
+		if have, want := len(merged.destructSet), len(destructs); have != want {
+			t.Errorf("destructSet wrong: have %v, want %v", have, want)
+		}
+	}
+	{ // Check storage lists
+		i := 0
+		for aHash, sMap := range storage {
+			if have, want := len(merged.storageData), i; have < want {
+				t.Errorf("[1] storageData wrong: have %v, want %v", have, want)
+			}
+			for sHash, data := range sMap {
+				if have, err := merged.Storage(aHash, sHash); err != nil || !bytes.Equal(have, data) {
+					t.Errorf("slot %x:%x mismatch: have %x, want %x (err %v)", aHash, sHash, have, data, err)
+				}
+			}
+			i++
+		}
+	}
+	// The flattened layers must not be usable any more
+	if _, err := parent.AccountRLP(randomHash()); err != ErrSnapshotStale {
+		t.Errorf("flattened parent not stale: %v", err)
+	}
+}
+
+// Tests that deleted and recreated accounts shadow the storage of all the layers
+// below them, while not touching the slots written on top.
+func TestMergeDelete(t *testing.T) {
+	var (
+		account = randomHash()
+		oldSlot = randomHash()
+		newSlot = randomHash()
+	)
+	base := newDiffLayer(emptyLayer(), randomHash(), nil,
+		map[common.Hash][]byte{account: {0x01}},
+		map[common.Hash]map[common.Hash][]byte{account: {oldSlot: {0x01}}},
+	)
+	deleted := newDiffLayer(base, randomHash(),
+		map[common.Hash]struct{}{account: {}}, nil, nil,
+	)
+	recreated := newDiffLayer(deleted, randomHash(), nil,
+		map[common.Hash][]byte{account: {0x02}},
+		map[common.Hash]map[common.Hash][]byte{account: {newSlot: {0x02}}},
+	)
+	check := func(layer snapshot, name string, wantAcc []byte, wantOld, wantNew []byte) {
+		if blob, err := layer.AccountRLP(account); err != nil || !bytes.Equal(blob, wantAcc) {
+			t.Errorf("%s: account mismatch: have %x, want %x (err %v)", name, blob, wantAcc, err)
+		}
+		if blob, err := layer.Storage(account, oldSlot); err != nil || !bytes.Equal(blob, wantOld) {
+			t.Errorf("%s: old slot mismatch: have %x, want %x (err %v)", name, blob, wantOld, err)
+		}
+		if blob, err := layer.Storage(account, newSlot); err != nil || !bytes.Equal(blob, wantNew) {
+			t.Errorf("%s: new slot mismatch: have %x, want %x (err %v)", name, blob, wantNew, err)
+		}
+	}
+	check(base, "base", []byte{0x01}, []byte{0x01}, nil)
+	check(deleted, "deleted", nil, nil, nil)
+	check(recreated, "recreated", []byte{0x02}, nil, []byte{0x02})
+
+	// Flatten everything and ensure the same data is returned
+	check(recreated.flatten(), "flattened", []byte{0x02}, nil, []byte{0x02})
+}
+
+func copyDestructs(destructs map[common.Hash]struct{}) map[common.Hash]struct{} {
+	copy := make(map[common.Hash]struct{})
+	for hash := range destructs {
+		copy[hash] = struct{}{}
+	}
+	return copy
+}
+
+func copyAccounts(accounts map[common.Hash][]byte) map[common.Hash][]byte {
+	copy := make(map[common.Hash][]byte)
+	for hash, blob := range accounts {
+		copy[hash] = blob
+	}
+	return copy
+}
+
+func copyStorage(storage map[common.Hash]map[common.Hash][]byte) map[common.Hash]map[common.Hash][]byte {
+	copy := make(map[common.Hash]map[common.Hash][]byte)
+	for accHash, slots := range storage {
+		copy[accHash] = make(map[common.Hash][]byte)
+		for slotHash, blob := range slots {
+			copy[accHash][slotHash] = blob
+		}
+	}
+	return copy
+}
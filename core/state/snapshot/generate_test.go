@@ -0,0 +1,163 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+	"github.com/Yocoin15/Yocoin_Sources/trie"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// testAccount mirrors the consensus representation of an account in the state
+// trie, duplicated here to avoid an import cycle with the state package.
+type testAccount struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// testState is a state trie assembled for the snapshot tests, along with all
+// the flat data that a complete snapshot of it is expected to contain.
+type testState struct {
+	diskdb   yocdb.Database
+	triedb   *trie.Database
+	root     common.Hash
+	accounts map[common.Hash][]byte
+	storage  map[common.Hash]map[common.Hash][]byte
+}
+
+// newTestState creates a state trie with the requested number of accounts, every
+// second one of them having the given number of storage slots, and commits it
+// into a fresh in-memory database.
+func newTestState(t *testing.T, accounts int, slots int) *testState {
+	var (
+		diskdb = yocdb.NewMemDatabase()
+		triedb = trie.NewDatabase(diskdb)
+		state  = &testState{
+			diskdb:   diskdb,
+			triedb:   triedb,
+			accounts: make(map[common.Hash][]byte),
+			storage:  make(map[common.Hash]map[common.Hash][]byte),
+		}
+	)
+	accTrie, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+	for i := 0; i < accounts; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		acc := testAccount{
+			Nonce:    uint64(i),
+			Balance:  big.NewInt(int64(1000 * i)),
+			Root:     emptyRoot,
+			CodeHash: crypto.Keccak256(nil),
+		}
+		if i%2 == 0 && slots > 0 {
+			stTrie, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+			accStorage := make(map[common.Hash][]byte)
+			for j := 0; j < slots; j++ {
+				key := common.BigToHash(big.NewInt(int64(j)))
+				val, _ := rlp.EncodeToBytes(big.NewInt(int64(i*slots + j + 1)).Bytes())
+				stTrie.Update(key[:], val)
+				accStorage[crypto.Keccak256Hash(key[:])] = val
+			}
+			root, err := stTrie.Commit(nil)
+			if err != nil {
+				t.Fatalf("failed to commit storage trie: %v", err)
+			}
+			acc.Root = root
+			state.storage[crypto.Keccak256Hash(addr[:])] = accStorage
+		}
+		blob, _ := rlp.EncodeToBytes(acc)
+		accTrie.Update(addr[:], blob)
+		state.accounts[crypto.Keccak256Hash(addr[:])] = blob
+	}
+	root, err := accTrie.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit account trie: %v", err)
+	}
+	if err := triedb.Commit(root, false); err != nil {
+		t.Fatalf("failed to flush tries: %v", err)
+	}
+	state.root = root
+	return state
+}
+
+// verify checks that a snapshot layer contains exactly the flat data expected.
+func (s *testState) verify(t *testing.T, snap Snapshot) {
+	for hash, want := range s.accounts {
+		have, err := snap.AccountRLP(hash)
+		if err != nil {
+			t.Fatalf("account %x: failed to retrieve: %v", hash, err)
+		}
+		if !bytes.Equal(have, want) {
+			t.Errorf("account %x: mismatch: have %x, want %x", hash, have, want)
+		}
+		for slot, want := range s.storage[hash] {
+			have, err := snap.Storage(hash, slot)
+			if err != nil {
+				t.Fatalf("slot %x:%x: failed to retrieve: %v", hash, slot, err)
+			}
+			if !bytes.Equal(have, want) {
+				t.Errorf("slot %x:%x: mismatch: have %x, want %x", hash, slot, have, want)
+			}
+		}
+	}
+}
+
+// Tests that a snapshot generated from a state trie contains all the accounts
+// and storage slots, and that the generator marks itself done in the database.
+func TestGeneration(t *testing.T) {
+	state := newTestState(t, 100, 10)
+
+	snaps := New(state.diskdb, state.triedb, 16, state.root, false)
+	snap := snaps.Snapshot(state.root)
+	if snap == nil {
+		t.Fatalf("snapshot missing for root %x", state.root)
+	}
+	state.verify(t, snap)
+
+	// Make sure nothing above the trie contents ended up in the flat store
+	if blob, _ := snap.AccountRLP(randomHash()); blob != nil {
+		t.Errorf("unknown account resolved: %x", blob)
+	}
+	// Ensure the generator persisted its completion
+	var generator journalGenerator
+	if err := rlp.DecodeBytes(rawdb.ReadSnapshotGenerator(state.diskdb), &generator); err != nil {
+		t.Fatalf("failed to decode generator progress: %v", err)
+	}
+	if !generator.Done {
+		t.Errorf("generator not marked done")
+	}
+	if generator.Accounts != 100 || generator.Slots != 500 {
+		t.Errorf("generator stats mismatch: have %d accounts %d slots, want 100 accounts 500 slots", generator.Accounts, generator.Slots)
+	}
+}
+
+// Tests that regenerating a snapshot wipes any stale flat data left over in the
+// database from a previous snapshot.
+func TestGenerationWipe(t *testing.T) {
+	state := newTestState(t, 10, 5)
+
+	stale := randomHash()
+	rawdb.WriteAccountSnapshot(state.diskdb, stale, []byte{0x01})
+	rawdb.WriteStorageSnapshot(state.diskdb, stale, randomHash(), []byte{0x01})
+
+	snaps := New(state.diskdb, state.triedb, 16, state.root, false)
+	state.verify(t, snaps.Snapshot(state.root))
+
+	if blob := rawdb.ReadAccountSnapshot(state.diskdb, stale); len(blob) != 0 {
+		t.Errorf("stale account not wiped: %x", blob)
+	}
+	it := rawdb.IterateStorageSnapshots(state.diskdb, stale)
+	defer it.Release()
+	if it.Next() {
+		t.Errorf("stale storage not wiped: %x", it.Key())
+	}
+}
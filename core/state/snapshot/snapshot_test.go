@@ -0,0 +1,211 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+)
+
+// randomAccountSet generates a set of random accounts with the given hashes.
+func randomAccountSet(hashes ...common.Hash) map[common.Hash][]byte {
+	accounts := make(map[common.Hash][]byte)
+	for _, hash := range hashes {
+		accounts[hash] = randomHash().Bytes()
+	}
+	return accounts
+}
+
+// newTestTree creates a snapshot tree with a fully generated, empty disk layer
+// at the given root.
+func newTestTree(root common.Hash) *Tree {
+	base := emptyLayer()
+	base.root = root
+	rawdb.WriteSnapshotRoot(base.diskdb, root)
+
+	return &Tree{
+		diskdb: base.diskdb,
+		cache:  1,
+		layers: map[common.Hash]snapshot{root: base},
+	}
+}
+
+// Tests that updates are rejected if they would form a cycle or if the parent
+// is unknown, and that duplicate states are tracked only once.
+func TestUpdateValidation(t *testing.T) {
+	snaps := newTestTree(common.HexToHash("0x01"))
+
+	if err := snaps.Update(common.HexToHash("0x01"), common.HexToHash("0x01"), nil, nil, nil); err != errSnapshotCycle {
+		t.Errorf("self-referencing update error mismatch: have %v, want %v", err, errSnapshotCycle)
+	}
+	if err := snaps.Update(common.HexToHash("0x03"), common.HexToHash("0x02"), nil, nil, nil); err == nil {
+		t.Errorf("orphan update accepted")
+	}
+	if err := snaps.Update(common.HexToHash("0x02"), common.HexToHash("0x01"), nil, randomAccountSet(randomHash()), nil); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	layer := snaps.Snapshot(common.HexToHash("0x02"))
+	if err := snaps.Update(common.HexToHash("0x02"), common.HexToHash("0x01"), nil, nil, nil); err != nil {
+		t.Fatalf("failed to re-add diff layer: %v", err)
+	}
+	if snaps.Snapshot(common.HexToHash("0x02")) != layer {
+		t.Errorf("duplicate update replaced existing layer")
+	}
+}
+
+// Tests that if a diff layer is flattened fully into the disk layer, the data
+// is persisted, the previous layers become stale and the tree only retains the
+// new disk layer.
+func TestDiskLayerFullFlatten(t *testing.T) {
+	var (
+		snaps   = newTestTree(common.HexToHash("0x01"))
+		account = randomHash()
+		slot    = randomHash()
+	)
+	base := snaps.Snapshot(common.HexToHash("0x01"))
+
+	accounts := map[common.Hash][]byte{account: {0xaa}}
+	storage := map[common.Hash]map[common.Hash][]byte{account: {slot: {0xbb}}}
+	if err := snaps.Update(common.HexToHash("0x02"), common.HexToHash("0x01"), nil, accounts, storage); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	if err := snaps.Cap(common.HexToHash("0x02"), 0); err != nil {
+		t.Fatalf("failed to merge diff layer onto disk: %v", err)
+	}
+	// The old disk layer must be stale and the tree must only have the new one
+	if _, err := base.AccountRLP(account); err != ErrSnapshotStale {
+		t.Errorf("stale disk layer still accessible: %v", err)
+	}
+	if n := len(snaps.layers); n != 1 {
+		t.Errorf("post-cap layer count mismatch: have %d, want %d", n, 1)
+	}
+	if _, ok := snaps.layers[common.HexToHash("0x02")].(*diskLayer); !ok {
+		t.Fatalf("head layer not persisted")
+	}
+	// Check the data both through the snapshot and directly in the database
+	if root := rawdb.ReadSnapshotRoot(snaps.diskdb); root != common.HexToHash("0x02") {
+		t.Errorf("snapshot root mismatch: have %x, want %x", root, common.HexToHash("0x02"))
+	}
+	if blob := rawdb.ReadAccountSnapshot(snaps.diskdb, account); !bytes.Equal(blob, []byte{0xaa}) {
+		t.Errorf("account not persisted: have %x, want %x", blob, []byte{0xaa})
+	}
+	if blob := rawdb.ReadStorageSnapshot(snaps.diskdb, account, slot); !bytes.Equal(blob, []byte{0xbb}) {
+		t.Errorf("slot not persisted: have %x, want %x", blob, []byte{0xbb})
+	}
+	// Destruct the account and flatten again, ensuring the storage is dropped
+	if err := snaps.Update(common.HexToHash("0x03"), common.HexToHash("0x02"), map[common.Hash]struct{}{account: {}}, nil, nil); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	if err := snaps.Cap(common.HexToHash("0x03"), 0); err != nil {
+		t.Fatalf("failed to merge diff layer onto disk: %v", err)
+	}
+	if blob := rawdb.ReadAccountSnapshot(snaps.diskdb, account); len(blob) != 0 {
+		t.Errorf("destructed account not deleted: %x", blob)
+	}
+	if blob := rawdb.ReadStorageSnapshot(snaps.diskdb, account, slot); len(blob) != 0 {
+		t.Errorf("destructed slot not deleted: %x", blob)
+	}
+	if blob, err := snaps.Snapshot(common.HexToHash("0x03")).Storage(account, slot); err != nil || len(blob) != 0 {
+		t.Errorf("destructed slot still cached: %x (err %v)", blob, err)
+	}
+}
+
+// Tests that capping a layer chain keeps the requested number of layers, merges
+// the ones below into an accumulator and drops any sibling branches that got
+// invalidated by the flattening.
+func TestCapLayers(t *testing.T) {
+	snaps := newTestTree(common.HexToHash("0x01"))
+
+	// Build a chain 0x02..0x05 on top of the disk and a sibling to 0x03
+	for i := 2; i <= 5; i++ {
+		if err := snaps.Update(common.BigToHash(big.NewInt(int64(i))), common.BigToHash(big.NewInt(int64(i-1))), nil, randomAccountSet(randomHash()), nil); err != nil {
+			t.Fatalf("failed to create diff layer %d: %v", i, err)
+		}
+	}
+	if err := snaps.Update(common.HexToHash("0xff"), common.HexToHash("0x02"), nil, randomAccountSet(randomHash()), nil); err != nil {
+		t.Fatalf("failed to create sibling layer: %v", err)
+	}
+	if n := len(snaps.layers); n != 6 {
+		t.Fatalf("pre-cap layer count mismatch: have %d, want %d", n, 6)
+	}
+	// Cap to three diff layers, 0x02 and 0x03 should be merged into an accumulator
+	// while the sibling gets dropped
+	if err := snaps.Cap(common.HexToHash("0x05"), 3); err != nil {
+		t.Fatalf("failed to cap snapshot tree: %v", err)
+	}
+	for _, root := range []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x03"), common.HexToHash("0x04"), common.HexToHash("0x05")} {
+		if snaps.Snapshot(root) == nil {
+			t.Errorf("layer %x missing after cap", root)
+		}
+	}
+	for _, root := range []common.Hash{common.HexToHash("0x02"), common.HexToHash("0xff")} {
+		if snaps.Snapshot(root) != nil {
+			t.Errorf("layer %x retained after cap", root)
+		}
+	}
+	if parent := snaps.Snapshot(common.HexToHash("0x03")).(*diffLayer).parent; parent.Root() != common.HexToHash("0x01") {
+		t.Errorf("accumulator parent mismatch: have %x, want %x", parent.Root(), common.HexToHash("0x01"))
+	}
+}
+
+// Tests that a journalled snapshot tree can be loaded back with all its diff
+// layers intact.
+func TestJournalRoundtrip(t *testing.T) {
+	state := newTestState(t, 20, 5)
+	snaps := New(state.diskdb, state.triedb, 16, state.root, false)
+
+	var (
+		account   = randomHash()
+		slot      = randomHash()
+		destructs = make(map[common.Hash]struct{})
+	)
+	for hash := range state.accounts {
+		destructs[hash] = struct{}{}
+		break
+	}
+	accounts := map[common.Hash][]byte{account: {0xaa}}
+	storage := map[common.Hash]map[common.Hash][]byte{account: {slot: {0xbb}}}
+	if err := snaps.Update(common.HexToHash("0x02"), state.root, destructs, accounts, storage); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	if err := snaps.Update(common.HexToHash("0x03"), common.HexToHash("0x02"), nil, randomAccountSet(randomHash()), nil); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	base, err := snaps.Journal(common.HexToHash("0x03"))
+	if err != nil {
+		t.Fatalf("failed to journal snapshot: %v", err)
+	}
+	if base != state.root {
+		t.Errorf("journal base mismatch: have %x, want %x", base, state.root)
+	}
+	// Reload the snapshot and check that all layers and data are present
+	loaded := New(state.diskdb, state.triedb, 16, common.HexToHash("0x03"), false)
+	if n := len(loaded.layers); n != 3 {
+		t.Fatalf("loaded layer count mismatch: have %d, want %d", n, 3)
+	}
+	head := loaded.Snapshot(common.HexToHash("0x03"))
+	if blob, err := head.AccountRLP(account); err != nil || !bytes.Equal(blob, []byte{0xaa}) {
+		t.Errorf("account mismatch: have %x, want %x (err %v)", blob, []byte{0xaa}, err)
+	}
+	if blob, err := head.Storage(account, slot); err != nil || !bytes.Equal(blob, []byte{0xbb}) {
+		t.Errorf("slot mismatch: have %x, want %x (err %v)", blob, []byte{0xbb}, err)
+	}
+	for hash := range destructs {
+		if blob, err := head.AccountRLP(hash); err != nil || len(blob) != 0 {
+			t.Errorf("destructed account resolved: %x (err %v)", blob, err)
+		}
+	}
+	state.verify(t, loaded.Snapshot(state.root))
+
+	// Loading with a different head must discard the journal and regenerate
+	regen := New(state.diskdb, state.triedb, 16, state.root, false)
+	if n := len(regen.layers); n != 1 {
+		t.Fatalf("regenerated layer count mismatch: have %d, want %d", n, 1)
+	}
+	state.verify(t, regen.Snapshot(state.root))
+}
@@ -6,12 +6,12 @@ package state
 
 import (
 	"fmt"
-	"github.com/Yocoin15/Yocoin_Sources/nov2019"
 	"math/big"
 	"sort"
 	"sync"
 
 	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/state/snapshot"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/crypto"
 	"github.com/Yocoin15/Yocoin_Sources/log"
@@ -24,6 +24,14 @@ type revision struct {
 	journalIndex int
 }
 
+// proofList collects the trie nodes of a merkle proof in their traversal order.
+type proofList [][]byte
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, value)
+	return nil
+}
+
 var (
 	// emptyState is the known hash of an empty state trie entry.
 	emptyState = crypto.Keccak256Hash(nil)
@@ -41,6 +49,11 @@ type StateDB struct {
 	db   Database
 	trie Trie
 
+	// The snapshot tree the state diffs are pushed into on commit, and the
+	// snapshot of the state root accounts and storage are read through.
+	snaps *snapshot.Tree
+	snap  snapshot.Snapshot
+
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects      map[common.Address]*stateObject
 	stateObjectsDirty map[common.Address]struct{}
@@ -62,6 +75,10 @@ type StateDB struct {
 
 	preimages map[common.Hash][]byte
 
+	// Accounts credits to are discarded, as set by the consensus rules of the
+	// block being processed.
+	frozen map[common.Address]struct{}
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -88,6 +105,21 @@ func New(root common.Hash, db Database) (*StateDB, error) {
 	}, nil
 }
 
+// NewWithSnapshot creates a new state from a given trie, reading the accounts
+// and storage slots through the snapshot of the root if the tree maintains one.
+// The changes of the state are pushed into the tree on commit.
+func NewWithSnapshot(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error) {
+	sdb, err := New(root, db)
+	if err != nil {
+		return nil, err
+	}
+	if snaps != nil {
+		sdb.snaps = snaps
+		sdb.snap = snaps.Snapshot(root)
+	}
+	return sdb, nil
+}
+
 // setError remembers the first non-nil error it is called with.
 func (self *StateDB) setError(err error) {
 	if self.dbErr == nil {
@@ -107,6 +139,9 @@ func (self *StateDB) Reset(root common.Hash) error {
 		return err
 	}
 	self.trie = tr
+	if self.snaps != nil {
+		self.snap = self.snaps.Snapshot(root)
+	}
 	self.stateObjects = make(map[common.Address]*stateObject)
 	self.stateObjectsDirty = make(map[common.Address]struct{})
 	self.thash = common.Hash{}
@@ -232,6 +267,24 @@ func (self *StateDB) GetState(addr common.Address, bhash common.Hash) common.Has
 	return common.Hash{}
 }
 
+// GetProof returns the merkle proof for a given account.
+func (self *StateDB) GetProof(addr common.Address) ([][]byte, error) {
+	var proof proofList
+	err := self.trie.Prove(crypto.Keccak256(addr.Bytes()), 0, &proof)
+	return [][]byte(proof), err
+}
+
+// GetStorageProof returns the merkle proof for a given storage slot.
+func (self *StateDB) GetStorageProof(addr common.Address, key common.Hash) ([][]byte, error) {
+	var proof proofList
+	trie := self.StorageTrie(addr)
+	if trie == nil {
+		return proof, fmt.Errorf("storage trie for requested address does not exist")
+	}
+	err := trie.Prove(crypto.Keccak256(key.Bytes()), 0, &proof)
+	return [][]byte(proof), err
+}
+
 // Database retrieves the low level database supporting the lower level trie ops.
 func (self *StateDB) Database() Database {
 	return self.db
@@ -260,17 +313,24 @@ func (self *StateDB) HasSuicided(addr common.Address) bool {
  * SETTERS
  */
 
+// FreezeCredits replaces the set of accounts credits to are discarded. Credits to
+// the frozen accounts, as well as setting their balances, still create them but
+// leave their balances unchanged.
+func (self *StateDB) FreezeCredits(addrs []common.Address) {
+	self.frozen = nil
+	if len(addrs) > 0 {
+		self.frozen = make(map[common.Address]struct{}, len(addrs))
+		for _, addr := range addrs {
+			self.frozen[addr] = struct{}{}
+		}
+	}
+}
+
 // AddBalance adds amount to the account associated with addr.
-func (self *StateDB) AddBalance(addr common.Address, amount *big.Int, height *big.Int) {
+func (self *StateDB) AddBalance(addr common.Address, amount *big.Int) {
 	stateObject := self.GetOrNewStateObject(addr)
-	if is, amountReplace, _ := nov2019.GetNov2019MigrationManager().IfApplicableCriteria(addr, "addBalance"); is {
-		if nov2019.GetNov2019MigrationManager().IfOnSuitableHeight(height.Int64(), addr) {
-			if stateObject != nil {
-				log.Info(nov2019.LOG_PREFIX+"addBalance criteria met", "nov2019", true, "amount", amountReplace)
-				// see report. stateObject.SetBalance(amountReplace)
-			}
-			return
-		}
+	if _, ok := self.frozen[addr]; ok {
+		return
 	}
 	if stateObject != nil {
 		stateObject.AddBalance(amount)
@@ -278,35 +338,18 @@ func (self *StateDB) AddBalance(addr common.Address, amount *big.Int, height *bi
 }
 
 // SubBalance subtracts amount from the account associated with addr.
-func (self *StateDB) SubBalance(addr common.Address, amount *big.Int, height *big.Int) {
+func (self *StateDB) SubBalance(addr common.Address, amount *big.Int) {
 	stateObject := self.GetOrNewStateObject(addr)
-	if is, amountReplace, _ := nov2019.GetNov2019MigrationManager().IfApplicableCriteria(addr, "subBalance"); is {
-		if nov2019.GetNov2019MigrationManager().IfOnSuitableHeight(height.Int64(), addr) {
-			if stateObject != nil {
-				log.Info(nov2019.LOG_PREFIX+"subBalance criteria met", "nov2019", true, "amount", amountReplace)
-				// see report. stateObject.SetBalance(amountReplace)
-			}
-			//return
-		}
-	}
 	if stateObject != nil {
 		stateObject.SubBalance(amount)
 	}
 }
 
-func (self *StateDB) SetBalance(addr common.Address, amount *big.Int, height *big.Int) {
+func (self *StateDB) SetBalance(addr common.Address, amount *big.Int) {
 	stateObject := self.GetOrNewStateObject(addr)
-
-	if is, amountReplace, _ := nov2019.GetNov2019MigrationManager().IfApplicableCriteria(addr, "setBalance"); is {
-		if nov2019.GetNov2019MigrationManager().IfOnSuitableHeight(height.Int64(), addr) {
-			if stateObject != nil {
-				log.Info(nov2019.LOG_PREFIX+"setBalance criteria met", "nov2019", true, "amount", amountReplace)
-				//stateObject.SetBalance(amountReplace)
-			}
-			return
-		}
+	if _, ok := self.frozen[addr]; ok {
+		return
 	}
-
 	if stateObject != nil {
 		stateObject.SetBalance(amount)
 	}
@@ -333,6 +376,15 @@ func (self *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	}
 }
 
+// SetStorage replaces the entire storage for the specified account with given
+// storage. This function should only be used for debugging and simulations.
+func (self *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.SetStorage(storage)
+	}
+}
+
 // Suicide marks the given account as suicided.
 // This clears the account balance.
 //
@@ -354,14 +406,6 @@ func (self *StateDB) Suicide(addr common.Address) bool {
 	return true
 }
 
-func (self *StateDB) MakeSuicideChange(addr common.Address, prev bool, prevBalance *big.Int) suicideChange {
-	return suicideChange{
-		account:     &addr,
-		prev:        prev,
-		prevbalance: prevBalance,
-	}
-}
-
 //
 // Setting, updating & deleting state object methods.
 //
@@ -383,10 +427,6 @@ func (self *StateDB) deleteStateObject(stateObject *stateObject) {
 	self.setError(self.trie.TryDelete(addr[:]))
 }
 
-func (self *StateDB) GetStateObject(addr common.Address) (stateObject *stateObject) {
-	return self.GetStateObject(addr)
-}
-
 // Retrieve a state object given by the address. Returns nil if not found.
 func (self *StateDB) getStateObject(addr common.Address) (stateObject *stateObject) {
 	// Prefer 'live' objects.
@@ -397,8 +437,18 @@ func (self *StateDB) getStateObject(addr common.Address) (stateObject *stateObje
 		return obj
 	}
 
-	// Load the object from the database.
-	enc, err := self.trie.TryGet(addr[:])
+	// Load the object from the snapshot if available, falling back to the
+	// database if the account isn't covered by it.
+	var (
+		enc []byte
+		err error
+	)
+	if self.snap != nil {
+		enc, err = self.snap.AccountRLP(crypto.Keccak256Hash(addr[:]))
+	}
+	if self.snap == nil || err != nil {
+		enc, err = self.trie.TryGet(addr[:])
+	}
 	if len(enc) == 0 {
 		self.setError(err)
 		return nil
@@ -433,8 +483,15 @@ func (self *StateDB) createObject(addr common.Address) (newobj, prev *stateObjec
 	prev = self.getStateObject(addr)
 	newobj = newObject(self, addr, Account{})
 	newobj.setNonce(0) // sets the object to dirty
+	newobj.created = true
 	if prev == nil {
-		self.journal.append(createObjectChange{account: &addr})
+		if deleted := self.stateObjects[addr]; deleted != nil {
+			// Restore the deleted object on revert, it's shadowing the account
+			// of the snapshot the state might be read through
+			self.journal.append(resetObjectChange{prev: deleted})
+		} else {
+			self.journal.append(createObjectChange{account: &addr})
+		}
 	} else {
 		self.journal.append(resetObjectChange{prev: prev})
 	}
@@ -490,12 +547,15 @@ func (self *StateDB) Copy() *StateDB {
 	state := &StateDB{
 		db:                self.db,
 		trie:              self.db.CopyTrie(self.trie),
+		snaps:             self.snaps,
+		snap:              self.snap,
 		stateObjects:      make(map[common.Address]*stateObject, len(self.journal.dirties)),
 		stateObjectsDirty: make(map[common.Address]struct{}, len(self.journal.dirties)),
 		refund:            self.refund,
 		logs:              make(map[common.Hash][]*types.Log, len(self.logs)),
 		logSize:           self.logSize,
 		preimages:         make(map[common.Hash][]byte),
+		frozen:            self.frozen,
 		journal:           newJournal(),
 	}
 	// Copy the dirty states, logs, and preimages
@@ -614,6 +674,17 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 	for addr := range s.journal.dirties {
 		s.stateObjectsDirty[addr] = struct{}{}
 	}
+	// Collect the changes of the accounts for the snapshot if one is maintained
+	var (
+		snapDestructs map[common.Hash]struct{}
+		snapAccounts  map[common.Hash][]byte
+		snapStorage   map[common.Hash]map[common.Hash][]byte
+	)
+	if s.snap != nil {
+		snapDestructs = make(map[common.Hash]struct{})
+		snapAccounts = make(map[common.Hash][]byte)
+		snapStorage = make(map[common.Hash]map[common.Hash][]byte)
+	}
 	// Commit objects to the trie.
 	for addr, stateObject := range s.stateObjects {
 		_, isDirty := s.stateObjectsDirty[addr]
@@ -635,6 +706,22 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 			// Update the object in the main account trie.
 			s.updateStateObject(stateObject)
 		}
+		if s.snap != nil {
+			switch {
+			case stateObject.deleted:
+				snapDestructs[stateObject.addrHash] = struct{}{}
+			case isDirty:
+				// Recreated accounts drop all the storage of the previous one
+				if stateObject.created {
+					snapDestructs[stateObject.addrHash] = struct{}{}
+				}
+				snapAccounts[stateObject.addrHash], _ = rlp.EncodeToBytes(stateObject)
+				if len(stateObject.snapStorage) > 0 {
+					snapStorage[stateObject.addrHash] = stateObject.snapStorage
+				}
+			}
+			stateObject.snapStorage = nil
+		}
 		delete(s.stateObjectsDirty, addr)
 	}
 	// Write trie changes.
@@ -653,9 +740,16 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 		return nil
 	})
 	log.Debug("Trie cache stats after commit", "misses", trie.CacheMisses(), "unloads", trie.CacheUnloads())
-	return root, err
-}
 
-func (self *StateDB) GetJournal() *journal {
-	return self.journal
+	// If snapshotting is enabled, update the snapshot tree with this new version
+	if err == nil && s.snap != nil {
+		// Only update if there's a state transition
+		if parent := s.snap.Root(); parent != root {
+			if err := s.snaps.Update(root, parent, snapDestructs, snapAccounts, snapStorage); err != nil {
+				log.Warn("Failed to update snapshot tree", "from", parent, "to", root, "err", err)
+			}
+		}
+		s.snap = nil
+	}
+	return root, err
 }
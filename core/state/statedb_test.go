@@ -18,6 +18,7 @@ import (
 	check "gopkg.in/check.v1"
 
 	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/state/snapshot"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/yocdb"
 )
@@ -422,3 +423,187 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that replacing the storage of an account hides all of its persistent
+// slots, while updates and reverts on top of the replacement keep working.
+func TestSetStorage(t *testing.T) {
+	db := NewDatabase(yocdb.NewMemDatabase())
+	sdb, _ := New(common.Hash{}, db)
+
+	addr := common.HexToAddress("aaaa")
+	sdb.SetState(addr, common.HexToHash("01"), common.HexToHash("11"))
+	sdb.SetState(addr, common.HexToHash("02"), common.HexToHash("22"))
+	root, _ := sdb.Commit(false)
+	sdb, _ = New(root, db)
+
+	sdb.SetStorage(addr, map[common.Hash]common.Hash{common.HexToHash("02"): common.HexToHash("33")})
+	if value := sdb.GetState(addr, common.HexToHash("01")); value != (common.Hash{}) {
+		t.Errorf("replaced slot still visible: %x", value)
+	}
+	if value := sdb.GetState(addr, common.HexToHash("02")); value != common.HexToHash("33") {
+		t.Errorf("overridden slot mismatch: have %x, want %x", value, common.HexToHash("33"))
+	}
+	snapshot := sdb.Snapshot()
+	sdb.SetState(addr, common.HexToHash("02"), common.HexToHash("44"))
+	if value := sdb.Copy().GetState(addr, common.HexToHash("02")); value != common.HexToHash("44") {
+		t.Errorf("copied slot mismatch: have %x, want %x", value, common.HexToHash("44"))
+	}
+	sdb.RevertToSnapshot(snapshot)
+	if value := sdb.GetState(addr, common.HexToHash("02")); value != common.HexToHash("33") {
+		t.Errorf("reverted slot mismatch: have %x, want %x", value, common.HexToHash("33"))
+	}
+	// The persistent storage must be untouched
+	fresh, _ := New(root, db)
+	if value := fresh.GetState(addr, common.HexToHash("01")); value != common.HexToHash("11") {
+		t.Errorf("persistent slot mismatch: have %x, want %x", value, common.HexToHash("11"))
+	}
+}
+
+// Tests that state reads served from the flat snapshot match the ones served
+// from the tries, across account deletions, resurrections and state copies.
+func TestFlatSnapshotReads(t *testing.T) {
+	var (
+		diskdb = yocdb.NewMemDatabase()
+		sdb    = NewDatabase(diskdb)
+		state  *StateDB
+		roots  []common.Hash
+	)
+	// Create a base state with a few accounts holding storage
+	state, _ = New(common.Hash{}, sdb)
+	for i := byte(1); i <= 10; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		state.SetBalance(addr, big.NewInt(int64(i)))
+		state.SetNonce(addr, uint64(i))
+		state.SetState(addr, common.BytesToHash([]byte{i}), common.BytesToHash([]byte{i, i}))
+	}
+	root, _ := state.Commit(false)
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush base state: %v", err)
+	}
+	roots = append(roots, root)
+
+	snaps := snapshot.New(diskdb, sdb.TrieDB(), 16, root, false)
+
+	// Modify, delete and resurrect accounts on top, one block at a time
+	var (
+		victim = common.BytesToAddress([]byte{1})
+		slot   = common.BytesToHash([]byte{1})
+		fresh  = common.BytesToHash([]byte{0xff})
+	)
+	updates := []func(*StateDB){
+		func(state *StateDB) {
+			state.AddBalance(common.BytesToAddress([]byte{2}), big.NewInt(100))
+			state.SetState(common.BytesToAddress([]byte{3}), common.BytesToHash([]byte{3}), common.Hash{})
+			state.Suicide(victim)
+		},
+		func(state *StateDB) {
+			state.CreateAccount(victim)
+			state.SetBalance(victim, big.NewInt(1000))
+			state.SetState(victim, fresh, common.BytesToHash([]byte{0xff}))
+		},
+		func(state *StateDB) {
+			state.Suicide(victim)
+			state.Finalise(true)
+			state.CreateAccount(victim)
+			state.SetNonce(victim, 7)
+		},
+	}
+	for i, update := range updates {
+		state, _ = NewWithSnapshot(root, sdb, snaps)
+		update(state)
+		if root, _ = state.Commit(true); snaps.Snapshot(root) == nil {
+			t.Fatalf("block %d: snapshot missing for root %x", i, root)
+		}
+		roots = append(roots, root)
+	}
+	// Compare every state served from the snapshot against the trie version
+	for i, root := range roots {
+		trieState, _ := New(root, sdb)
+		snapState, _ := NewWithSnapshot(root, sdb, snaps)
+
+		for _, state := range []*StateDB{snapState, snapState.Copy()} {
+			for j := byte(1); j <= 10; j++ {
+				addr := common.BytesToAddress([]byte{j})
+				if have, want := state.Exist(addr), trieState.Exist(addr); have != want {
+					t.Errorf("state %d: account %x existence mismatch: have %v, want %v", i, addr, have, want)
+				}
+				if have, want := state.GetBalance(addr), trieState.GetBalance(addr); have.Cmp(want) != 0 {
+					t.Errorf("state %d: account %x balance mismatch: have %v, want %v", i, addr, have, want)
+				}
+				if have, want := state.GetNonce(addr), trieState.GetNonce(addr); have != want {
+					t.Errorf("state %d: account %x nonce mismatch: have %v, want %v", i, addr, have, want)
+				}
+				for _, key := range []common.Hash{common.BytesToHash([]byte{j}), slot, fresh} {
+					if have, want := state.GetState(addr, key), trieState.GetState(addr, key); have != want {
+						t.Errorf("state %d: account %x slot %x mismatch: have %x, want %x", i, addr, key, have, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Tests that the state diff reports the original and current values of the
+// modified accounts, skipping reverted and unchanged ones.
+func TestDiff(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(yocdb.NewMemDatabase()))
+
+	var (
+		changed   = common.Address{1}
+		reverted  = common.Address{2}
+		untouched = common.Address{3}
+		created   = common.Address{4}
+		suicided  = common.Address{5}
+	)
+	for _, addr := range []common.Address{changed, reverted, untouched, suicided} {
+		state.SetBalance(addr, big.NewInt(100))
+		state.SetNonce(addr, 1)
+		state.SetState(addr, common.Hash{1}, common.Hash{1})
+	}
+	state.SetCode(changed, []byte{0xde, 0xad})
+	state.Finalise(true)
+
+	state.SubBalance(changed, big.NewInt(10))
+	state.SetNonce(changed, 2)
+	state.SetState(changed, common.Hash{1}, common.Hash{2})
+	state.SetState(changed, common.Hash{1}, common.Hash{3})
+	state.SetState(changed, common.Hash{2}, common.Hash{4})
+	state.SetCode(changed, []byte{0xbe, 0xef})
+
+	snap := state.Snapshot()
+	state.AddBalance(reverted, big.NewInt(1))
+	state.SetState(reverted, common.Hash{1}, common.Hash{2})
+	state.RevertToSnapshot(snap)
+
+	state.AddBalance(untouched, big.NewInt(0))
+	state.AddBalance(created, big.NewInt(7))
+	state.Suicide(suicided)
+
+	diff := state.Diff(true)
+	if len(diff) != 3 {
+		t.Fatalf("diff size mismatch: have %d, want 3", len(diff))
+	}
+	want := &AccountDiff{
+		Pre: &AccountState{
+			Balance: big.NewInt(100),
+			Nonce:   1,
+			Code:    []byte{0xde, 0xad},
+			Storage: map[common.Hash]common.Hash{{1}: {1}, {2}: {}},
+		},
+		Post: &AccountState{
+			Balance: big.NewInt(90),
+			Nonce:   2,
+			Code:    []byte{0xbe, 0xef},
+			Storage: map[common.Hash]common.Hash{{1}: {3}, {2}: {4}},
+		},
+	}
+	if !reflect.DeepEqual(diff[changed], want) {
+		t.Errorf("changed account mismatch: have %+v/%+v, want %+v/%+v", diff[changed].Pre, diff[changed].Post, want.Pre, want.Post)
+	}
+	if d := diff[created]; d == nil || d.Pre != nil || d.Post == nil || d.Post.Balance.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("created account mismatch: have %+v", d)
+	}
+	if d := diff[suicided]; d == nil || d.Post != nil || d.Pre == nil || d.Pre.Balance.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("suicided account mismatch: have %+v", d)
+	}
+}
@@ -85,6 +85,7 @@ var (
 
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
+	blockedTxCounter     = metrics.NewRegisteredCounter("txpool/blocked", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
 )
 
@@ -113,6 +114,9 @@ type TxPoolConfig struct {
 	NoLocals  bool          // Whether local transaction handling should be disabled
 	Journal   string        // Journal of local transactions to survive node restarts
 	Rejournal time.Duration // Time interval to regenerate the local transaction journal
+	Blocklist string        // File of local address rules to enforce on top of the chain's ones
+
+	AddressRules []*params.AddressRule `toml:"-"` // Built-in and blocklist rules of the node, set up by the service
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -183,9 +187,11 @@ type TxPool struct {
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
+	pendingNumber *big.Int            // Number of the upcoming block for address rule checks
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *txJournal  // Journal of local transaction to back up to disk
+	locals  *accountSet   // Set of local transaction to exempt from eviction rules
+	journal *txJournal    // Journal of local transaction to back up to disk
+	policy  AddressPolicy // Address rules transactions are filtered by
 
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
@@ -219,6 +225,9 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	}
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(pool.all)
+
+	// Enforce any local address rules on top of the consensus ones
+	pool.policy = NewAddressPolicy(chainconfig.AddressRules, config.AddressRules)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
 	// If local transactions and journaling is enabled, load from disk
@@ -395,6 +404,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.pendingNumber = new(big.Int).Add(newHead.Number, common.Big1)
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -459,6 +469,12 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// AddressPolicy returns the address rules the transaction pool filters by, the
+// consensus ones of the chain merged with any locally configured ones.
+func (pool *TxPool) AddressPolicy() AddressPolicy {
+	return pool.policy
+}
+
 // State returns the virtual managed state of the transaction pool.
 func (pool *TxPool) State() *state.ManagedState {
 	pool.mu.RLock()
@@ -558,6 +574,11 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Reject transactions touching accounts restricted in the upcoming block
+	if err := pool.policy.Check(pool.pendingNumber, from, tx.To()); err != nil {
+		blockedTxCounter.Inc(1)
+		return err
+	}
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
 	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
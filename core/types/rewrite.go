@@ -0,0 +1,37 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+)
+
+//go:generate gencodec -type BalanceRewrite -field-override balanceRewriteMarshaling -out gen_rewrite_json.go
+
+// Reasons an account balance may be rewritten outside of transaction execution.
+const (
+	RewriteMoveOut = "move-out" // Balance moved away from the account
+	RewriteMoveIn  = "move-in"  // Balance moved into the account
+	RewriteFreeze  = "freeze"   // Remaining balance of the account zeroed
+)
+
+// BalanceRewrite is an audit record of an account balance modified by the node
+// outside of regular transaction execution, e.g. by an irregular state change.
+type BalanceRewrite struct {
+	BlockNumber uint64         `json:"blockNumber" gencodec:"required"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Address     common.Address `json:"address" gencodec:"required"`
+	Reason      string         `json:"reason" gencodec:"required"`
+	OldBalance  *big.Int       `json:"oldBalance" gencodec:"required"`
+	NewBalance  *big.Int       `json:"newBalance" gencodec:"required"`
+}
+
+type balanceRewriteMarshaling struct {
+	BlockNumber hexutil.Uint64
+	OldBalance  *hexutil.Big
+	NewBalance  *hexutil.Big
+}
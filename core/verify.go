@@ -0,0 +1,242 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/misc"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+	"github.com/Yocoin15/Yocoin_Sources/trie"
+)
+
+// BlockDivergence describes the first block whose re-execution did not reproduce
+// the canonical chain.
+type BlockDivergence struct {
+	Number  uint64      // Number of the divergent block
+	Hash    common.Hash // Hash of the divergent block
+	Root    common.Hash // State root of the canonical block
+	NewRoot common.Hash // State root produced by the re-execution (zero if execution failed)
+	Err     error       // Error the re-execution failed with, if any
+
+	TxIndex    int            // Index of the first divergent transaction (-1 if no transaction diverged)
+	TxHash     common.Hash    // Hash of the first divergent transaction
+	Receipt    *types.Receipt // Canonical receipt of the divergent transaction (nil if unavailable)
+	NewReceipt *types.Receipt // Re-executed receipt of the divergent transaction (nil if execution failed)
+	Accounts   []*AccountDiff // Accounts differing between the two post-block states
+	DiffErr    error          // Reason the account diff could not be produced, if any
+}
+
+// AccountDiff is a single account differing between the canonical and the
+// re-executed state of a block.
+type AccountDiff struct {
+	Hash    common.Hash     // Hash of the account address (the key in the state trie)
+	Address *common.Address // Account address, nil if its preimage is unknown
+	Old     *state.Account  // Canonical account, nil if it doesn't exist
+	New     *state.Account  // Re-executed account, nil if it doesn't exist
+}
+
+// verifyChain is the chain blocks are re-executed against, exposing the verified
+// configuration instead of the chain's own to the consensus engine, so the block
+// rewards follow it too.
+type verifyChain struct {
+	*BlockChain
+	config *params.ChainConfig
+}
+
+// Config implements consensus.ChainReader, returning the verified configuration.
+func (c *verifyChain) Config() *params.ChainConfig { return c.config }
+
+// VerifyChainRange re-executes the canonical blocks in the inclusive range
+// [from, to] on top of the stored state of block from-1, using the given chain
+// configuration, and compares the resulting state roots and receipts with the
+// canonical ones. It returns the first divergent block, or nil if the entire
+// range was reproduced exactly.
+func (bc *BlockChain) VerifyChainRange(config *params.ChainConfig, from, to uint64) (*BlockDivergence, error) {
+	if from == 0 {
+		return nil, fmt.Errorf("genesis block cannot be re-executed")
+	}
+	if from > to {
+		return nil, fmt.Errorf("start block %d after end block %d", from, to)
+	}
+	parent := bc.GetBlockByNumber(from - 1)
+	if parent == nil {
+		return nil, fmt.Errorf("block %d not found", from-1)
+	}
+	var (
+		database  = bc.stateCache
+		triedb    = database.TrieDB()
+		processor = &StateProcessor{config: config, bc: &verifyChain{bc, config}, engine: bc.engine}
+		root      = parent.Root()
+		start     = time.Now()
+		logged    = time.Now()
+	)
+	// Pin the parent state in memory for as long as it's needed
+	triedb.Reference(root, common.Hash{})
+	defer func() { triedb.Dereference(root) }()
+
+	for number := from; number <= to; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", number)
+		}
+		statedb, err := state.New(root, database)
+		if err != nil {
+			return nil, fmt.Errorf("state of block %d unavailable: %v", number-1, err)
+		}
+		receipts, _, _, err := processor.Process(block, statedb, vm.Config{})
+
+		var newRoot common.Hash
+		if err == nil {
+			if newRoot, err = statedb.Commit(config.IsEIP158(block.Number())); err != nil {
+				return nil, err
+			}
+			triedb.Reference(newRoot, common.Hash{})
+		}
+		if err != nil || newRoot != block.Root() || types.DeriveSha(receipts) != block.ReceiptHash() {
+			div := bc.divergence(config, database, root, block, newRoot, err)
+			if newRoot != (common.Hash{}) {
+				triedb.Dereference(newRoot)
+			}
+			return div, nil
+		}
+		triedb.Dereference(root)
+		root = newRoot
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying block range", "number", number, "remaining", to-number, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	return nil, nil
+}
+
+// divergence collects the details of a block whose re-execution on top of the
+// given parent state produced the state root newRoot (zero if execution failed
+// with err) instead of the canonical one.
+func (bc *BlockChain) divergence(config *params.ChainConfig, database state.Database, parentRoot common.Hash, block *types.Block, newRoot common.Hash, err error) *BlockDivergence {
+	div := &BlockDivergence{
+		Number:  block.NumberU64(),
+		Hash:    block.Hash(),
+		Root:    block.Root(),
+		NewRoot: newRoot,
+		Err:     err,
+		TxIndex: -1,
+	}
+	// Replay the transactions one by one to find the first divergent receipt
+	statedb, err := state.New(parentRoot, database)
+	if err != nil {
+		div.DiffErr = err
+		return div
+	}
+	var (
+		header  = block.Header()
+		stored  = rawdb.ReadReceipts(bc.db, block.Hash(), block.NumberU64())
+		usedGas = new(uint64)
+		gp      = new(GasPool).AddGas(block.GasLimit())
+	)
+	if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(block.Number()) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+	misc.ApplyIrregularStateChanges(config, statedb, block.Number())
+	for i, tx := range block.Transactions() {
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		receipt, _, err := ApplyTransaction(config, bc, nil, gp, statedb, header, tx, usedGas, vm.Config{})
+
+		var canonical *types.Receipt
+		if i < len(stored) {
+			canonical = stored[i]
+		}
+		if err != nil || canonical == nil || !receiptsEqual(receipt, canonical) {
+			div.TxIndex, div.TxHash = i, tx.Hash()
+			div.Receipt, div.NewReceipt = canonical, receipt
+			break
+		}
+	}
+	// Diff the post-block states if both of them are available
+	if newRoot == (common.Hash{}) {
+		div.DiffErr = fmt.Errorf("re-execution failed: %v", div.Err)
+		return div
+	}
+	div.Accounts, div.DiffErr = diffStates(database, block.Root(), newRoot)
+	return div
+}
+
+// receiptsEqual returns whether the consensus fields of two receipts match.
+func receiptsEqual(a, b *types.Receipt) bool {
+	encA, _ := rlp.EncodeToBytes(a)
+	encB, _ := rlp.EncodeToBytes(b)
+	return bytes.Equal(encA, encB)
+}
+
+// diffStates returns the accounts differing between two state tries.
+func diffStates(database state.Database, oldRoot, newRoot common.Hash) ([]*AccountDiff, error) {
+	oldTrie, err := database.OpenTrie(oldRoot)
+	if err != nil {
+		return nil, err
+	}
+	newTrie, err := database.OpenTrie(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make(map[common.Hash]*AccountDiff)
+	collect := func(a, b state.Trie, old bool) error {
+		it, _ := trie.NewDifferenceIterator(a.NodeIterator(nil), b.NodeIterator(nil))
+		iter := trie.NewIterator(it)
+		for iter.Next() {
+			account := new(state.Account)
+			if err := rlp.DecodeBytes(iter.Value, account); err != nil {
+				return err
+			}
+			hash := common.BytesToHash(iter.Key)
+			diff := diffs[hash]
+			if diff == nil {
+				diff = &AccountDiff{Hash: hash}
+				if preimage := b.GetKey(iter.Key); preimage != nil {
+					addr := common.BytesToAddress(preimage)
+					diff.Address = &addr
+				}
+				diffs[hash] = diff
+			}
+			if old {
+				diff.Old = account
+			} else {
+				diff.New = account
+			}
+		}
+		return iter.Err
+	}
+	if err := collect(oldTrie, newTrie, false); err != nil {
+		return nil, err
+	}
+	if err := collect(newTrie, oldTrie, true); err != nil {
+		return nil, err
+	}
+	accounts := make([]*AccountDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		// Unchanged accounts may still show up if the trie shape changed around them
+		if diff.Old != nil && diff.New != nil {
+			oldBlob, _ := rlp.EncodeToBytes(diff.Old)
+			newBlob, _ := rlp.EncodeToBytes(diff.New)
+			if bytes.Equal(oldBlob, newBlob) {
+				continue
+			}
+		}
+		accounts = append(accounts, diff)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return bytes.Compare(accounts[i].Hash[:], accounts[j].Hash[:]) < 0
+	})
+	return accounts, nil
+}
@@ -0,0 +1,156 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// Tests that re-executing a block range reproduces the canonical chain with the
+// original configuration, and pinpoints the divergence without the irregular
+// state changes it was processed with.
+func TestVerifyChainRange(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		drained  = common.Address{0x01}
+		restored = common.Address{0x02}
+		signer   = types.HomesteadSigner{}
+	)
+	config := *params.TestChainConfig
+	config.IrregularStateChanges = []*params.IrregularStateChange{{
+		Block: big.NewInt(3),
+		Moves: []params.IrregularBalance{{From: drained, To: restored}},
+	}}
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			sender:  {Balance: big.NewInt(1000000000)},
+			drained: {Balance: big.NewInt(1000)},
+		},
+	}
+	db := yocdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(&config, genesis, yochash.NewFaker(), db, 5, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(sender), common.Address{0xff}, big.NewInt(1), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	chain, _ := NewBlockChain(db, nil, &config, yochash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	// Re-executing with the original configuration must reproduce every block
+	if div, err := chain.VerifyChainRange(&config, 1, 5); err != nil || div != nil {
+		t.Fatalf("original config diverged: %+v, err %v", div, err)
+	}
+	// Re-executing without the irregular change must diverge right at it
+	plain := config
+	plain.IrregularStateChanges = nil
+
+	div, err := chain.VerifyChainRange(&plain, 1, 5)
+	if err != nil {
+		t.Fatalf("failed to verify range: %v", err)
+	}
+	if div == nil {
+		t.Fatalf("missing divergence")
+	}
+	if div.Number != 3 || div.Hash != blocks[2].Hash() {
+		t.Errorf("divergent block mismatch: have %d/%x, want %d/%x", div.Number, div.Hash, 3, blocks[2].Hash())
+	}
+	if div.Err != nil || div.NewRoot == div.Root {
+		t.Errorf("unexpected re-execution result: root %x, err %v", div.NewRoot, div.Err)
+	}
+	if div.DiffErr != nil {
+		t.Fatalf("failed to diff states: %v", div.DiffErr)
+	}
+	// Value transfers are unaffected by the balance move, the receipt matches too
+	if div.TxIndex != -1 {
+		t.Errorf("unexpected divergent transaction #%d", div.TxIndex)
+	}
+	// Emptied accounts are deleted (EIP-158), -1 marks a missing account
+	want := map[common.Address][2]int64{
+		drained:  {-1, 1000},
+		restored: {1000, -1},
+	}
+	if len(div.Accounts) != len(want) {
+		t.Fatalf("diff length mismatch: have %d, want %d", len(div.Accounts), len(want))
+	}
+	for _, diff := range div.Accounts {
+		if diff.Address == nil {
+			t.Fatalf("missing address preimage for %x", diff.Hash)
+		}
+		balances, ok := want[*diff.Address]
+		if !ok {
+			t.Errorf("unexpected account in diff: %x", *diff.Address)
+			continue
+		}
+		for i, account := range []*state.Account{diff.Old, diff.New} {
+			switch {
+			case balances[i] < 0 && account != nil:
+				t.Errorf("account %x side %d should not exist: have %+v", *diff.Address, i, account)
+			case balances[i] >= 0 && (account == nil || account.Balance.Int64() != balances[i]):
+				t.Errorf("account %x side %d mismatch: have %+v, want balance %d", *diff.Address, i, account, balances[i])
+			}
+		}
+	}
+}
+
+// Tests that the block rewards of the re-executed blocks follow the verified
+// configuration instead of the one the chain was opened with.
+func TestVerifyChainRangeRewards(t *testing.T) {
+	special := common.Address{0xaa}
+
+	config := *params.TestChainConfig
+	config.Yochash = &params.YochashConfig{RewardSchedule: []*params.RewardPeriod{
+		{Block: big.NewInt(0), Reward: big.NewInt(3200), Beneficiaries: []*params.RewardBeneficiary{{Coinbase: special, Reward: big.NewInt(10000)}}},
+	}}
+	gspec := &Genesis{Config: &config}
+
+	db := yocdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(&config, genesis, yochash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		if i == 1 {
+			gen.SetCoinbase(special)
+		}
+	})
+	chain, _ := NewBlockChain(db, nil, &config, yochash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	if div, err := chain.VerifyChainRange(&config, 1, 3); err != nil || div != nil {
+		t.Fatalf("original config diverged: %+v, err %v", div, err)
+	}
+	// Paying the beneficiary the regular reward must diverge at its block
+	plain := config
+	plain.Yochash = &params.YochashConfig{RewardSchedule: []*params.RewardPeriod{
+		{Block: big.NewInt(0), Reward: big.NewInt(3200)},
+	}}
+	div, err := chain.VerifyChainRange(&plain, 1, 3)
+	if err != nil {
+		t.Fatalf("failed to verify range: %v", err)
+	}
+	if div == nil || div.Number != 2 {
+		t.Fatalf("divergence mismatch: have %+v, want block 2", div)
+	}
+	if len(div.Accounts) != 1 || div.Accounts[0].Address == nil || *div.Accounts[0].Address != special {
+		t.Fatalf("diff mismatch: have %+v, want only the beneficiary", div.Accounts)
+	}
+	if canonical, reexecuted := div.Accounts[0].Old.Balance.Int64(), div.Accounts[0].New.Balance.Int64(); canonical != 10000 || reexecuted != 3200 {
+		t.Errorf("beneficiary balance mismatch: have %d -> %d, want 10000 -> 3200", canonical, reexecuted)
+	}
+}
@@ -23,7 +23,7 @@ type (
 	// CanTransferFunc is the signature of a transfer guard function
 	CanTransferFunc func(StateDB, common.Address, *big.Int) bool
 	// TransferFunc is the signature of a transfer function
-	TransferFunc func(StateDB, common.Address, common.Address, *big.Int /* height */, *big.Int)
+	TransferFunc func(StateDB, common.Address, common.Address, *big.Int)
 	// GetHashFunc returns the nth block hash in the blockchain
 	// and is used by the BLOCKHASH YVM op code.
 	GetHashFunc func(uint64) common.Hash
@@ -183,7 +183,7 @@ func (yvm *YVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		}
 		yvm.StateDB.CreateAccount(addr)
 	}
-	yvm.Transfer(yvm.StateDB, caller.Address(), to.Address(), value, yvm.BlockNumber)
+	yvm.Transfer(yvm.StateDB, caller.Address(), to.Address(), value)
 
 	// Initialise a new contract and set the code that is to be used by the YVM.
 	// The contract is a scoped environment for this execution context only.
@@ -357,7 +357,7 @@ func (yvm *YVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 	if yvm.ChainConfig().IsEIP158(yvm.BlockNumber) {
 		yvm.StateDB.SetNonce(address, 1)
 	}
-	yvm.Transfer(yvm.StateDB, caller.Address(), address, value, yvm.BlockNumber)
+	yvm.Transfer(yvm.StateDB, caller.Address(), address, value)
 
 	// initialise a new contract and set the code that is to be used by the
 	// YVM. The contract is a scoped environment for this execution context
@@ -843,7 +843,7 @@ func opStop(pc *uint64, interpreter *YVMInterpreter, contract *Contract, memory
 
 func opSuicide(pc *uint64, interpreter *YVMInterpreter, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
 	balance := interpreter.yvm.StateDB.GetBalance(contract.Address())
-	interpreter.yvm.StateDB.AddBalance(common.BigToAddress(stack.pop()), balance, interpreter.yvm.BlockNumber)
+	interpreter.yvm.StateDB.AddBalance(common.BigToAddress(stack.pop()), balance)
 
 	interpreter.yvm.StateDB.Suicide(contract.Address())
 	return nil, nil
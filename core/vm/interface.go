@@ -14,8 +14,8 @@ import (
 type StateDB interface {
 	CreateAccount(common.Address)
 
-	SubBalance(common.Address, *big.Int, *big.Int)
-	AddBalance(common.Address, *big.Int, *big.Int)
+	SubBalance(common.Address, *big.Int)
+	AddBalance(common.Address, *big.Int)
 	GetBalance(common.Address) *big.Int
 
 	GetNonce(common.Address) uint64
@@ -0,0 +1,402 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/bloombits"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/internal/yocapi"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// Tests that the schema is consistent with the resolvers backing it.
+func TestBuildSchema(t *testing.T) {
+	if _, err := newHandler(nil); err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+}
+
+// Tests that malformed queries are rejected with GraphQL errors instead of
+// reaching the backend.
+func TestMalformedQuery(t *testing.T) {
+	handler, err := newHandler(nil)
+	if err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"query": "{ block { unknownField } }"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: have %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `"errors"`) {
+		t.Errorf("malformed query accepted: %s", rec.Body.String())
+	}
+}
+
+// testBackend is a minimal backend serving the resolvers from a chain of blocks
+// generated in memory. Calling any method not overridden below panics.
+type testBackend struct {
+	yocapi.Backend
+
+	db    yocdb.Database
+	chain *core.BlockChain
+}
+
+func (b *testBackend) ChainDb() yocdb.Database                                              { return b.db }
+func (b *testBackend) ChainConfig() *params.ChainConfig                                     { return b.chain.Config() }
+func (b *testBackend) CurrentBlock() *types.Block                                           { return b.chain.CurrentBlock() }
+func (b *testBackend) GetPoolTransaction(hash common.Hash) *types.Transaction               { return nil }
+func (b *testBackend) BloomStatus() (uint64, uint64)                                        { return params.BloomBitsBlocks, 0 }
+func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock().Header(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.chain.GetReceiptsByHash(hash), nil
+}
+
+func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
+	receipts := b.chain.GetReceiptsByHash(hash)
+	logs := make([][]*types.Log, len(receipts))
+	for i, receipt := range receipts {
+		logs[i] = receipt.Logs
+	}
+	return logs, nil
+}
+
+func (b *testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	var header *types.Header
+	if number, ok := blockNrOrHash.Number(); ok {
+		header, _ = b.HeaderByNumber(ctx, number)
+	} else if hash, ok := blockNrOrHash.Hash(); ok {
+		header, _ = b.HeaderByHash(ctx, hash)
+	}
+	if header == nil {
+		return nil, nil, errors.New("header not found")
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testLogger  = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testRecv    = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	testTopic   = common.HexToHash("0x01")
+	testSlot    = common.HexToHash("0x05")
+	testSlotVal = common.HexToHash("0x0a")
+)
+
+// newTestBackend creates a backend over a chain of two blocks: the first one
+// transfers funds to testRecv, the second calls the testLogger contract which
+// emits a single log with testTopic.
+func newTestBackend(t *testing.T) (*testBackend, []*types.Block) {
+	var (
+		db     = yocdb.NewMemDatabase()
+		engine = yochash.NewFaker()
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				testAddr: {Balance: big.NewInt(params.YOC)},
+				testLogger: {
+					// PUSH1 0x01 PUSH1 0x00 PUSH1 0x00 LOG1 STOP
+					Balance: new(big.Int),
+					Code:    common.FromHex("0x600160006000a100"),
+					Storage: map[common.Hash]common.Hash{testSlot: testSlotVal},
+				},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.HomesteadSigner{}
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, 2, func(i int, b *core.BlockGen) {
+		var tx *types.Transaction
+		switch i {
+		case 0:
+			tx = types.NewTransaction(b.TxNonce(testAddr), testRecv, big.NewInt(1000), params.TxGas, big.NewInt(1), nil)
+		case 1:
+			tx = types.NewTransaction(b.TxNonce(testAddr), testLogger, big.NewInt(0), 100000, big.NewInt(1), nil)
+		}
+		tx, err := types.SignTx(tx, signer, testKey)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		b.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	return &testBackend{db: db, chain: chain}, blocks
+}
+
+// query runs a GraphQL query against the handler and decodes its data into
+// result, failing the test on any error reported.
+func query(t *testing.T, handler http.Handler, q string, result interface{}) {
+	body, _ := json.Marshal(map[string]string{"query": q})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+
+	var res struct {
+		Data   json.RawMessage
+		Errors []interface{}
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("failed to decode response %s: %v", rec.Body.String(), err)
+	}
+	if len(res.Errors) > 0 {
+		t.Fatalf("query %q failed: %v", q, res.Errors)
+	}
+	if err := json.Unmarshal(res.Data, result); err != nil {
+		t.Fatalf("failed to decode data %s: %v", res.Data, err)
+	}
+}
+
+// Tests that blocks are resolved both by number and by hash.
+func TestQueryBlock(t *testing.T) {
+	backend, blocks := newTestBackend(t)
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+	type block struct {
+		Number           hexutil.Uint64
+		Hash             common.Hash
+		TransactionCount int
+	}
+	tests := []struct {
+		query string
+		want  *types.Block
+	}{
+		{`{ block(number: 1) { number hash transactionCount } }`, blocks[0]},
+		{fmt.Sprintf(`{ block(hash: "%s") { number hash transactionCount } }`, blocks[1].Hash().Hex()), blocks[1]},
+		{`{ block { number hash transactionCount } }`, blocks[1]},
+		{`{ block(number: 3) { number hash transactionCount } }`, nil},
+	}
+	for i, tt := range tests {
+		var res struct{ Block *block }
+		query(t, handler, tt.query, &res)
+
+		if tt.want == nil {
+			if res.Block != nil {
+				t.Errorf("test %d: unexpected block %+v", i, res.Block)
+			}
+			continue
+		}
+		if res.Block == nil {
+			t.Errorf("test %d: block not found", i)
+			continue
+		}
+		if uint64(res.Block.Number) != tt.want.NumberU64() || res.Block.Hash != tt.want.Hash() || res.Block.TransactionCount != len(tt.want.Transactions()) {
+			t.Errorf("test %d: block mismatch: have %+v, want number %d hash %x txs %d", i, res.Block, tt.want.NumberU64(), tt.want.Hash(), len(tt.want.Transactions()))
+		}
+	}
+}
+
+// Tests that account balances, nonces and storage are resolved at the state of
+// the requested block.
+func TestQueryAccount(t *testing.T) {
+	backend, _ := newTestBackend(t)
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+	type account struct {
+		Balance          hexutil.Big
+		TransactionCount hexutil.Uint64
+		Storage          common.Hash
+	}
+	tests := []struct {
+		number  uint64
+		address common.Address
+		balance int64
+		nonce   uint64
+		storage common.Hash
+	}{
+		{0, testRecv, 0, 0, common.Hash{}},
+		{1, testRecv, 1000, 0, common.Hash{}},
+		{0, testAddr, params.YOC, 0, common.Hash{}},
+		{2, testAddr, -1, 2, common.Hash{}},
+		{2, testLogger, 0, 0, testSlotVal},
+	}
+	for i, tt := range tests {
+		q := fmt.Sprintf(`{ block(number: %d) { account(address: "%s") { balance transactionCount storage(slot: "%s") } } }`, tt.number, tt.address.Hex(), testSlot.Hex())
+
+		var res struct{ Block struct{ Account account } }
+		query(t, handler, q, &res)
+
+		if tt.balance >= 0 && res.Block.Account.Balance.ToInt().Cmp(big.NewInt(tt.balance)) != 0 {
+			t.Errorf("test %d: balance mismatch: have %v, want %d", i, res.Block.Account.Balance.ToInt(), tt.balance)
+		}
+		if uint64(res.Block.Account.TransactionCount) != tt.nonce {
+			t.Errorf("test %d: nonce mismatch: have %d, want %d", i, res.Block.Account.TransactionCount, tt.nonce)
+		}
+		if res.Block.Account.Storage != tt.storage {
+			t.Errorf("test %d: storage mismatch: have %x, want %x", i, res.Block.Account.Storage, tt.storage)
+		}
+	}
+}
+
+// Tests that transactions are resolved by hash along with their receipts.
+func TestQueryTransaction(t *testing.T) {
+	backend, blocks := newTestBackend(t)
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+	tx := blocks[1].Transactions()[0]
+	receipt := backend.chain.GetReceiptsByHash(blocks[1].Hash())[0]
+
+	var res struct {
+		Transaction *struct {
+			Hash    common.Hash
+			Nonce   hexutil.Uint64
+			From    struct{ Address common.Address }
+			To      struct{ Address common.Address }
+			Index   int
+			Status  hexutil.Uint64
+			GasUsed hexutil.Uint64
+			Block   struct{ Number hexutil.Uint64 }
+			Logs    []struct{ Topics []common.Hash }
+		}
+	}
+	q := fmt.Sprintf(`{ transaction(hash: "%s") { hash nonce from { address } to { address } index status gasUsed block { number } logs { topics } } }`, tx.Hash().Hex())
+	query(t, handler, q, &res)
+
+	have := res.Transaction
+	switch {
+	case have == nil:
+		t.Fatalf("transaction not found")
+	case have.Hash != tx.Hash() || uint64(have.Nonce) != tx.Nonce():
+		t.Errorf("transaction mismatch: have hash %x nonce %d, want hash %x nonce %d", have.Hash, have.Nonce, tx.Hash(), tx.Nonce())
+	case have.From.Address != testAddr || have.To.Address != testLogger:
+		t.Errorf("sender/recipient mismatch: have %x/%x, want %x/%x", have.From.Address, have.To.Address, testAddr, testLogger)
+	case have.Index != 0 || uint64(have.Block.Number) != blocks[1].NumberU64():
+		t.Errorf("position mismatch: have index %d block %d, want index 0 block %d", have.Index, have.Block.Number, blocks[1].NumberU64())
+	case uint64(have.Status) != types.ReceiptStatusSuccessful || uint64(have.GasUsed) != receipt.GasUsed:
+		t.Errorf("receipt mismatch: have status %d gas %d, want status %d gas %d", have.Status, have.GasUsed, types.ReceiptStatusSuccessful, receipt.GasUsed)
+	case len(have.Logs) != 1 || len(have.Logs[0].Topics) != 1 || have.Logs[0].Topics[0] != testTopic:
+		t.Errorf("logs mismatch: have %+v, want a single log with topic %x", have.Logs, testTopic)
+	}
+	// Unknown transactions should resolve to null
+	var missing struct{ Transaction *struct{ Hash common.Hash } }
+	query(t, handler, fmt.Sprintf(`{ transaction(hash: "%s") { hash } }`, common.Hash{}.Hex()), &missing)
+	if missing.Transaction != nil {
+		t.Errorf("unknown transaction resolved: %+v", missing.Transaction)
+	}
+}
+
+// Tests that logs are filtered by block range, address and topics.
+func TestQueryLogs(t *testing.T) {
+	backend, blocks := newTestBackend(t)
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+	tests := []struct {
+		filter string
+		want   int
+	}{
+		{`{fromBlock: 0, toBlock: 2}`, 1},
+		{`{fromBlock: 0, toBlock: 1}`, 0},
+		{fmt.Sprintf(`{fromBlock: 0, addresses: ["%s"]}`, testLogger.Hex()), 1},
+		{fmt.Sprintf(`{fromBlock: 0, addresses: ["%s"]}`, testRecv.Hex()), 0},
+		{fmt.Sprintf(`{fromBlock: 0, topics: [["%s"]]}`, testTopic.Hex()), 1},
+		{fmt.Sprintf(`{fromBlock: 0, topics: [["%s"]]}`, common.Hash{}.Hex()), 0},
+	}
+	for i, tt := range tests {
+		var res struct {
+			Logs []struct {
+				Account     struct{ Address common.Address }
+				Topics      []common.Hash
+				Transaction struct{ Hash common.Hash }
+			}
+		}
+		query(t, handler, fmt.Sprintf(`{ logs(filter: %s) { account { address } topics transaction { hash } } }`, tt.filter), &res)
+
+		if len(res.Logs) != tt.want {
+			t.Errorf("test %d: log count mismatch: have %d, want %d", i, len(res.Logs), tt.want)
+			continue
+		}
+		for _, log := range res.Logs {
+			if log.Account.Address != testLogger || log.Transaction.Hash != blocks[1].Transactions()[0].Hash() {
+				t.Errorf("test %d: log mismatch: have %+v", i, log)
+			}
+		}
+	}
+}
+
+// Tests that queries spanning block ranges over the limits are rejected.
+func TestQueryRangeLimits(t *testing.T) {
+	backend, _ := newTestBackend(t)
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("could not create GraphQL handler: %v", err)
+	}
+	tests := []struct {
+		query  string
+		failed bool
+	}{
+		{fmt.Sprintf(`{ blocks(from: 0, to: %d) { number } }`, maxBlocksRange-1), false},
+		{fmt.Sprintf(`{ blocks(from: 0, to: %d) { number } }`, maxBlocksRange), true},
+		{fmt.Sprintf(`{ logs(filter: {fromBlock: 0, toBlock: %d}) { index } }`, maxLogsRange-1), false},
+		{fmt.Sprintf(`{ logs(filter: {fromBlock: 0, toBlock: %d}) { index } }`, maxLogsRange), true},
+		{`{ logs(filter: {}) { index } }`, false},
+	}
+	for i, tt := range tests {
+		body, _ := json.Marshal(map[string]string{"query": tt.query})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+
+		if failed := strings.Contains(rec.Body.String(), "exceeds the limit"); failed != tt.failed {
+			t.Errorf("test %d: rejection mismatch: have %v, want %v: %s", i, failed, tt.failed, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package graphql
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Yocoin15/Yocoin_Sources/internal/yocapi"
+	"github.com/Yocoin15/Yocoin_Sources/les"
+	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/node"
+	"github.com/Yocoin15/Yocoin_Sources/p2p"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+	"github.com/Yocoin15/Yocoin_Sources/yoc"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// Service encapsulates a GraphQL service. Unlike the HTTP RPC endpoint, it is
+// neither authenticated nor rate limited: queries are only bounded by the caps
+// on the block ranges they span and the HTTP timeouts.
+type Service struct {
+	endpoint string           // The host:port endpoint for this service.
+	cors     []string         // Allowed CORS domains
+	vhosts   []string         // Recognised vhosts
+	timeouts rpc.HTTPTimeouts // Timeout settings for HTTP requests.
+	backend  yocapi.Backend   // The backend that queries will operate on.
+	handler  http.Handler     // The `http.Handler` used to answer queries.
+	listener net.Listener     // The listening socket.
+}
+
+// New constructs a new GraphQL service instance.
+func New(backend yocapi.Backend, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) (*Service, error) {
+	return &Service{
+		endpoint: endpoint,
+		cors:     cors,
+		vhosts:   vhosts,
+		timeouts: timeouts,
+		backend:  backend,
+	}, nil
+}
+
+// Protocols returns the list of protocols exported by this service.
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs returns the list of APIs exported by this service.
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start is called after all services have been constructed and the networking
+// layer was also initialized to spawn any goroutines required by the service.
+func (s *Service) Start(server *p2p.Server) error {
+	var err error
+	s.handler, err = newHandler(s.backend)
+	if err != nil {
+		return err
+	}
+	if s.listener, err = net.Listen("tcp", s.endpoint); err != nil {
+		return err
+	}
+	go rpc.NewHTTPServer(s.cors, s.vhosts, s.timeouts, s.handler).Serve(s.listener)
+	log.Info("GraphQL endpoint opened", "url", fmt.Sprintf("http://%s", s.endpoint), "cors", strings.Join(s.cors, ","), "vhosts", strings.Join(s.vhosts, ","))
+	return nil
+}
+
+// Stop terminates all goroutines belonging to the service, blocking until they
+// are all terminated.
+func (s *Service) Stop() error {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+		log.Info("GraphQL endpoint closed", "url", fmt.Sprintf("http://%s", s.endpoint))
+	}
+	return nil
+}
+
+// newHandler parses the schema against the resolvers backed by the given
+// backend and returns an HTTP handler answering queries over it.
+func newHandler(backend yocapi.Backend) (http.Handler, error) {
+	q := Resolver{backend}
+
+	s, err := graphqlgo.ParseSchema(schema, &q)
+	if err != nil {
+		return nil, err
+	}
+	return &relay.Handler{Schema: s}, nil
+}
+
+// RegisterGraphQLService is a utility function to construct a new service and
+// register it against a node.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) error {
+	return stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		var yocServ *yoc.YoCoin
+		if err := ctx.Service(&yocServ); err == nil {
+			return New(yocServ.APIBackend, endpoint, cors, vhosts, timeouts)
+		}
+		var lesServ *les.LightYoCoin
+		if err := ctx.Service(&lesServ); err == nil {
+			return New(lesServ.ApiBackend, endpoint, cors, vhosts, timeouts)
+		}
+		return nil, fmt.Errorf("no YoCoin service to back GraphQL")
+	})
+}
@@ -165,6 +165,21 @@ type GasPricer interface {
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 }
 
+// GasPrices contains slow, normal and fast gas price suggestions.
+type GasPrices struct {
+	Slow   *big.Int
+	Normal *big.Int
+	Fast   *big.Int
+}
+
+// FeeHistory provides recent fee market data that consumers can use to determine
+// a reasonable gas price.
+type FeeHistory struct {
+	OldestBlock  *big.Int     // block corresponding to first response value
+	Reward       [][]*big.Int // gas prices paid at the requested percentiles of each block
+	GasUsedRatio []float64    // gas used divided by gas limit of each block
+}
+
 // A PendingStateReader provides access to the pending state, which is the result of all
 // known executable transactions which have not yet been included in the blockchain. It is
 // commonly used to display the result of ’unconfirmed’ actions (e.g. wallet value
@@ -0,0 +1,257 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yocapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Yocoin15/Yocoin_Sources/accounts/abi"
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+	"github.com/Yocoin15/Yocoin_Sources/common/math"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+)
+
+// revertSelector is the method selector of the Error(string) revert reasons
+// emitted by Solidity.
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// BlockOverrides are the fields of the block context a call bundle is executed
+// in which may be overridden.
+type BlockOverrides struct {
+	Number   *hexutil.Big    `json:"number"`
+	Time     *hexutil.Big    `json:"time"`
+	Coinbase *common.Address `json:"coinbase"`
+}
+
+// Apply returns a copy of the header with the overrides applied.
+func (diff *BlockOverrides) Apply(header *types.Header) *types.Header {
+	header = types.CopyHeader(header)
+	if diff == nil {
+		return header
+	}
+	if diff.Number != nil {
+		header.Number = new(big.Int).Set(diff.Number.ToInt())
+	}
+	if diff.Time != nil {
+		header.Time = new(big.Int).Set(diff.Time.ToInt())
+	}
+	if diff.Coinbase != nil {
+		header.Coinbase = *diff.Coinbase
+	}
+	return header
+}
+
+// BundleCallResult is the outcome of a single call of a bundle.
+type BundleCallResult struct {
+	ReturnValue  hexutil.Bytes  `json:"returnValue"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Logs         []*types.Log   `json:"logs"`
+	Error        string         `json:"error,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+}
+
+// bundleBlockTime is the number of seconds the timestamp of the blocks of a
+// multi-block bundle advance by, unless overridden.
+const bundleBlockTime = 13
+
+// maxBundleBlocks is the maximum number of blocks a bundle may simulate.
+const maxBundleBlocks = 256
+
+// BundleBlock is a block of a multi-block bundle: the calls to execute in it
+// and the overrides of its block context.
+type BundleBlock struct {
+	Calls          []CallArgs      `json:"calls"`
+	BlockOverrides *BlockOverrides `json:"blockOverrides"`
+}
+
+// DoCallBundle executes the calls of the given blocks one after the other on a
+// single copy of the state of the given block, each call seeing the changes made
+// by the previous ones. The first block runs in the context of the given block,
+// every following one on top of its predecessor, with the number increased by
+// one and the time by bundleBlockTime before applying its overrides. Calls
+// failing do not abort the bundle, their outcome is reported in their result
+// instead.
+func DoCallBundle(ctx context.Context, b Backend, blocks []BundleBlock, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, vmCfg vm.Config, timeout time.Duration) ([][]*BundleCallResult, error) {
+	defer func(start time.Time) { log.Debug("Executing YVM call bundle finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, err
+	}
+	// Setup context so it may be cancelled when the bundle has completed or
+	// ran out of time.
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		parent  *types.Header
+		calls   int // Number of calls executed so far, identifying their logs
+		results = make([][]*BundleCallResult, len(blocks))
+	)
+	for i, block := range blocks {
+		if parent != nil {
+			header = types.CopyHeader(parent)
+			header.ParentHash = parent.Hash()
+			header.Number.Add(header.Number, common.Big1)
+			header.Time.Add(header.Time, big.NewInt(bundleBlockTime))
+		}
+		header = block.BlockOverrides.Apply(header)
+		if parent != nil && header.Number.Cmp(parent.Number) <= 0 {
+			return nil, fmt.Errorf("bundle block %d: number %v not above parent %v", i, header.Number, parent.Number)
+		}
+		if results[i], err = applyBundleBlock(ctx, b, state, header, block.Calls, calls, vmCfg); err != nil {
+			return nil, err
+		}
+		calls += len(block.Calls)
+		parent = header
+	}
+	return results, nil
+}
+
+// applyBundleBlock executes the calls of a single bundle block on the given
+// state, in the block context of the given header.
+func applyBundleBlock(ctx context.Context, b Backend, state *state.StateDB, header *types.Header, calls []CallArgs, offset int, vmCfg vm.Config) ([]*BundleCallResult, error) {
+	var (
+		gp        = new(core.GasPool).AddGas(math.MaxUint64)
+		blockHash = header.Hash()
+		results   = make([]*BundleCallResult, len(calls))
+	)
+	for i, args := range calls {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Unlike single calls, the sender pays for the gas with its actual
+		// balance, so default to the block gas limit and a free gas price.
+		msg := args.ToMessage(b.AccountManager())
+		gas := uint64(args.Gas)
+		if gas == 0 {
+			gas = header.GasLimit
+		}
+		msg = types.NewMessage(msg.From(), msg.To(), 0, msg.Value(), gas, args.GasPrice.ToInt(), msg.Data(), false)
+
+		// The backends fund the sender of calls, undo it to keep the balances
+		// consistent across the bundle.
+		balance := state.GetBalance(msg.From())
+		yvm, vmError, err := b.GetYVM(ctx, msg, state, header, vmCfg)
+		if err != nil {
+			return nil, err
+		}
+		state.SetBalance(msg.From(), balance)
+
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				yvm.Cancel()
+			case <-done:
+			}
+		}()
+		// Use the index of the call within the bundle as the hash of the logs,
+		// the calls having none
+		txHash := common.BigToHash(big.NewInt(int64(offset + i)))
+		state.Prepare(txHash, blockHash, i)
+
+		res, gasUsed, failed, err := core.ApplyMessage(yvm, msg, gp)
+		close(done)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, errors.New("execution aborted (timeout)")
+		}
+		state.Finalise(true)
+
+		result := &BundleCallResult{
+			ReturnValue: res,
+			GasUsed:     hexutil.Uint64(gasUsed),
+			Logs:        state.GetLogs(txHash),
+		}
+		if result.Logs == nil {
+			result.Logs = []*types.Log{}
+		}
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case failed:
+			result.Error = "execution failed"
+			if reason, ok := unpackRevert(res); ok {
+				result.Error = "execution reverted"
+				result.RevertReason = reason
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// unpackRevert decodes the Error(string) revert reason of the given return
+// data, if any.
+func unpackRevert(data []byte) (string, bool) {
+	if len(data) < 4 || !bytes.Equal(data[:4], revertSelector) {
+		return "", false
+	}
+	typ, _ := abi.NewType("string")
+	var reason string
+	if err := (abi.Arguments{{Type: typ}}).Unpack(&reason, data[4:]); err != nil {
+		return "", false
+	}
+	return reason, true
+}
+
+// CallBundle executes the given calls in order on the state of the given block,
+// with each call operating on the state left behind by the previous ones. The
+// state and the block context the bundle runs in can be modified with the
+// overrides. It returns the outcome of every call.
+//
+// Note, this function doesn't make any changes in the state/blockchain and is
+// useful to simulate dependent transactions, e.g. an approval and a transfer.
+func (s *PublicBlockChainAPI) CallBundle(ctx context.Context, calls []CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides) ([]*BundleCallResult, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("empty call bundle")
+	}
+	blocks := []BundleBlock{{Calls: calls, BlockOverrides: blockOverrides}}
+	results, err := DoCallBundle(ctx, s.b, blocks, blockNrOrHash, overrides, vm.Config{}, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// SimulateBlocks executes the calls of the given blocks in order on top of the
+// state of the given block, the first block running in its context and every
+// following one succeeding the previous, with the state carried over between
+// them. It returns the outcome of every call, grouped by block.
+//
+// Note, this function doesn't make any changes in the state/blockchain and is
+// useful to simulate transactions depending on the block context, e.g. ones
+// unlocked by a time lock.
+func (s *PublicBlockChainAPI) SimulateBlocks(ctx context.Context, blocks []BundleBlock, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride) ([][]*BundleCallResult, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("empty call bundle")
+	}
+	if len(blocks) > maxBundleBlocks {
+		return nil, fmt.Errorf("too many bundle blocks: %d > %d", len(blocks), maxBundleBlocks)
+	}
+	return DoCallBundle(ctx, s.b, blocks, blockNrOrHash, overrides, vm.Config{}, 5*time.Second)
+}
@@ -0,0 +1,289 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yocapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/accounts"
+	"github.com/Yocoin15/Yocoin_Sources/accounts/abi"
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+	"github.com/Yocoin15/Yocoin_Sources/common/math"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// testBackend is a minimal backend executing calls on a chain generated in
+// memory. Calling any method not overridden below panics.
+type testBackend struct {
+	Backend
+
+	chain *core.BlockChain
+}
+
+func (b *testBackend) AccountManager() *accounts.Manager { return nil }
+
+func (b *testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	var header *types.Header
+	if number, ok := blockNrOrHash.Number(); ok {
+		if number == rpc.LatestBlockNumber {
+			header = b.chain.CurrentHeader()
+		} else {
+			header = b.chain.GetHeaderByNumber(uint64(number))
+		}
+	} else if hash, ok := blockNrOrHash.Hash(); ok {
+		header = b.chain.GetHeaderByHash(hash)
+	}
+	if header == nil {
+		return nil, nil, errors.New("header not found")
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+func (b *testBackend) GetYVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.YVM, func() error, error) {
+	state.SetBalance(msg.From(), math.MaxBig256)
+	context := core.NewYVMContext(msg, header, b.chain, nil)
+	return vm.NewYVM(context, state, b.chain.Config(), vmCfg), func() error { return nil }, nil
+}
+
+var (
+	testAddr     = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testRecv     = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	testCoinbase = common.HexToAddress("0x3000000000000000000000000000000000000003")
+	testCounter  = common.HexToAddress("0x4000000000000000000000000000000000000004")
+	testBalance  = common.HexToAddress("0x5000000000000000000000000000000000000005")
+	testBlock    = common.HexToAddress("0x6000000000000000000000000000000000000006")
+	testReverter = common.HexToAddress("0x7000000000000000000000000000000000000007")
+)
+
+// revertCode returns the code of a contract reverting with the given data.
+func revertCode(data []byte) []byte {
+	var code []byte
+	for i := 0; i < len(data); i += 32 {
+		word := make([]byte, 32)
+		copy(word, data[i:])
+
+		code = append(code, byte(vm.PUSH32))
+		code = append(code, word...)
+		code = append(code, byte(vm.PUSH1), byte(i), byte(vm.MSTORE))
+	}
+	return append(code, byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 0, byte(vm.REVERT))
+}
+
+// newTestBackend creates a backend over a chain of two empty blocks, with the
+// genesis funding testAddr and deploying the contracts probed by the tests:
+//
+//   - testCounter increments storage slot 0, logs and returns the new value
+//   - testBalance returns the balance of the address passed as call data
+//   - testBlock returns the number and the timestamp of the block
+//   - testReverter reverts with the reason "nope"
+func newTestBackend(t *testing.T) *testBackend {
+	typ, _ := abi.NewType("string")
+	reason, err := abi.Arguments{{Type: typ}}.Pack("nope")
+	if err != nil {
+		t.Fatalf("failed to pack revert reason: %v", err)
+	}
+	var (
+		db     = yocdb.NewMemDatabase()
+		engine = yochash.NewFaker()
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				testAddr: {Balance: big.NewInt(params.YOC)},
+				testCounter: {
+					// SLOAD(0)+1, SSTORE to 0, LOG0 and RETURN it
+					Balance: new(big.Int),
+					Code:    common.FromHex("0x6000546001018060005560005260206000a060206000f3"),
+				},
+				testBalance: {
+					// RETURN BALANCE(CALLDATALOAD(0))
+					Balance: new(big.Int),
+					Code:    common.FromHex("0x6000353160005260206000f3"),
+				},
+				testBlock: {
+					// RETURN NUMBER, TIMESTAMP
+					Balance: new(big.Int),
+					Code:    common.FromHex("0x436000524260205260406000f3"),
+				},
+				testReverter: {
+					Balance: new(big.Int),
+					Code:    revertCode(append(append([]byte{}, revertSelector...), reason...)),
+				},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, 2, nil)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	return &testBackend{chain: chain}
+}
+
+var latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+// callBundle executes the given blocks of calls, failing the test on error.
+func callBundle(t *testing.T, b Backend, blocks ...BundleBlock) [][]*BundleCallResult {
+	results, err := DoCallBundle(context.Background(), b, blocks, latest, nil, vm.Config{}, 0)
+	if err != nil {
+		t.Fatalf("failed to execute bundle: %v", err)
+	}
+	return results
+}
+
+// word decodes the idx-th 32 byte word of the return value of a call.
+func word(t *testing.T, res *BundleCallResult, idx int) *big.Int {
+	if res.Error != "" {
+		t.Fatalf("call failed: %s", res.Error)
+	}
+	if len(res.ReturnValue) < 32*(idx+1) {
+		t.Fatalf("return value too short: %x", res.ReturnValue)
+	}
+	return new(big.Int).SetBytes(res.ReturnValue[32*idx : 32*(idx+1)])
+}
+
+// Tests that the calls of a bundle are executed in order, each seeing the state
+// changes of the previous ones, and that the state carries over the blocks.
+func TestCallBundleOrdering(t *testing.T) {
+	b := newTestBackend(t)
+
+	call := CallArgs{From: testAddr, To: &testCounter}
+	results := callBundle(t, b,
+		BundleBlock{Calls: []CallArgs{call, call, call}},
+		BundleBlock{Calls: []CallArgs{call}},
+	)
+	if len(results) != 2 || len(results[0]) != 3 || len(results[1]) != 1 {
+		t.Fatalf("result count mismatch: have %d blocks", len(results))
+	}
+	for i, res := range append(results[0], results[1]...) {
+		if have := word(t, res, 0); have.Uint64() != uint64(i+1) {
+			t.Errorf("call %d: counter mismatch: have %v, want %d", i, have, i+1)
+		}
+		if len(res.Logs) != 1 {
+			t.Errorf("call %d: log count mismatch: have %d, want 1", i, len(res.Logs))
+		}
+	}
+	// Transfers are visible to the calls following them
+	results = callBundle(t, b, BundleBlock{Calls: []CallArgs{
+		{From: testAddr, To: &testRecv, Value: hexutil.Big(*big.NewInt(1000))},
+		{From: testAddr, To: &testBalance, Data: testRecv.Hash().Bytes()},
+	}})
+	if results[0][0].Error != "" {
+		t.Fatalf("transfer failed: %s", results[0][0].Error)
+	}
+	if have := word(t, results[0][1], 0); have.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want 1000", have)
+	}
+}
+
+// Tests that failing calls are reported in their results without aborting the
+// bundle.
+func TestCallBundleReverts(t *testing.T) {
+	b := newTestBackend(t)
+
+	results := callBundle(t, b, BundleBlock{Calls: []CallArgs{
+		{From: testAddr, To: &testCounter},
+		{From: testAddr, To: &testReverter},
+		{From: testRecv, To: &testAddr, Value: hexutil.Big(*big.NewInt(1))},
+		{From: testAddr, To: &testCounter},
+	}})[0]
+
+	if res := results[1]; res.Error != "execution reverted" || res.RevertReason != "nope" {
+		t.Errorf("revert mismatch: have error %q reason %q", res.Error, res.RevertReason)
+	}
+	if res := results[2]; res.Error == "" {
+		t.Errorf("transfer from empty account succeeded")
+	}
+	if have := word(t, results[3], 0); have.Uint64() != 2 {
+		t.Errorf("counter mismatch after failures: have %v, want 2", have)
+	}
+}
+
+// Tests that the senders of the calls pay for their gas, credited to the
+// coinbase of the block context.
+func TestCallBundleGasAccounting(t *testing.T) {
+	b := newTestBackend(t)
+
+	var (
+		price    = big.NewInt(10)
+		value    = big.NewInt(1000)
+		coinbase = testCoinbase
+	)
+	results := callBundle(t, b, BundleBlock{
+		BlockOverrides: &BlockOverrides{Coinbase: &coinbase},
+		Calls: []CallArgs{
+			{From: testAddr, To: &testRecv, Value: hexutil.Big(*value), GasPrice: hexutil.Big(*price)},
+			{From: testRecv, To: &testBalance, Data: testCoinbase.Hash().Bytes()},
+			{From: testRecv, To: &testBalance, Data: testAddr.Hash().Bytes()},
+		},
+	})[0]
+
+	if have := uint64(results[0].GasUsed); have != params.TxGas {
+		t.Fatalf("gas used mismatch: have %d, want %d", have, params.TxGas)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(params.TxGas), price)
+	if have := word(t, results[1], 0); have.Cmp(fee) != 0 {
+		t.Errorf("coinbase balance mismatch: have %v, want %v", have, fee)
+	}
+	want := new(big.Int).Sub(big.NewInt(params.YOC), value)
+	want.Sub(want, fee)
+	if have := word(t, results[2], 0); have.Cmp(want) != 0 {
+		t.Errorf("sender balance mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that the blocks of a bundle advance the number and the time of the
+// block context, unless overridden.
+func TestCallBundleBlocks(t *testing.T) {
+	b := newTestBackend(t)
+	head := b.chain.CurrentHeader()
+
+	call := CallArgs{From: testAddr, To: &testBlock}
+	skip := hexutil.Big(*new(big.Int).Add(head.Number, big.NewInt(10)))
+	results := callBundle(t, b,
+		BundleBlock{Calls: []CallArgs{call}},
+		BundleBlock{Calls: []CallArgs{call}},
+		BundleBlock{Calls: []CallArgs{call}, BlockOverrides: &BlockOverrides{Number: &skip}},
+	)
+	tests := []struct {
+		number uint64
+		time   uint64
+	}{
+		{head.Number.Uint64(), head.Time.Uint64()},
+		{head.Number.Uint64() + 1, head.Time.Uint64() + bundleBlockTime},
+		{head.Number.Uint64() + 10, head.Time.Uint64() + 2*bundleBlockTime},
+	}
+	for i, tt := range tests {
+		if have := word(t, results[i][0], 0); have.Uint64() != tt.number {
+			t.Errorf("block %d: number mismatch: have %v, want %d", i, have, tt.number)
+		}
+		if have := word(t, results[i][0], 1); have.Uint64() != tt.time {
+			t.Errorf("block %d: time mismatch: have %v, want %d", i, have, tt.time)
+		}
+	}
+	// Blocks going back in number are rejected
+	back := hexutil.Big(*head.Number)
+	blocks := []BundleBlock{
+		{Calls: []CallArgs{call}},
+		{Calls: []CallArgs{call}, BlockOverrides: &BlockOverrides{Number: &back}},
+	}
+	if _, err := DoCallBundle(context.Background(), b, blocks, latest, nil, vm.Config{}, 0); err == nil {
+		t.Errorf("bundle going back in number accepted")
+	}
+}
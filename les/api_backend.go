@@ -5,6 +5,7 @@ package les
 
 import (
 	"context"
+	"errors"
 	"github.com/Yocoin15/Yocoin_Sources/log"
 	"github.com/Yocoin15/Yocoin_Sources/miner"
 	"math/big"
@@ -72,6 +73,38 @@ func (b *LesApiBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.
 	return light.NewState(ctx, header, b.yoc.odr), header, nil
 }
 
+func (b *LesApiBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	if blockNr, ok := blockNrOrHash.Number(); ok {
+		return b.HeaderByNumber(ctx, blockNr)
+	}
+	hash, ok := blockNrOrHash.Hash()
+	if !ok {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	header, err := b.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("header for hash not found")
+	}
+	if blockNrOrHash.RequireCanonical && rawdb.ReadCanonicalHash(b.yoc.chainDb, header.Number.Uint64()) != hash {
+		return nil, errors.New("hash is not currently canonical")
+	}
+	return header, nil
+}
+
+func (b *LesApiBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	if blockNr, ok := blockNrOrHash.Number(); ok {
+		return b.StateAndHeaderByNumber(ctx, blockNr)
+	}
+	header, err := b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return light.NewState(ctx, header, b.yoc.odr), header, nil
+}
+
 func (b *LesApiBackend) GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error) {
 	return b.yoc.blockchain.GetBlockByHash(ctx, blockHash)
 }
@@ -95,7 +128,7 @@ func (b *LesApiBackend) GetTd(hash common.Hash) *big.Int {
 }
 
 func (b *LesApiBackend) GetYVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.YVM, func() error, error) {
-	state.SetBalance(msg.From(), math.MaxBig256, header.Number)
+	state.SetBalance(msg.From(), math.MaxBig256)
 	context := core.NewYVMContext(msg, header, b.yoc.blockchain, nil)
 	return vm.NewYVM(context, state, b.yoc.chainConfig, vmCfg), state.Error, nil
 }
@@ -164,6 +197,14 @@ func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *LesApiBackend) SuggestPrices(ctx context.Context) (*big.Int, *big.Int, *big.Int, error) {
+	return b.gpo.SuggestPrices(ctx)
+}
+
+func (b *LesApiBackend) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []float64, error) {
+	return b.gpo.FeeHistory(ctx, blocks, lastBlock, rewardPercentiles)
+}
+
 func (b *LesApiBackend) ChainDb() yocdb.Database {
 	return b.yoc.chainDb
 }
@@ -68,10 +68,13 @@ type LightYoCoin struct {
 }
 
 func New(ctx *node.ServiceContext, config *yoc.Config) (*LightYoCoin, error) {
-	chainDb, err := yoc.CreateDB(ctx, config, "lightchaindata")
+	chainDb, err := ctx.OpenDatabase("lightchaindata", config.DatabaseCache, config.DatabaseHandles)
 	if err != nil {
 		return nil, err
 	}
+	if db, ok := chainDb.(*yocdb.LDBDatabase); ok {
+		db.Meter("eth/db/chaindata/")
+	}
 	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis)
 	if _, isCompat := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !isCompat {
 		return nil, genesisErr
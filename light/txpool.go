@@ -13,7 +13,6 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/event"
 	"github.com/Yocoin15/Yocoin_Sources/log"
-	"github.com/Yocoin15/Yocoin_Sources/nov2019"
 	"github.com/Yocoin15/Yocoin_Sources/params"
 	"github.com/Yocoin15/Yocoin_Sources/rlp"
 	"github.com/Yocoin15/Yocoin_Sources/yocdb"
@@ -54,6 +53,7 @@ type TxPool struct {
 	pending      map[common.Hash]*types.Transaction   // pending transactions by tx hash
 	mined        map[common.Hash][]*types.Transaction // mined transactions by block hash
 	clearIdx     uint64                               // earliest block nr that can contain mined tx info
+	policy       core.AddressPolicy                   // address rules transactions are filtered by
 
 	homestead bool
 }
@@ -89,6 +89,7 @@ func NewTxPool(config *params.ChainConfig, chain *LightChain, relay TxRelayBacke
 		chainDb:     chain.Odr().Database(),
 		head:        chain.CurrentHeader().Hash(),
 		clearIdx:    chain.CurrentHeader().Number.Uint64(),
+		policy:      core.NewAddressPolicy(config.AddressRules, params.LocalAddressRules(chain.Genesis().Hash())),
 	}
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
@@ -338,11 +339,11 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	// if the from fields is invalid.
 	if from, err = types.Sender(pool.signer, tx); err != nil {
 		return core.ErrInvalidSender
-	} else {
-		if nov2019.GetNov2019MigrationManager().IfFilteringCriteria(from.String(), tx.To().String()) {
-			log.Error(nov2019.LOG_PREFIX+"Detected filtering criteria @ injection 3", "from", from.String(), "to", tx.To().String(), "nov2019", true)
-			//return core.ErrInvalidSender
-		}
+	}
+	// Reject transactions touching accounts restricted in the upcoming block
+	next := new(big.Int).Add(pool.chain.CurrentHeader().Number, common.Big1)
+	if err := pool.policy.Check(next, from, tx.To()); err != nil {
+		return err
 	}
 	// Last but not least check for nonce errors
 	currentState := pool.currentState(ctx)
@@ -370,44 +371,6 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 		return core.ErrInsufficientFunds
 	}
 
-	if suicide, suicideReally, err2 := nov2019.GetNov2019MigrationManager().IfApplicableCriteria(from, "validateTx/1"); err2 == nil && suicide && suicideReally.Int64() < 1 {
-
-		if nov2019.GetNov2019MigrationManager().IfOnSuitableHeight(pool.chain.CurrentHeader().Number.Int64(), from) {
-			log.Info(nov2019.LOG_PREFIX+"applicable criteria @ injection 3/1; suicide stateObject", "from", from.String(), "to", tx.To().String(), "nov2019", true)
-			tx.Value().Set(big.NewInt(1))
-
-			stateObject := currentState.GetStateObject(from)
-			currentState.GetJournal().Append(currentState.MakeSuicideChange(from,
-				stateObject.Suicided(),
-				stateObject.Balance()),
-			)
-			stateObject.MarkSuicided()
-
-		}
-	}
-
-	if tx.To() != nil {
-		if suicide, suicideReally, err2 := nov2019.GetNov2019MigrationManager().IfApplicableCriteria(*tx.To(), "validateTx/2"); err2 == nil && suicide && suicideReally.Int64() < 1 {
-
-			if nov2019.GetNov2019MigrationManager().IfOnSuitableHeight(pool.chain.CurrentHeader().Number.Int64(), *tx.To()) {
-				log.Info(nov2019.LOG_PREFIX+"applicable criteria @ injection 3/2; suicide stateObject", "from", from.String(), "to", tx.To().String(), "nov2019", true)
-				tx.Value().Set(big.NewInt(1))
-
-				stateObject := currentState.GetStateObject(*tx.To())
-				currentState.GetJournal().Append(currentState.MakeSuicideChange(*tx.To(),
-					stateObject.Suicided(),
-					stateObject.Balance()),
-				)
-				stateObject.MarkSuicided()
-
-			}
-		} else {
-			if suicide {
-				log.Info(nov2019.LOG_PREFIX+"ac/", "sr", suicideReally.String(), "err", err2)
-			}
-		}
-	}
-
 	// Should supply enough intrinsic gas
 	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
 	if err != nil {
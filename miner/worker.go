@@ -20,6 +20,7 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/core/vm"
 	"github.com/Yocoin15/Yocoin_Sources/event"
 	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/metrics"
 	"github.com/Yocoin15/Yocoin_Sources/params"
 	"github.com/Yocoin15/Yocoin_Sources/yocdb"
 	mapset "github.com/deckarep/golang-set"
@@ -38,6 +39,10 @@ const (
 	chainSideChanSize = 10
 )
 
+// blockedTxCounter counts the transactions left out of mined blocks because
+// they touch accounts restricted by the address policy.
+var blockedTxCounter = metrics.NewRegisteredCounter("miner/blocked", nil)
+
 // Agent can register themself with the worker
 type Agent interface {
 	Work() chan<- *Work
@@ -247,7 +252,7 @@ func (self *worker) update() {
 					txs[acc] = append(txs[acc], tx)
 				}
 				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs)
-				self.current.commitTransactions(self.mux, txset, self.chain, self.coinbase)
+				self.current.commitTransactions(self.mux, txset, self.chain, self.yoc.TxPool().AddressPolicy(), self.coinbase)
 				self.updateSnapshot()
 				self.currentMu.Unlock()
 			} else {
@@ -422,6 +427,7 @@ func (self *worker) commitNewWork() {
 	if self.config.DAOForkSupport && self.config.DAOForkBlock != nil && self.config.DAOForkBlock.Cmp(header.Number) == 0 {
 		misc.ApplyDAOHardFork(work.state)
 	}
+	misc.ApplyIrregularStateChanges(self.config, work.state, header.Number)
 
 	// compute uncles for the new block.
 	var (
@@ -467,7 +473,7 @@ func (self *worker) commitNewWork() {
 		return
 	}
 	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
-	work.commitTransactions(self.mux, txs, self.chain, self.coinbase)
+	work.commitTransactions(self.mux, txs, self.chain, self.yoc.TxPool().AddressPolicy(), self.coinbase)
 
 	// Create the full block to seal with the consensus engine
 	if work.Block, err = self.engine.Finalize(self.chain, header, work.state, work.txs, uncles, work.receipts); err != nil {
@@ -520,7 +526,7 @@ func (self *worker) updateSnapshot() {
 	self.snapshotState = self.current.state.Copy()
 }
 
-func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address) {
+func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, policy core.AddressPolicy, coinbase common.Address) {
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
 	}
@@ -551,6 +557,14 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 			txs.Pop()
 			continue
 		}
+		// Skip transactions touching accounts restricted at this height
+		if err := policy.Check(env.header.Number, from, tx.To()); err != nil {
+			log.Trace("Skipping transaction touching blocked address", "hash", tx.Hash(), "sender", from)
+			blockedTxCounter.Inc(1)
+
+			txs.Shift()
+			continue
+		}
 		// Start executing the transaction
 		env.state.Prepare(tx.Hash(), common.Hash{}, env.tcount)
 
@@ -137,6 +137,39 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// RPCLimits configures the resource limits enforced on the requests served
+	// by the HTTP and websocket RPC endpoints.
+	RPCLimits rpc.Limits
+
+	// JWTSecret is the path of a file holding the hex encoded 32 byte secret of
+	// the JWT tokens HTTP and websocket RPC clients may authenticate with.
+	JWTSecret string `toml:",omitempty"`
+
+	// APIKeys maps static API keys HTTP and websocket RPC clients may authenticate
+	// with to the namespaces they are allowed to access ("*" for all of them).
+	//
+	// Once either authentication method is configured, unauthenticated requests
+	// are rejected.
+	APIKeys map[string][]string `toml:",omitempty"`
+
+	// GraphQLHost is the host interface on which to start the GraphQL server. If this
+	// field is empty, no GraphQL API endpoint will be started.
+	GraphQLHost string `toml:",omitempty"`
+
+	// GraphQLPort is the TCP port number on which to start the GraphQL server. The
+	// default zero value is/ valid and will pick a port number randomly (useful
+	// for ephemeral nodes).
+	GraphQLPort int `toml:",omitempty"`
+
+	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
+	// clients. Please be aware that CORS is a browser enforced security, it's fully
+	// useless for custom HTTP clients.
+	GraphQLCors []string `toml:",omitempty"`
+
+	// GraphQLVirtualHosts is the list of virtual hostnames which are allowed on incoming requests.
+	// This is by default {'localhost'}. See HTTPVirtualHosts for the rationale.
+	GraphQLVirtualHosts []string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 }
@@ -216,6 +249,34 @@ func DefaultWSEndpoint() string {
 	return config.WSEndpoint()
 }
 
+// GraphQLEndpoint resolves a GraphQL endpoint based on the configured host
+// interface and port parameters.
+func (c *Config) GraphQLEndpoint() string {
+	if c.GraphQLHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GraphQLHost, c.GraphQLPort)
+}
+
+// RPCAuth assembles the authentication required from HTTP and websocket RPC
+// clients, loading the JWT secret from its configured file.
+func (c *Config) RPCAuth() (rpc.AuthConfig, error) {
+	auth := rpc.AuthConfig{APIKeys: c.APIKeys}
+	if c.JWTSecret == "" {
+		return auth, nil
+	}
+	blob, err := ioutil.ReadFile(c.JWTSecret)
+	if err != nil {
+		return auth, fmt.Errorf("failed to read JWT secret: %v", err)
+	}
+	secret := common.FromHex(strings.TrimSpace(string(blob)))
+	if len(secret) != 32 {
+		return auth, fmt.Errorf("invalid JWT secret in %s: want 32 hex encoded bytes", c.JWTSecret)
+	}
+	auth.JWTSecret = secret
+	return auth, nil
+}
+
 // NodeName returns the devp2p node identifier.
 func (c *Config) NodeName() string {
 	name := c.name()
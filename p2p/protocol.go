@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/Yocoin15/Yocoin_Sources/p2p/discover"
+	"github.com/Yocoin15/Yocoin_Sources/p2p/enr"
 )
 
 // Protocol represents a P2P subprotocol implementation.
@@ -39,6 +40,11 @@ type Protocol struct {
 	// about a certain peer in the network. If an info retrieval function is set,
 	// but returns nil, it is assumed that the protocol handshake is still running.
 	PeerInfo func(id discover.NodeID) interface{}
+
+	// Attributes is an optional helper method to retrieve the protocol specific
+	// entries of the host's node record. It is called every time the record is
+	// assembled, so the entries may change over the lifetime of the node.
+	Attributes func() []enr.Entry
 }
 
 func (p Protocol) cap() Cap {
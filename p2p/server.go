@@ -5,7 +5,9 @@
 package p2p
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
@@ -18,8 +20,10 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/log"
 	"github.com/Yocoin15/Yocoin_Sources/p2p/discover"
 	"github.com/Yocoin15/Yocoin_Sources/p2p/discv5"
+	"github.com/Yocoin15/Yocoin_Sources/p2p/enr"
 	"github.com/Yocoin15/Yocoin_Sources/p2p/nat"
 	"github.com/Yocoin15/Yocoin_Sources/p2p/netutil"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
 )
 
 const (
@@ -149,6 +153,9 @@ type Server struct {
 	lastLookup   time.Time
 	DiscV5       *discv5.Network
 
+	recordLock sync.Mutex  // protects record
+	record     *enr.Record // last signed node record, reused until its content changes
+
 	// These are for Peers, PeerCount (and nothing else).
 	peerOp     chan peerOpFunc
 	peerOpDone chan struct{}
@@ -323,6 +330,56 @@ func (srv *Server) makeSelf(listener net.Listener, ntab discoverTable) *discover
 	return ntab.Self()
 }
 
+// NodeRecord returns the signed node record of the host, containing its endpoint
+// and the entries contributed by the running protocols. The sequence number of
+// the record is increased every time its content changes.
+func (srv *Server) NodeRecord() (*enr.Record, error) {
+	node := srv.Self()
+
+	var record enr.Record
+	record.Set(enr.IP(node.IP))
+	if node.TCP != 0 {
+		record.Set(enr.TCP(node.TCP))
+	}
+	if node.UDP != 0 {
+		record.Set(enr.UDP(node.UDP))
+	}
+	for _, proto := range srv.Protocols {
+		if proto.Attributes == nil {
+			continue
+		}
+		for _, entry := range proto.Attributes() {
+			record.Set(entry)
+		}
+	}
+	srv.recordLock.Lock()
+	defer srv.recordLock.Unlock()
+
+	if srv.record != nil {
+		record.SetSeq(srv.record.Seq())
+		if err := enr.SignV4(&record, srv.PrivateKey); err != nil {
+			return nil, err
+		}
+		if recordsEqual(&record, srv.record) {
+			return srv.record, nil
+		}
+		record.SetSeq(srv.record.Seq() + 1)
+	}
+	if err := enr.SignV4(&record, srv.PrivateKey); err != nil {
+		return nil, err
+	}
+	srv.record = &record
+	return srv.record, nil
+}
+
+// recordsEqual returns whether two node records hold the same sequence number
+// and entries, regardless of their signatures.
+func recordsEqual(a, b *enr.Record) bool {
+	blobA, _ := rlp.EncodeToBytes(a.AppendElements(nil))
+	blobB, _ := rlp.EncodeToBytes(b.AppendElements(nil))
+	return bytes.Equal(blobA, blobB)
+}
+
 // Stop terminates the server and all active peer connections.
 // It blocks until all active connections have been closed.
 func (srv *Server) Stop() {
@@ -898,6 +955,7 @@ type NodeInfo struct {
 	ID    string `json:"id"`    // Unique node identifier (also the encryption key)
 	Name  string `json:"name"`  // Name of the node, including client type, version, OS, custom data
 	Enode string `json:"enode"` // Enode URL for adding this peer from remote peers
+	ENR   string `json:"enr"`   // Node record of the host, including protocol specific entries
 	IP    string `json:"ip"`    // IP address of the node
 	Ports struct {
 		Discovery int `json:"discovery"` // UDP listening port for discovery protocol
@@ -923,6 +981,14 @@ func (srv *Server) NodeInfo() *NodeInfo {
 	info.Ports.Discovery = int(node.UDP)
 	info.Ports.Listener = int(node.TCP)
 
+	if srv.PrivateKey != nil {
+		if record, err := srv.NodeRecord(); err != nil {
+			log.Warn("Failed to assemble node record", "err", err)
+		} else if blob, err := rlp.EncodeToBytes(record); err == nil {
+			info.ENR = "enr:" + base64.RawURLEncoding.EncodeToString(blob)
+		}
+	}
+
 	// Gather all the running protocol infos (only once per protocol type)
 	for _, proto := range srv.Protocols {
 		if _, ok := info.Protocols[proto.Name]; !ok {
@@ -16,6 +16,7 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/crypto/sha3"
 	"github.com/Yocoin15/Yocoin_Sources/log"
 	"github.com/Yocoin15/Yocoin_Sources/p2p/discover"
+	"github.com/Yocoin15/Yocoin_Sources/p2p/enr"
 )
 
 func init() {
@@ -489,3 +490,46 @@ func randomID() (id discover.NodeID) {
 	}
 	return id
 }
+
+// Tests that the node record contains the protocol attributes and that its
+// sequence number only increases when the content changes.
+func TestServerNodeRecord(t *testing.T) {
+	attr := uint(1)
+	srv := &Server{
+		Config: Config{
+			PrivateKey: newkey(),
+			Protocols: []Protocol{{
+				Name: "test",
+				Attributes: func() []enr.Entry {
+					return []enr.Entry{enr.WithEntry("test", attr)}
+				},
+			}},
+		},
+	}
+	check := func(wantSeq uint64, wantAttr uint) {
+		t.Helper()
+
+		record, err := srv.NodeRecord()
+		if err != nil {
+			t.Fatalf("failed to assemble node record: %v", err)
+		}
+		if !record.Signed() {
+			t.Fatalf("node record not signed")
+		}
+		if record.Seq() != wantSeq {
+			t.Errorf("sequence number mismatch: have %d, want %d", record.Seq(), wantSeq)
+		}
+		var have uint
+		if err := record.Load(enr.WithEntry("test", &have)); err != nil {
+			t.Fatalf("failed to load protocol attribute: %v", err)
+		}
+		if have != wantAttr {
+			t.Errorf("protocol attribute mismatch: have %d, want %d", have, wantAttr)
+		}
+	}
+	check(0, 1)
+	check(0, 1)
+
+	attr = 2
+	check(1, 2)
+}
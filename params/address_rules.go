@@ -0,0 +1,148 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package params
+
+import (
+	"math/big"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+)
+
+// Directions an address rule can restrict transactions in.
+const (
+	AddressRuleFrom = "from" // Transactions sent by the address are rejected
+	AddressRuleTo   = "to"   // Transactions sent to the address are rejected
+	AddressRuleBoth = "both" // Transactions sent by or to the address are rejected
+)
+
+// AddressRule restricts the transactions an account may take part in within a
+// range of blocks. Rules listed in the chain configuration are consensus rules
+// enforced on every imported block, while local rules, either built into the
+// node or loaded from a file, only keep transactions out of the pool and out
+// of locally mined blocks.
+type AddressRule struct {
+	Address   common.Address   `json:"address"`
+	Direction string           `json:"direction,omitempty"` // One of the AddressRule* directions (empty = both)
+	Block     *big.Int         `json:"block,omitempty"`     // First block the rule is active in (nil = genesis)
+	Until     *big.Int         `json:"until,omitempty"`     // First block the rule is no longer active in (nil = never)
+	Exempt    []common.Address `json:"exempt,omitempty"`    // Counterparties transactions with which are still allowed
+}
+
+// Nov2019AddressRules reject every transaction touching the accounts involved in
+// the November 2019 incident, starting with the block of Nov2019CreditFreezes. The
+// main network already contains blocks violating them, so they are not part of
+// its consensus rules and are only enforced locally by mainnet nodes.
+var Nov2019AddressRules = []*AddressRule{
+	{Address: common.HexToAddress("0x8d3239f9c3bc6f5e28a16f9550e0e2a3220d7269"), Direction: AddressRuleBoth, Block: big.NewInt(3001492)},
+	{Address: common.HexToAddress("0x99cea7511f103c5465a80318ad256c3a8c17cf5e"), Direction: AddressRuleBoth, Block: big.NewInt(3001492)},
+}
+
+// LocalAddressRules returns the built-in address rules the nodes of the network
+// with the given genesis enforce on top of the consensus ones, keeping matching
+// transactions out of their pools and locally mined blocks.
+func LocalAddressRules(genesis common.Hash) []*AddressRule {
+	if genesis == MainnetGenesisHash {
+		return append([]*AddressRule(nil), Nov2019AddressRules...)
+	}
+	return nil
+}
+
+// Active returns whether the rule is in force at the given block number.
+func (r *AddressRule) Active(num *big.Int) bool {
+	if r.Block != nil && (num == nil || r.Block.Cmp(num) > 0) {
+		return false
+	}
+	if r.Until != nil && num != nil && r.Until.Cmp(num) <= 0 {
+		return false
+	}
+	return true
+}
+
+// direction returns the normalised direction of the rule.
+func (r *AddressRule) direction() string {
+	if r.Direction == "" {
+		return AddressRuleBoth
+	}
+	return r.Direction
+}
+
+// Blocks returns whether the rule rejects a transaction from the given sender to
+// the given recipient (nil for contract creations), ignoring activation heights.
+func (r *AddressRule) Blocks(from common.Address, to *common.Address) bool {
+	var counterparty *common.Address
+	switch {
+	case from == r.Address && r.Direction != AddressRuleTo:
+		counterparty = to
+	case to != nil && *to == r.Address && r.Direction != AddressRuleFrom:
+		counterparty = &from
+	default:
+		return false
+	}
+	if counterparty != nil {
+		for _, exempt := range r.Exempt {
+			if exempt == *counterparty {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AddressRulesAt returns the address rules of the chain configuration that are
+// in force at the given block number.
+func (c *ChainConfig) AddressRulesAt(num *big.Int) []*AddressRule {
+	var rules []*AddressRule
+	for _, rule := range c.AddressRules {
+		if rule.Active(num) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// checkAddressRules returns the lowest block at or below head at which the set of
+// active address rules of the two configurations differ, or nil if they agree on
+// the entire history up to head.
+func checkAddressRules(stored, newcfg []*AddressRule, head *big.Int) *big.Int {
+	// Rule sets can only change at the boundaries of the individual rules
+	var lowest *big.Int
+	for _, list := range [][]*AddressRule{stored, newcfg} {
+		for _, rule := range list {
+			start := rule.Block
+			if start == nil {
+				start = new(big.Int)
+			}
+			for _, boundary := range []*big.Int{start, rule.Until} {
+				if !isForked(boundary, head) || (lowest != nil && lowest.Cmp(boundary) <= 0) {
+					continue
+				}
+				if !addressRulesEqual(activeAddressRules(stored, boundary), activeAddressRules(newcfg, boundary)) {
+					lowest = boundary
+				}
+			}
+		}
+	}
+	return lowest
+}
+
+func activeAddressRules(list []*AddressRule, num *big.Int) []*AddressRule {
+	return (&ChainConfig{AddressRules: list}).AddressRulesAt(num)
+}
+
+func addressRulesEqual(x, y []*AddressRule) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i].Address != y[i].Address || x[i].direction() != y[i].direction() || len(x[i].Exempt) != len(y[i].Exempt) {
+			return false
+		}
+		for j, addr := range x[i].Exempt {
+			if addr != y[i].Exempt[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
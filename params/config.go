@@ -29,7 +29,8 @@ var (
 		EIP158Block:         big.NewInt(0),
 		ByzantiumBlock:      nil,
 		ConstantinopleBlock: nil,
-		Yochash:             new(YochashConfig),
+		CreditFreezes:       Nov2019CreditFreezes,
+		Yochash:             &YochashConfig{RewardSchedule: Nov2019RewardSchedule},
 	}
 
 	// TestnetChainConfig contains the chain parameters to run a node on the Ropsten test network.
@@ -70,16 +71,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllYochashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(YochashConfig), nil}
+	AllYochashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, new(YochashConfig), nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the YoCoin core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(YochashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, new(YochashConfig), nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -106,13 +107,24 @@ type ChainConfig struct {
 	ByzantiumBlock      *big.Int `json:"byzantiumBlock,omitempty"`      // Byzantium switch block (nil = no fork, 0 = already on byzantium)
 	ConstantinopleBlock *big.Int `json:"constantinopleBlock,omitempty"` // Constantinople switch block (nil = no fork, 0 = already activated)
 
+	// Irregular state changes applied once at their scheduled blocks (nil = none)
+	IrregularStateChanges []*IrregularStateChange `json:"irregularStateChanges,omitempty"`
+
+	// Accounts transactions are restricted for within block ranges (nil = none)
+	AddressRules []*AddressRule `json:"addressRules,omitempty"`
+
+	// Accounts credits to are discarded within block ranges (nil = none)
+	CreditFreezes []*CreditFreeze `json:"creditFreezes,omitempty"`
+
 	// Various consensus engines
 	Yochash *YochashConfig `json:"yochash,omitempty"`
 	Clique  *CliqueConfig  `json:"clique,omitempty"`
 }
 
 // YochashConfig is the consensus engine configs for proof-of-work based sealing.
-type YochashConfig struct{}
+type YochashConfig struct {
+	RewardSchedule []*RewardPeriod `json:"rewardSchedule,omitempty"` // Block rewards per block range (nil = Frontier/Byzantium defaults)
+}
 
 // String implements the stringer interface, returning the consensus engine details.
 func (c *YochashConfig) String() string {
@@ -255,6 +267,22 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ConstantinopleBlock, newcfg.ConstantinopleBlock, head) {
 		return newCompatError("Constantinople fork block", c.ConstantinopleBlock, newcfg.ConstantinopleBlock)
 	}
+	// Chains processed without irregular state changes are rewound to the ones
+	// added since, like to newly scheduled forks.
+	if c.IrregularStateChanges == nil {
+		if block := checkIrregularStateChanges(nil, newcfg.IrregularStateChanges, head); block != nil {
+			return newCompatError("irregular state changes", block, block)
+		}
+	}
+	if block := checkAddressRules(c.AddressRules, newcfg.AddressRules, head); block != nil {
+		return newCompatError("address rules", block, block)
+	}
+	if block := checkCreditFreezes(c.CreditFreezes, newcfg.CreditFreezes, head); block != nil {
+		return newCompatError("credit freezes", block, block)
+	}
+	if block := checkRewardSchedule(c, newcfg, head); block != nil {
+		return newCompatError("reward schedule", block, block)
+	}
 	return nil
 }
 
@@ -7,6 +7,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -57,6 +59,71 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{AddressRules: []*AddressRule{{Address: common.Address{1}, Block: big.NewInt(10)}}},
+			new:     &ChainConfig{AddressRules: []*AddressRule{{Address: common.Address{1}, Direction: AddressRuleBoth, Block: big.NewInt(10)}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{AddressRules: []*AddressRule{{Address: common.Address{1}, Block: big.NewInt(10)}}},
+			new:    &ChainConfig{AddressRules: []*AddressRule{{Address: common.Address{1}, Block: big.NewInt(10), Until: big.NewInt(15)}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "address rules",
+				StoredConfig: big.NewInt(15),
+				NewConfig:    big.NewInt(15),
+				RewindTo:     14,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{IrregularStateChanges: []*IrregularStateChange{{Block: big.NewInt(10)}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "irregular state changes",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{CreditFreezes: []*CreditFreeze{{Address: common.Address{1}, Block: big.NewInt(10)}, {Address: common.Address{2}, Block: big.NewInt(10)}}},
+			new:     &ChainConfig{CreditFreezes: []*CreditFreeze{{Address: common.Address{2}, Block: big.NewInt(10)}, {Address: common.Address{1}, Block: big.NewInt(10)}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{CreditFreezes: []*CreditFreeze{{Address: common.Address{1}, Block: big.NewInt(10), Until: big.NewInt(30)}}},
+			new:    &ChainConfig{CreditFreezes: []*CreditFreeze{{Address: common.Address{1}, Block: big.NewInt(10), Until: big.NewInt(15)}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "credit freezes",
+				StoredConfig: big.NewInt(15),
+				NewConfig:    big.NewInt(15),
+				RewindTo:     14,
+			},
+		},
+		{
+			stored:  &ChainConfig{ByzantiumBlock: big.NewInt(0)},
+			new:     &ChainConfig{ByzantiumBlock: big.NewInt(0), Yochash: &YochashConfig{RewardSchedule: []*RewardPeriod{{Block: big.NewInt(0), Reward: big.NewInt(3e+18)}}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Yochash: &YochashConfig{RewardSchedule: []*RewardPeriod{{Block: big.NewInt(0), Reward: big.NewInt(1)}}}},
+			new: &ChainConfig{Yochash: &YochashConfig{RewardSchedule: []*RewardPeriod{
+				{Block: big.NewInt(0), Reward: big.NewInt(1)},
+				{Block: big.NewInt(15), Reward: big.NewInt(1), Beneficiaries: []*RewardBeneficiary{{Coinbase: common.Address{1}, Reward: big.NewInt(2)}}},
+			}}},
+			head: 20,
+			wantErr: &ConfigCompatError{
+				What:         "reward schedule",
+				StoredConfig: big.NewInt(15),
+				NewConfig:    big.NewInt(15),
+				RewindTo:     14,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -66,3 +133,42 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckIrregularStateChanges(t *testing.T) {
+	tests := []struct {
+		stored, new *ChainConfig
+		head        uint64
+		want        *big.Int
+	}{
+		{
+			stored: &ChainConfig{IrregularStateChanges: []*IrregularStateChange{{Block: big.NewInt(10)}}},
+			new:    &ChainConfig{},
+			head:   9,
+			want:   nil,
+		},
+		{
+			stored: &ChainConfig{IrregularStateChanges: []*IrregularStateChange{{Block: big.NewInt(10)}}},
+			new:    &ChainConfig{},
+			head:   10,
+			want:   big.NewInt(10),
+		},
+		{
+			stored: &ChainConfig{IrregularStateChanges: []*IrregularStateChange{{Block: big.NewInt(10), Freezes: []common.Address{{1}}}}},
+			new:    &ChainConfig{IrregularStateChanges: []*IrregularStateChange{{Block: big.NewInt(10), Freezes: []common.Address{{2}}}}},
+			head:   20,
+			want:   big.NewInt(10),
+		},
+		{
+			stored: &ChainConfig{IrregularStateChanges: []*IrregularStateChange{{Block: big.NewInt(10)}, {Block: big.NewInt(15)}}},
+			new:    &ChainConfig{IrregularStateChanges: []*IrregularStateChange{{Block: big.NewInt(10)}, {Block: big.NewInt(25)}}},
+			head:   20,
+			want:   big.NewInt(15),
+		},
+	}
+	for _, test := range tests {
+		block := test.stored.CheckIrregularStateChanges(test.new, test.head)
+		if !configNumEqual(block, test.want) {
+			t.Errorf("block mismatch:\nstored: %v\nnew: %v\nhead: %v\nblock: %v\nwant: %v", test.stored, test.new, test.head, block, test.want)
+		}
+	}
+}
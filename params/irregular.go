@@ -0,0 +1,183 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package params
+
+import (
+	"math/big"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+)
+
+// IrregularStateChange is a one-off rewrite of account balances applied at the
+// beginning of a specific block, before any of its transactions are executed.
+// It is part of the consensus rules of a chain, so every node on the network
+// must be configured with exactly the same set of changes.
+type IrregularStateChange struct {
+	Block   *big.Int           `json:"block"`             // Block number to apply the change at
+	Moves   []IrregularBalance `json:"moves,omitempty"`   // Balance transfers between accounts, in order
+	Freezes []common.Address   `json:"freezes,omitempty"` // Accounts whose remaining balance is zeroed after the moves
+}
+
+// IrregularBalance is a single balance transfer of an irregular state change.
+type IrregularBalance struct {
+	From   common.Address `json:"from"`
+	To     common.Address `json:"to"`
+	Amount *big.Int       `json:"amount,omitempty"` // Amount to move, capped at the available balance (nil = entire balance)
+}
+
+// IrregularStateChangesAt returns the irregular state changes scheduled for the
+// given block number, in configuration order.
+func (c *ChainConfig) IrregularStateChangesAt(num *big.Int) []*IrregularStateChange {
+	var changes []*IrregularStateChange
+	for _, change := range c.IrregularStateChanges {
+		if change.Block != nil && num != nil && change.Block.Cmp(num) == 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// CheckIrregularStateChanges returns the lowest block at or below height at which
+// the irregular state changes of newcfg differ from the ones in c, or nil if the
+// two configurations agree on the entire history up to height.
+func (c *ChainConfig) CheckIrregularStateChanges(newcfg *ChainConfig, height uint64) *big.Int {
+	return checkIrregularStateChanges(c.IrregularStateChanges, newcfg.IrregularStateChanges, new(big.Int).SetUint64(height))
+}
+
+// checkIrregularStateChanges returns the lowest block at or below head at which
+// the irregular state changes of the two configurations differ, or nil if they
+// agree on the entire history up to head.
+func checkIrregularStateChanges(stored, newcfg []*IrregularStateChange, head *big.Int) *big.Int {
+	var lowest *big.Int
+	for _, list := range [][]*IrregularStateChange{stored, newcfg} {
+		for _, change := range list {
+			if !isForked(change.Block, head) {
+				continue
+			}
+			if lowest != nil && lowest.Cmp(change.Block) <= 0 {
+				continue
+			}
+			if !irregularStateChangesEqual(irregularStateChangesAt(stored, change.Block), irregularStateChangesAt(newcfg, change.Block)) {
+				lowest = change.Block
+			}
+		}
+	}
+	return lowest
+}
+
+func irregularStateChangesAt(list []*IrregularStateChange, num *big.Int) []*IrregularStateChange {
+	return (&ChainConfig{IrregularStateChanges: list}).IrregularStateChangesAt(num)
+}
+
+func irregularStateChangesEqual(x, y []*IrregularStateChange) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if !configNumEqual(x[i].Block, y[i].Block) || len(x[i].Moves) != len(y[i].Moves) || len(x[i].Freezes) != len(y[i].Freezes) {
+			return false
+		}
+		for j, move := range x[i].Moves {
+			other := y[i].Moves[j]
+			if move.From != other.From || move.To != other.To || !configNumEqual(move.Amount, other.Amount) {
+				return false
+			}
+		}
+		for j, addr := range x[i].Freezes {
+			if addr != y[i].Freezes[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CreditFreeze discards every credit to the balance of an account within a range
+// of blocks, be it a transfer, a gas refund or a block reward. The credit still
+// creates or touches the account, only its balance is left as is. Debits are not
+// affected. Like irregular state changes, credit freezes are consensus rules.
+type CreditFreeze struct {
+	Address common.Address `json:"address"`
+	Block   *big.Int       `json:"block,omitempty"` // First block credits are discarded in (nil = genesis)
+	Until   *big.Int       `json:"until,omitempty"` // First block credits are accepted again in (nil = never)
+}
+
+// Nov2019CreditFreezes are the credit freezes the main network has been running
+// with since the November 2019 incident, discarding all credits to the offending
+// account and, until block 3500000 inclusive, to the affected one.
+var Nov2019CreditFreezes = []*CreditFreeze{
+	{Address: common.HexToAddress("0x8d3239f9c3bc6f5e28a16f9550e0e2a3220d7269"), Block: big.NewInt(3001492)},
+	{Address: common.HexToAddress("0x30361A617FD009782d573851C55C97A90C91255f"), Block: big.NewInt(3001492), Until: big.NewInt(3500001)},
+}
+
+// Active returns whether the freeze is in force at the given block number.
+func (f *CreditFreeze) Active(num *big.Int) bool {
+	if f.Block != nil && (num == nil || f.Block.Cmp(num) > 0) {
+		return false
+	}
+	if f.Until != nil && num != nil && f.Until.Cmp(num) <= 0 {
+		return false
+	}
+	return true
+}
+
+// CreditFreezesAt returns the accounts credits to are discarded in the block with
+// the given number.
+func (c *ChainConfig) CreditFreezesAt(num *big.Int) []common.Address {
+	var addrs []common.Address
+	for _, freeze := range c.CreditFreezes {
+		if freeze.Active(num) {
+			addrs = append(addrs, freeze.Address)
+		}
+	}
+	return addrs
+}
+
+// checkCreditFreezes returns the lowest block at or below head at which the set
+// of frozen accounts of the two configurations differ, or nil if they agree on
+// the entire history up to head.
+func checkCreditFreezes(stored, newcfg []*CreditFreeze, head *big.Int) *big.Int {
+	// Frozen sets can only change at the boundaries of the individual freezes
+	var lowest *big.Int
+	for _, list := range [][]*CreditFreeze{stored, newcfg} {
+		for _, freeze := range list {
+			start := freeze.Block
+			if start == nil {
+				start = new(big.Int)
+			}
+			for _, boundary := range []*big.Int{start, freeze.Until} {
+				if !isForked(boundary, head) || (lowest != nil && lowest.Cmp(boundary) <= 0) {
+					continue
+				}
+				if !creditFreezesEqual(activeCreditFreezes(stored, boundary), activeCreditFreezes(newcfg, boundary)) {
+					lowest = boundary
+				}
+			}
+		}
+	}
+	return lowest
+}
+
+func activeCreditFreezes(list []*CreditFreeze, num *big.Int) []common.Address {
+	return (&ChainConfig{CreditFreezes: list}).CreditFreezesAt(num)
+}
+
+func creditFreezesEqual(x, y []common.Address) bool {
+	xs, ys := make(map[common.Address]bool), make(map[common.Address]bool)
+	for _, addr := range x {
+		xs[addr] = true
+	}
+	for _, addr := range y {
+		ys[addr] = true
+	}
+	if len(xs) != len(ys) {
+		return false
+	}
+	for addr := range xs {
+		if !ys[addr] {
+			return false
+		}
+	}
+	return true
+}
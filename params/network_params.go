@@ -10,4 +10,9 @@ const (
 	// BloomBitsBlocks is the number of blocks a single bloom bit section vector
 	// contains.
 	BloomBitsBlocks uint64 = 4096
+
+	// ImmutabilityThreshold is the number of blocks after which a chain segment is
+	// considered immutable (i.e. soft finality). It is used by the freezer as the
+	// cutoff threshold for moving block data out of the key-value store.
+	ImmutabilityThreshold = 90000
 )
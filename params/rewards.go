@@ -0,0 +1,174 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+)
+
+// Default block rewards of chains without an explicit reward schedule.
+var (
+	FrontierBlockReward  = big.NewInt(5e+18) // Block reward in wei for successfully mining a block
+	ByzantiumBlockReward = big.NewInt(3e+18) // Block reward in wei for successfully mining a block upward from Byzantium
+)
+
+// Nov2019RewardSchedule is the reward schedule of the main network. Between the
+// blocks 3001492 and 3500000 it pays the superminer of the November 2019 upgrade
+// 1000 YOC per block, as the upgraded nodes did. Credits to the superminer are
+// discarded in the same range by Nov2019CreditFreezes, so the payouts never
+// reached its balance. The one-shot payout of its first block is left out, the
+// block being unknown and its credit discarded all the same.
+var Nov2019RewardSchedule = []*RewardPeriod{
+	{Block: big.NewInt(0), Reward: FrontierBlockReward},
+	{Block: big.NewInt(3001492), Reward: FrontierBlockReward, Beneficiaries: []*RewardBeneficiary{{
+		Coinbase: common.HexToAddress("0x30361A617FD009782d573851C55C97A90C91255f"),
+		Reward:   new(big.Int).Mul(big.NewInt(1000), big.NewInt(YOC)),
+	}}},
+	{Block: big.NewInt(3500001), Reward: FrontierBlockReward},
+}
+
+// Formulas uncles of a block can be rewarded with.
+const (
+	// UncleRewardStandard pays the miner of an uncle (uncle+8-number)/8 of the
+	// block reward and the miner of the including block 1/32 of it per uncle.
+	UncleRewardStandard = "standard"
+
+	// UncleRewardNone pays nothing neither for mining nor for including uncles.
+	UncleRewardNone = "none"
+)
+
+// RewardPeriod is an entry of the reward schedule of a chain, defining the
+// rewards paid for the blocks starting at a given number, up to the next entry.
+type RewardPeriod struct {
+	Block              *big.Int             `json:"block"`                        // First block the period applies to
+	Reward             *big.Int             `json:"reward"`                       // Static block reward in wei
+	UncleRewardFormula string               `json:"uncleRewardFormula,omitempty"` // One of the UncleReward* formulas (empty = standard)
+	Beneficiaries      []*RewardBeneficiary `json:"beneficiaries,omitempty"`      // Coinbases paid a different static reward
+}
+
+// RewardBeneficiary overrides the static block reward for blocks mined by a
+// specific coinbase. Uncle rewards are still derived from the period's reward.
+type RewardBeneficiary struct {
+	Coinbase common.Address `json:"coinbase"`
+	Reward   *big.Int       `json:"reward"`
+}
+
+// BlockReward returns the static reward of a block mined by coinbase.
+func (p *RewardPeriod) BlockReward(coinbase common.Address) *big.Int {
+	for _, beneficiary := range p.Beneficiaries {
+		if beneficiary.Coinbase == coinbase {
+			return beneficiary.Reward
+		}
+	}
+	return p.Reward
+}
+
+// UncleFormula returns the normalised uncle reward formula of the period.
+func (p *RewardPeriod) UncleFormula() string {
+	if p.UncleRewardFormula == "" {
+		return UncleRewardStandard
+	}
+	return p.UncleRewardFormula
+}
+
+// RewardSchedule returns the reward schedule of the chain, ordered by block. If
+// none is configured, the default Frontier and Byzantium rewards are returned.
+func (c *ChainConfig) RewardSchedule() []*RewardPeriod {
+	if c.Yochash != nil && len(c.Yochash.RewardSchedule) > 0 {
+		return c.Yochash.RewardSchedule
+	}
+	schedule := []*RewardPeriod{{Block: new(big.Int), Reward: FrontierBlockReward}}
+	if c.ByzantiumBlock != nil {
+		byzantium := &RewardPeriod{Block: c.ByzantiumBlock, Reward: ByzantiumBlockReward}
+		if c.ByzantiumBlock.Sign() == 0 {
+			schedule[0] = byzantium
+		} else {
+			schedule = append(schedule, byzantium)
+		}
+	}
+	return schedule
+}
+
+// RewardPeriodAt returns the reward period the given block number belongs to.
+func (c *ChainConfig) RewardPeriodAt(num *big.Int) *RewardPeriod {
+	schedule := c.RewardSchedule()
+
+	period := schedule[0]
+	for _, next := range schedule[1:] {
+		if !isForked(next.Block, num) {
+			break
+		}
+		period = next
+	}
+	return period
+}
+
+// CheckRewardSchedule verifies that the reward schedule configured for the
+// chain is well formed: ordered by block, starting at genesis, and made up of
+// known formulas and non-negative rewards.
+func (c *ChainConfig) CheckRewardSchedule() error {
+	if c.Yochash == nil || len(c.Yochash.RewardSchedule) == 0 {
+		return nil
+	}
+	var last *big.Int
+	for i, period := range c.Yochash.RewardSchedule {
+		switch {
+		case period.Block == nil:
+			return fmt.Errorf("reward period %d: missing block", i)
+		case last == nil && period.Block.Sign() != 0:
+			return errors.New("reward schedule must start at the genesis block")
+		case last != nil && period.Block.Cmp(last) <= 0:
+			return fmt.Errorf("reward period %d: block %v not after previous period block %v", i, period.Block, last)
+		case period.Reward == nil || period.Reward.Sign() < 0:
+			return fmt.Errorf("reward period %d: invalid reward %v", i, period.Reward)
+		}
+		switch period.UncleFormula() {
+		case UncleRewardStandard, UncleRewardNone:
+		default:
+			return fmt.Errorf("reward period %d: unknown uncle reward formula %q", i, period.UncleRewardFormula)
+		}
+		for _, beneficiary := range period.Beneficiaries {
+			if beneficiary.Reward == nil || beneficiary.Reward.Sign() < 0 {
+				return fmt.Errorf("reward period %d: invalid reward %v for beneficiary %x", i, beneficiary.Reward, beneficiary.Coinbase)
+			}
+		}
+		last = period.Block
+	}
+	return nil
+}
+
+// checkRewardSchedule returns the lowest block at or below head at which the
+// reward periods of the two configurations differ, or nil if they agree on the
+// entire history up to head.
+func checkRewardSchedule(stored, newcfg *ChainConfig, head *big.Int) *big.Int {
+	// Rewards can only change at the boundaries of the individual periods
+	var lowest *big.Int
+	for _, schedule := range [][]*RewardPeriod{stored.RewardSchedule(), newcfg.RewardSchedule()} {
+		for _, period := range schedule {
+			if !isForked(period.Block, head) || (lowest != nil && lowest.Cmp(period.Block) <= 0) {
+				continue
+			}
+			if !rewardPeriodsEqual(stored.RewardPeriodAt(period.Block), newcfg.RewardPeriodAt(period.Block)) {
+				lowest = period.Block
+			}
+		}
+	}
+	return lowest
+}
+
+func rewardPeriodsEqual(x, y *RewardPeriod) bool {
+	if !configNumEqual(x.Reward, y.Reward) || x.UncleFormula() != y.UncleFormula() || len(x.Beneficiaries) != len(y.Beneficiaries) {
+		return false
+	}
+	for i, beneficiary := range x.Beneficiaries {
+		if beneficiary.Coinbase != y.Beneficiaries[i].Coinbase || !configNumEqual(beneficiary.Reward, y.Beneficiaries[i].Reward) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package params
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+)
+
+// Tests that the reward period of a block is looked up correctly, both from an
+// explicit schedule and from the default fork based one.
+func TestRewardPeriodAt(t *testing.T) {
+	var (
+		miner = common.Address{0x01}
+		other = common.Address{0x02}
+
+		superminer = common.HexToAddress("0x30361A617FD009782d573851C55C97A90C91255f")
+		supermined = new(big.Int).Mul(big.NewInt(1000), big.NewInt(YOC))
+	)
+	config := &ChainConfig{Yochash: &YochashConfig{RewardSchedule: []*RewardPeriod{
+		{Block: big.NewInt(0), Reward: big.NewInt(10)},
+		{Block: big.NewInt(5), Reward: big.NewInt(20), Beneficiaries: []*RewardBeneficiary{{Coinbase: miner, Reward: big.NewInt(100)}}},
+		{Block: big.NewInt(8), Reward: big.NewInt(30), UncleRewardFormula: UncleRewardNone},
+	}}}
+	tests := []struct {
+		config   *ChainConfig
+		number   int64
+		coinbase common.Address
+		reward   *big.Int
+		formula  string
+	}{
+		{config, 0, miner, big.NewInt(10), UncleRewardStandard},
+		{config, 4, miner, big.NewInt(10), UncleRewardStandard},
+		{config, 5, miner, big.NewInt(100), UncleRewardStandard},
+		{config, 7, other, big.NewInt(20), UncleRewardStandard},
+		{config, 8, miner, big.NewInt(30), UncleRewardNone},
+		{&ChainConfig{ByzantiumBlock: big.NewInt(5)}, 4, miner, FrontierBlockReward, UncleRewardStandard},
+		{&ChainConfig{ByzantiumBlock: big.NewInt(5)}, 5, miner, ByzantiumBlockReward, UncleRewardStandard},
+		{&ChainConfig{ByzantiumBlock: big.NewInt(0)}, 0, miner, ByzantiumBlockReward, UncleRewardStandard},
+		{&ChainConfig{}, 100, miner, FrontierBlockReward, UncleRewardStandard},
+		{MainnetChainConfig, 3001491, superminer, FrontierBlockReward, UncleRewardStandard},
+		{MainnetChainConfig, 3001492, superminer, supermined, UncleRewardStandard},
+		{MainnetChainConfig, 3001492, miner, FrontierBlockReward, UncleRewardStandard},
+		{MainnetChainConfig, 3500000, superminer, supermined, UncleRewardStandard},
+		{MainnetChainConfig, 3500001, superminer, FrontierBlockReward, UncleRewardStandard},
+	}
+	for i, tt := range tests {
+		period := tt.config.RewardPeriodAt(big.NewInt(tt.number))
+		if reward := period.BlockReward(tt.coinbase); reward.Cmp(tt.reward) != 0 {
+			t.Errorf("test %d: reward mismatch: have %v, want %v", i, reward, tt.reward)
+		}
+		if formula := period.UncleFormula(); formula != tt.formula {
+			t.Errorf("test %d: uncle formula mismatch: have %s, want %s", i, formula, tt.formula)
+		}
+	}
+}
+
+// Tests that malformed reward schedules are rejected.
+func TestCheckRewardSchedule(t *testing.T) {
+	tests := []struct {
+		schedule []*RewardPeriod
+		valid    bool
+	}{
+		{nil, true},
+		{[]*RewardPeriod{{Block: big.NewInt(0), Reward: big.NewInt(1)}, {Block: big.NewInt(10), Reward: big.NewInt(0)}}, true},
+		{[]*RewardPeriod{{Block: big.NewInt(1), Reward: big.NewInt(1)}}, false},                                                // Doesn't start at genesis
+		{[]*RewardPeriod{{Block: big.NewInt(0), Reward: big.NewInt(1)}, {Block: big.NewInt(0), Reward: big.NewInt(1)}}, false}, // Unordered
+		{[]*RewardPeriod{{Block: big.NewInt(0)}}, false},                                                                       // Missing reward
+		{[]*RewardPeriod{{Block: big.NewInt(0), Reward: big.NewInt(-1)}}, false},                                               // Negative reward
+		{[]*RewardPeriod{{Block: big.NewInt(0), Reward: big.NewInt(1), UncleRewardFormula: "double"}}, false},                  // Unknown formula
+		{[]*RewardPeriod{{Block: big.NewInt(0), Reward: big.NewInt(1), Beneficiaries: []*RewardBeneficiary{{}}}}, false},       // Missing beneficiary reward
+	}
+	for i, tt := range tests {
+		config := &ChainConfig{Yochash: &YochashConfig{RewardSchedule: tt.schedule}}
+		if err := config.CheckRewardSchedule(); (err == nil) != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, tt.valid)
+		}
+	}
+}
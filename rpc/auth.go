@@ -0,0 +1,227 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// APIKeyHeader is the HTTP header carrying static API keys.
+	APIKeyHeader = "X-API-Key"
+
+	// jwtExpiryTimeout is the maximum difference between the issuance time of
+	// a JWT token and the time it is presented.
+	jwtExpiryTimeout = 60 * time.Second
+)
+
+var (
+	errMissingAuth = errors.New("missing authentication")
+	errInvalidAuth = errors.New("invalid authentication")
+)
+
+// AuthConfig configures the optional authentication of HTTP and websocket
+// clients. Authentication is disabled if neither field is set.
+type AuthConfig struct {
+	// JWTSecret is the HMAC secret of the HS256 signed JWT bearer tokens
+	// accepted with access to all namespaces.
+	JWTSecret []byte
+
+	// APIKeys maps static API keys to the namespaces they may access, with "*"
+	// granting access to all of them.
+	APIKeys map[string][]string
+}
+
+// unauthorizedError is returned when an authenticated client calls a method
+// outside of the namespaces it may access.
+type unauthorizedError struct{ namespace string }
+
+func (e *unauthorizedError) ErrorCode() int { return -32001 }
+
+func (e *unauthorizedError) Error() string {
+	return fmt.Sprintf("access to namespace %s not allowed", e.namespace)
+}
+
+// namespacesKey is the context key under which the namespaces an authenticated
+// client may access are stored.
+type namespacesKey struct{}
+
+// namespaceAllowed reports whether the client of the context may access the
+// given namespace. Unauthenticated contexts have no restrictions.
+func namespaceAllowed(ctx context.Context, namespace string) bool {
+	allowed, ok := ctx.Value(namespacesKey{}).(map[string]bool)
+	if !ok {
+		return true
+	}
+	return allowed["*"] || allowed[namespace]
+}
+
+// authenticator checks the credentials of HTTP requests.
+type authenticator struct {
+	secret []byte
+	keys   map[string]map[string]bool
+}
+
+// newAuthenticator creates an authenticator for the given config, or nil if
+// authentication is disabled.
+func newAuthenticator(config AuthConfig) *authenticator {
+	if len(config.JWTSecret) == 0 && len(config.APIKeys) == 0 {
+		return nil
+	}
+	a := &authenticator{
+		secret: config.JWTSecret,
+		keys:   make(map[string]map[string]bool),
+	}
+	for key, namespaces := range config.APIKeys {
+		allowed := make(map[string]bool)
+		for _, namespace := range namespaces {
+			allowed[namespace] = true
+		}
+		a.keys[key] = allowed
+	}
+	return a
+}
+
+// authenticate checks the credentials of the request, returning a context
+// carrying the identity of the client and the namespaces it may access.
+func (a *authenticator) authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	var (
+		id         string
+		namespaces map[string]bool
+		err        error
+	)
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		id, namespaces, err = a.checkKey(key)
+	} else {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			return nil, errMissingAuth
+		}
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if len(a.secret) > 0 && strings.Count(token, ".") == 2 {
+			id, namespaces, err = a.checkJWT(token)
+		} else {
+			id, namespaces, err = a.checkKey(token)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	ctx = withClientID(ctx, id)
+	return context.WithValue(ctx, namespacesKey{}, namespaces), nil
+}
+
+// checkKey looks up a static API key in constant time.
+func (a *authenticator) checkKey(key string) (string, map[string]bool, error) {
+	var allowed map[string]bool
+	for candidate, namespaces := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			allowed = namespaces
+		}
+	}
+	if allowed == nil {
+		return "", nil, errInvalidAuth
+	}
+	hash := sha256.Sum256([]byte(key))
+	return "key-" + hex.EncodeToString(hash[:8]), allowed, nil
+}
+
+// checkJWT verifies the signature and freshness of a JWT token.
+func (a *authenticator) checkJWT(token string) (string, map[string]bool, error) {
+	claims := make(jwt.MapClaims)
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return a.secret, nil
+	})
+	if err != nil {
+		return "", nil, errInvalidAuth
+	}
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		return "", nil, errors.New("missing issued-at claim")
+	}
+	if issued := time.Unix(int64(iat), 0); time.Since(issued) > jwtExpiryTimeout || time.Until(issued) > jwtExpiryTimeout {
+		return "", nil, errors.New("stale token")
+	}
+	id := "jwt"
+	if sub, ok := claims["id"].(string); ok && sub != "" {
+		id += "-" + sub
+	}
+	return id, map[string]bool{"*": true}, nil
+}
+
+// newJWTToken creates a fresh HS256 signed JWT token for the given secret.
+func newJWTToken(secret []byte) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iat": time.Now().Unix(),
+	})
+	return token.SignedString(secret)
+}
+
+// ClientOption configures optional aspects of clients dialed over HTTP or
+// websocket.
+type ClientOption func(*clientConfig)
+
+// clientConfig collects the client options.
+type clientConfig struct {
+	headers   http.Header // extra headers sent with every request
+	jwtSecret []byte      // secret to sign a fresh JWT token with for every request
+}
+
+// newClientConfig applies the given options to an empty config.
+func newClientConfig(opts []ClientOption) *clientConfig {
+	cfg := &clientConfig{headers: make(http.Header)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// setHeaders adds the configured headers and credentials to a header set.
+func (cfg *clientConfig) setHeaders(headers http.Header) error {
+	for key, values := range cfg.headers {
+		headers[key] = values
+	}
+	if len(cfg.jwtSecret) > 0 {
+		token, err := newJWTToken(cfg.jwtSecret)
+		if err != nil {
+			return err
+		}
+		headers.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// WithHeader sets an extra HTTP header sent along with every request, or with
+// the handshake of websocket connections.
+func WithHeader(key, value string) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.headers.Set(key, value)
+	}
+}
+
+// WithAPIKey authenticates the client with a static API key.
+func WithAPIKey(key string) ClientOption {
+	return WithHeader(APIKeyHeader, key)
+}
+
+// WithJWTAuth authenticates the client with JWT tokens signed with the given
+// secret. A fresh token is created for every request and connection.
+func WithJWTAuth(secret []byte) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.jwtSecret = secret
+	}
+}
@@ -0,0 +1,141 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+var testJWTSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// newAuthTestServer creates an HTTP test server around a server requiring the
+// given authentication.
+func newAuthTestServer(config AuthConfig) (*Server, *httptest.Server) {
+	server := newTestServer("service", new(Service))
+	server.SetAuth(config)
+	return server, httptest.NewServer(server)
+}
+
+// postAuth sends a JSON-RPC request with the given extra headers, returning
+// the HTTP status code.
+func postAuth(t *testing.T, url string, headers map[string]string) int {
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"rpc_modules"}`))
+	req.Header.Set("content-type", contentType)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestHTTPAuth(t *testing.T) {
+	server, hs := newAuthTestServer(AuthConfig{
+		JWTSecret: testJWTSecret,
+		APIKeys:   map[string][]string{"secret-key": {"*"}},
+	})
+	defer server.Stop()
+	defer hs.Close()
+
+	sign := func(iat time.Time, secret []byte) string {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iat": iat.Unix()}).SignedString(secret)
+		return "Bearer " + token
+	}
+	fresh, _ := newJWTToken(testJWTSecret)
+
+	tests := []struct {
+		headers map[string]string
+		status  int
+	}{
+		{nil, http.StatusUnauthorized},
+		{map[string]string{"Authorization": "Bearer " + fresh}, http.StatusOK},
+		{map[string]string{"Authorization": sign(time.Now().Add(-2*jwtExpiryTimeout), testJWTSecret)}, http.StatusUnauthorized},
+		{map[string]string{"Authorization": sign(time.Now().Add(2*jwtExpiryTimeout), testJWTSecret)}, http.StatusUnauthorized},
+		{map[string]string{"Authorization": sign(time.Now(), []byte("wrong secret"))}, http.StatusUnauthorized},
+		{map[string]string{APIKeyHeader: "secret-key"}, http.StatusOK},
+		{map[string]string{"Authorization": "Bearer secret-key"}, http.StatusOK},
+		{map[string]string{APIKeyHeader: "wrong-key"}, http.StatusUnauthorized},
+	}
+	for i, test := range tests {
+		if status := postAuth(t, hs.URL, test.headers); status != test.status {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, status, test.status)
+		}
+	}
+}
+
+func TestAPIKeyNamespaces(t *testing.T) {
+	server, hs := newAuthTestServer(AuthConfig{
+		APIKeys: map[string][]string{"limited": {"other"}, "full": {"service"}},
+	})
+	defer server.Stop()
+	defer hs.Close()
+
+	call := func(key string) *jsonError {
+		client, err := DialHTTP(hs.URL, WithAPIKey(key))
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		defer client.Close()
+
+		var result Result
+		err = client.Call(&result, "service_echo", "a", 1, &Args{"x"})
+		if err == nil {
+			return nil
+		}
+		if jerr, ok := err.(*jsonError); ok {
+			return jerr
+		}
+		t.Fatalf("unexpected error: %v", err)
+		return nil
+	}
+	if err := call("full"); err != nil {
+		t.Fatalf("allowed namespace rejected: %v", err)
+	}
+	if err := call("limited"); err == nil || err.Code != -32001 {
+		t.Fatalf("disallowed namespace not rejected: %v", err)
+	}
+	// The metadata namespace is open to all authenticated clients
+	client, _ := DialHTTP(hs.URL, WithAPIKey("limited"))
+	defer client.Close()
+	if _, err := client.SupportedModules(); err != nil {
+		t.Fatalf("metadata namespace rejected: %v", err)
+	}
+}
+
+func TestWebsocketAuth(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	server.SetAuth(AuthConfig{JWTSecret: testJWTSecret})
+	defer server.Stop()
+
+	hs := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer hs.Close()
+	url := "ws" + strings.TrimPrefix(hs.URL, "http")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := DialWebsocket(ctx, url, ""); err == nil {
+		t.Fatalf("unauthenticated connection accepted")
+	}
+	client, err := DialWebsocket(ctx, url, "", WithJWTAuth(testJWTSecret))
+	if err != nil {
+		t.Fatalf("authenticated connection rejected: %v", err)
+	}
+	defer client.Close()
+
+	var result Result
+	if err := client.Call(&result, "service_echo", "a", 1, &Args{"x"}); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+}
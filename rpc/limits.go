@@ -0,0 +1,245 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yocoin15/Yocoin_Sources/metrics"
+)
+
+// maxIdleBuckets is the number of per-client rate limit buckets above which
+// the idle (fully refilled) ones are dropped.
+const maxIdleBuckets = 4096
+
+var (
+	batchThrottledMeter       = metrics.NewRegisteredMeter("rpc/throttled/batch", nil)
+	responseThrottledMeter    = metrics.NewRegisteredMeter("rpc/throttled/response", nil)
+	rateThrottledMeter        = metrics.NewRegisteredMeter("rpc/throttled/rate", nil)
+	concurrencyThrottledMeter = metrics.NewRegisteredMeter("rpc/throttled/concurrency", nil)
+)
+
+// Limits configures the resource limits the RPC server enforces on incoming
+// requests. Zero values disable the corresponding limit.
+type Limits struct {
+	// BatchItems is the maximum number of requests accepted in a single batch.
+	BatchItems int `toml:",omitempty"`
+
+	// ResponseSize is the maximum size in bytes of the (batch) response to a
+	// single read from the connection.
+	ResponseSize int `toml:",omitempty"`
+
+	// RateLimits is the number of requests per second a single client may issue
+	// to a method namespace. The "*" entry applies to all namespaces that don't
+	// have their own. Clients are identified by their remote IP address, or by
+	// their API key when authenticated.
+	RateLimits map[string]float64 `toml:",omitempty"`
+
+	// RateBurst is the number of requests a client may issue to a namespace in
+	// a burst. It defaults to the namespace rate, rounded up.
+	RateBurst int `toml:",omitempty"`
+
+	// HeavyMethods lists the expensive methods whose concurrent execution is
+	// capped. A trailing '*' matches any method with the given prefix.
+	HeavyMethods []string `toml:",omitempty"`
+
+	// HeavyConcurrency is the maximum number of heavy methods executing at once.
+	HeavyConcurrency int `toml:",omitempty"`
+}
+
+// DefaultLimits contains the default limits applied to the public endpoints.
+// Every limit is off until configured, only the heavy methods are preset for
+// the concurrency cap to apply to.
+var DefaultLimits = Limits{
+	HeavyMethods: []string{"eth_getLogs", "yoc_getLogs", "debug_trace*", "trace_*"},
+}
+
+// limitExceededError is returned when a request is throttled.
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+func (e *limitExceededError) Error() string { return e.message }
+
+// responseTooLargeError is returned when a response exceeds the size limit.
+type responseTooLargeError struct{ limit int }
+
+func (e *responseTooLargeError) ErrorCode() int { return -32003 }
+
+func (e *responseTooLargeError) Error() string {
+	return fmt.Sprintf("response too large (limit %d bytes)", e.limit)
+}
+
+// clientIDKey is the context key under which the identity of the remote client
+// is stored for rate limiting.
+type clientIDKey struct{}
+
+// withClientID returns a copy of the context carrying the client identity.
+func withClientID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, id)
+}
+
+// clientID returns the client identity carried by the context, if any.
+func clientID(ctx context.Context) string {
+	id, _ := ctx.Value(clientIDKey{}).(string)
+	return id
+}
+
+// remoteIP strips the port from a remote address, leaving the host.
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// tokenBucket is a simple token bucket refilling at a constant rate.
+type tokenBucket struct {
+	rate   float64   // tokens added per second
+	burst  float64   // maximum number of tokens
+	tokens float64   // tokens currently available
+	last   time.Time // last time the bucket was refilled
+}
+
+// take refills the bucket and tries to consume a single token from it.
+func (b *tokenBucket) take(now time.Time) bool {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// full reports whether the bucket would be completely refilled at the given
+// time, i.e. whether it is indistinguishable from a fresh one.
+func (b *tokenBucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+// limiter enforces the configured limits on the requests of a server.
+type limiter struct {
+	limits Limits
+	heavy  chan struct{} // semaphore of heavy method slots, nil if unlimited
+
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket // rate limit buckets keyed by client and namespace
+}
+
+// newLimiter creates a limiter enforcing the given limits.
+func newLimiter(limits Limits) *limiter {
+	l := &limiter{
+		limits:  limits,
+		buckets: make(map[string]*tokenBucket),
+	}
+	if limits.HeavyConcurrency > 0 && len(limits.HeavyMethods) > 0 {
+		l.heavy = make(chan struct{}, limits.HeavyConcurrency)
+	}
+	return l
+}
+
+// checkBatch returns an error if the batch has too many items.
+func (l *limiter) checkBatch(items int) Error {
+	if l == nil || l.limits.BatchItems == 0 || items <= l.limits.BatchItems {
+		return nil
+	}
+	batchThrottledMeter.Mark(1)
+	return &invalidRequestError{fmt.Sprintf("batch too large (%d>%d)", items, l.limits.BatchItems)}
+}
+
+// checkResponse returns an error if a response of the given size would push
+// the total size of the responses written so far over the limit.
+func (l *limiter) checkResponse(total int) Error {
+	if l == nil || l.limits.ResponseSize == 0 || total <= l.limits.ResponseSize {
+		return nil
+	}
+	responseThrottledMeter.Mark(1)
+	return &responseTooLargeError{l.limits.ResponseSize}
+}
+
+// checkResponses reports whether response sizes need to be measured at all.
+func (l *limiter) checkResponses() bool {
+	return l != nil && l.limits.ResponseSize > 0
+}
+
+// acquire checks the rate limit of the client for the namespace of the method
+// and reserves a slot if it's a heavy one. The returned function releases the
+// slot and must be called once the method returns.
+func (l *limiter) acquire(ctx context.Context, namespace, method string) (func(), Error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if !l.allow(clientID(ctx), namespace) {
+		rateThrottledMeter.Mark(1)
+		return nil, &limitExceededError{fmt.Sprintf("rate limit exceeded for namespace %s", namespace)}
+	}
+	if l.heavy == nil || !l.isHeavy(namespace+serviceMethodSeparator+method) {
+		return func() {}, nil
+	}
+	select {
+	case l.heavy <- struct{}{}:
+		return func() { <-l.heavy }, nil
+	default:
+		concurrencyThrottledMeter.Mark(1)
+		return nil, &limitExceededError{fmt.Sprintf("too many concurrent %s%s%s requests", namespace, serviceMethodSeparator, method)}
+	}
+}
+
+// allow consumes a token from the bucket of the client for the namespace,
+// returning false if none is available.
+func (l *limiter) allow(client, namespace string) bool {
+	rate, ok := l.limits.RateLimits[namespace]
+	if !ok {
+		rate = l.limits.RateLimits["*"]
+	}
+	if rate <= 0 {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	key := client + "/" + namespace
+
+	bucket := l.buckets[key]
+	if bucket == nil {
+		if len(l.buckets) >= maxIdleBuckets {
+			for key, bucket := range l.buckets {
+				if bucket.full(now) {
+					delete(l.buckets, key)
+				}
+			}
+		}
+		burst := float64(l.limits.RateBurst)
+		if burst <= 0 {
+			burst = math.Ceil(rate)
+		}
+		bucket = &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+		l.buckets[key] = bucket
+	}
+	return bucket.take(now)
+}
+
+// isHeavy reports whether the fully qualified method is a heavy one.
+func (l *limiter) isHeavy(name string) bool {
+	for _, pattern := range l.limits.HeavyMethods {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
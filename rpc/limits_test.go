@@ -0,0 +1,182 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postRaw sends a raw JSON-RPC message to the HTTP server and decodes the
+// response into result, which is reset first.
+func postRaw(t *testing.T, url string, msg string, result interface{}) {
+	switch r := result.(type) {
+	case *jsonrpcMessage:
+		*r = jsonrpcMessage{}
+	case *[]jsonrpcMessage:
+		*r = nil
+	}
+	resp, err := http.Post(url, contentType, strings.NewReader(msg))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
+
+// newLimitedTestServer creates an HTTP test server around a server with the
+// given limits.
+func newLimitedTestServer(limits Limits) (*Server, *httptest.Server) {
+	server := newTestServer("service", new(Service))
+	server.SetLimits(limits)
+	return server, httptest.NewServer(server)
+}
+
+func TestBatchLimit(t *testing.T) {
+	server, hs := newLimitedTestServer(Limits{BatchItems: 2})
+	defer server.Stop()
+	defer hs.Close()
+
+	// A batch within the limit is executed
+	var resps []jsonrpcMessage
+	postRaw(t, hs.URL, `[{"jsonrpc":"2.0","id":1,"method":"rpc_modules"},{"jsonrpc":"2.0","id":2,"method":"rpc_modules"}]`, &resps)
+	if len(resps) != 2 || resps[0].Error != nil || resps[1].Error != nil {
+		t.Fatalf("batch within limit failed: %+v", resps)
+	}
+	// An oversized batch is rejected as a whole
+	var resp jsonrpcMessage
+	postRaw(t, hs.URL, `[{"jsonrpc":"2.0","id":1,"method":"rpc_modules"},{"jsonrpc":"2.0","id":2,"method":"rpc_modules"},{"jsonrpc":"2.0","id":3,"method":"rpc_modules"}]`, &resp)
+	if resp.Error == nil || resp.Error.Code != -32600 {
+		t.Fatalf("oversized batch not rejected: %+v", resp)
+	}
+}
+
+func TestResponseLimit(t *testing.T) {
+	server, hs := newLimitedTestServer(Limits{ResponseSize: 200})
+	defer server.Stop()
+	defer hs.Close()
+
+	var resp jsonrpcMessage
+	postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["short",1,{"S":"x"}]}`, &resp)
+	if resp.Error != nil {
+		t.Fatalf("small response rejected: %v", resp.Error)
+	}
+	long := strings.Repeat("x", 300)
+	postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["`+long+`",1,{"S":"x"}]}`, &resp)
+	if resp.Error == nil || resp.Error.Code != -32003 {
+		t.Fatalf("oversized response not rejected: %+v", resp)
+	}
+	// Batches fail from the request which pushes them over the limit
+	var resps []jsonrpcMessage
+	postRaw(t, hs.URL, `[{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["short",1,{"S":"x"}]},{"jsonrpc":"2.0","id":2,"method":"service_echo","params":["`+long+`",1,{"S":"x"}]},{"jsonrpc":"2.0","id":3,"method":"rpc_modules"}]`, &resps)
+	if len(resps) != 3 {
+		t.Fatalf("batch response count mismatch: have %d, want 3", len(resps))
+	}
+	if resps[0].Error != nil {
+		t.Errorf("first batch response rejected: %v", resps[0].Error)
+	}
+	for i := 1; i < 3; i++ {
+		if resps[i].Error == nil || resps[i].Error.Code != -32003 {
+			t.Errorf("batch response %d not rejected: %+v", i, resps[i])
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server, hs := newLimitedTestServer(Limits{RateLimits: map[string]float64{"service": 1}})
+	defer server.Stop()
+	defer hs.Close()
+
+	var resp jsonrpcMessage
+	postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["a",1,{"S":"x"}]}`, &resp)
+	if resp.Error != nil {
+		t.Fatalf("first request throttled: %v", resp.Error)
+	}
+	postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":2,"method":"service_echo","params":["a",1,{"S":"x"}]}`, &resp)
+	if resp.Error == nil || resp.Error.Code != -32005 {
+		t.Fatalf("second request not throttled: %+v", resp)
+	}
+	// Other namespaces have their own (unlimited) budget
+	postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":3,"method":"rpc_modules"}`, &resp)
+	if resp.Error != nil {
+		t.Fatalf("unrelated namespace throttled: %v", resp.Error)
+	}
+}
+
+func TestHeavyMethodConcurrency(t *testing.T) {
+	server, hs := newLimitedTestServer(Limits{HeavyMethods: []string{"service_sle*"}, HeavyConcurrency: 1})
+	defer server.Stop()
+	defer hs.Close()
+
+	done := make(chan *jsonrpcMessage)
+	go func() {
+		var resp jsonrpcMessage
+		postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":1,"method":"service_sleep","params":[500000000]}`, &resp)
+		done <- &resp
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	var resp jsonrpcMessage
+	postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":2,"method":"service_sleep","params":[1]}`, &resp)
+	if resp.Error == nil || resp.Error.Code != -32005 {
+		t.Fatalf("concurrent heavy request not throttled: %+v", resp)
+	}
+	postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":3,"method":"service_echo","params":["a",1,{"S":"x"}]}`, &resp)
+	if resp.Error != nil {
+		t.Fatalf("light request throttled: %v", resp.Error)
+	}
+	if first := <-done; first.Error != nil {
+		t.Fatalf("first heavy request failed: %v", first.Error)
+	}
+	// Once the slot is released, heavy requests are accepted again
+	postRaw(t, hs.URL, `{"jsonrpc":"2.0","id":4,"method":"service_sleep","params":[1]}`, &resp)
+	if resp.Error != nil {
+		t.Fatalf("heavy request throttled after release: %v", resp.Error)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := &tokenBucket{rate: 2, burst: 2, tokens: 2, last: now}
+
+	if !bucket.take(now) || !bucket.take(now) {
+		t.Fatalf("burst not available")
+	}
+	if bucket.take(now) {
+		t.Fatalf("token taken from empty bucket")
+	}
+	if bucket.full(now.Add(500 * time.Millisecond)) {
+		t.Fatalf("half refilled bucket reported full")
+	}
+	if !bucket.take(now.Add(500 * time.Millisecond)) {
+		t.Fatalf("refilled token not available")
+	}
+	if !bucket.full(now.Add(2 * time.Second)) {
+		t.Fatalf("refilled bucket not reported full")
+	}
+}
+
+// Tests that the default limits cap the log and trace methods only.
+func TestDefaultHeavyMethods(t *testing.T) {
+	l := newLimiter(DefaultLimits)
+	for name, heavy := range map[string]bool{
+		"eth_getLogs":            true,
+		"debug_traceTransaction": true,
+		"trace_filter":           true,
+		"trace_block":            true,
+		"eth_getBalance":         false,
+		"debug_getBadBlocks":     false,
+	} {
+		if have := l.isHeavy(name); have != heavy {
+			t.Errorf("%s: heavy mismatch: have %v, want %v", name, have, heavy)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -103,6 +104,18 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 	return nil
 }
 
+// SetLimits configures the resource limits enforced on incoming requests. It
+// must be called before the server starts serving requests.
+func (s *Server) SetLimits(limits Limits) {
+	s.limiter = newLimiter(limits)
+}
+
+// SetAuth configures the authentication required from HTTP and websocket
+// clients. It must be called before the server starts serving requests.
+func (s *Server) SetAuth(config AuthConfig) {
+	s.auth = newAuthenticator(config)
+}
+
 // serveRequest will reads requests from the codec, calls the RPC callback and
 // writes the response to the given codec.
 //
@@ -171,6 +184,16 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 			}
 			return nil
 		}
+		// Reject oversized batches as a whole without executing any of them
+		if batch {
+			if err := s.limiter.checkBatch(len(reqs)); err != nil {
+				codec.Write(codec.CreateErrorResponse(nil, err))
+				if singleShot {
+					return nil
+				}
+				continue
+			}
+		}
 		// If a single shot request is executing, run and return immediately
 		if singleShot {
 			if batch {
@@ -261,6 +284,17 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, &invalidParamsError{"Expected subscription id as first argument"}), nil
 	}
 
+	// reject methods outside of the namespaces the client may access
+	if req.svcname != MetadataApi && !namespaceAllowed(ctx, req.svcname) {
+		return codec.CreateErrorResponse(&req.id, &unauthorizedError{req.svcname}), nil
+	}
+	// enforce the rate and concurrency limits of the method
+	release, err := s.limiter.acquire(ctx, req.svcname, formatName(req.callb.method.Name))
+	if err != nil {
+		return codec.CreateErrorResponse(&req.id, err), nil
+	}
+	defer release()
+
 	if req.callb.isSubscribe {
 		subid, err := s.createSubscription(ctx, codec, req)
 		if err != nil {
@@ -316,6 +350,13 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 	} else {
 		response, callback = s.handle(ctx, codec, req)
 	}
+	if s.limiter.checkResponses() {
+		var size int
+		response, size = encodeResponse(response)
+		if err := s.limiter.checkResponse(size); err != nil {
+			response, callback = codec.CreateErrorResponse(&req.id, err), nil
+		}
+	}
 
 	if err := codec.Write(response); err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
@@ -332,16 +373,32 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 // It will only write the response back when the last request is processed.
 func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*serverRequest) {
 	responses := make([]interface{}, len(requests))
-	var callbacks []func()
+	var (
+		callbacks []func()
+		total     int
+	)
 	for i, req := range requests {
+		var callback func()
 		if req.err != nil {
 			responses[i] = codec.CreateErrorResponse(&req.id, req.err)
 		} else {
-			var callback func()
-			if responses[i], callback = s.handle(ctx, codec, req); callback != nil {
-				callbacks = append(callbacks, callback)
+			responses[i], callback = s.handle(ctx, codec, req)
+		}
+		// once the batch response grows too large, fail all remaining requests
+		if s.limiter.checkResponses() {
+			var size int
+			responses[i], size = encodeResponse(responses[i])
+			total += size
+			if err := s.limiter.checkResponse(total); err != nil {
+				for j := i; j < len(requests); j++ {
+					responses[j] = codec.CreateErrorResponse(&requests[j].id, err)
+				}
+				break
 			}
 		}
+		if callback != nil {
+			callbacks = append(callbacks, callback)
+		}
 	}
 
 	if err := codec.Write(responses); err != nil {
@@ -355,6 +412,18 @@ func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*s
 	}
 }
 
+// encodeResponse encodes the response ahead of writing it, returning the encoded
+// form to write instead and its size, so the size limit can be checked without
+// encoding the response twice. Responses failing to encode are returned as is
+// for the codec to report the error when writing them.
+func encodeResponse(response interface{}) (interface{}, int) {
+	blob, err := json.Marshal(response)
+	if err != nil {
+		return response, 0
+	}
+	return json.RawMessage(blob), len(blob)
+}
+
 // readRequest requests the next (batch) request from the codec. It will return the collection
 // of requests, an indication if the request was a batch, the invalid request identifier and an
 // error when the request could not be read/parsed.
@@ -4,12 +4,15 @@
 package rpc
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"reflect"
 	"strings"
 	"sync"
 
+	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
 	mapset "github.com/deckarep/golang-set"
 )
@@ -57,6 +60,8 @@ type subscriptions map[string]*callback  // collection of subscription callbacks
 // Server represents a RPC server
 type Server struct {
 	services serviceRegistry
+	limiter  *limiter       // resource limits enforced on requests, nil if unlimited
+	auth     *authenticator // credential checks of HTTP clients, nil if open
 
 	run      int32
 	codecsMu sync.Mutex
@@ -150,3 +155,111 @@ func (bn *BlockNumber) UnmarshalJSON(data []byte) error {
 func (bn BlockNumber) Int64() int64 {
 	return (int64)(bn)
 }
+
+// encodeBlockNumber returns the JSON argument representation of a block number.
+func encodeBlockNumber(bn BlockNumber) string {
+	switch bn {
+	case EarliestBlockNumber:
+		return "earliest"
+	case LatestBlockNumber:
+		return "latest"
+	case PendingBlockNumber:
+		return "pending"
+	}
+	return hexutil.EncodeUint64(uint64(bn))
+}
+
+// BlockNumberOrHash identifies a block either by its number (or one of the
+// named tags) or by its hash, as specified by EIP-1898. Exactly one of the two
+// fields is set. If RequireCanonical is set, a block selected by hash must also
+// be part of the canonical chain.
+type BlockNumberOrHash struct {
+	BlockNumber      *BlockNumber `json:"blockNumber,omitempty"`
+	BlockHash        *common.Hash `json:"blockHash,omitempty"`
+	RequireCanonical bool         `json:"requireCanonical,omitempty"`
+}
+
+// UnmarshalJSON parses the given JSON fragment into a BlockNumberOrHash. It
+// supports:
+// - a block number or "latest", "earliest" or "pending" as string arguments
+// - a 32 byte block hash as a string argument
+// - an object with either a blockNumber or a blockHash and requireCanonical field
+func (bnh *BlockNumberOrHash) UnmarshalJSON(data []byte) error {
+	type erased BlockNumberOrHash
+	e := erased{}
+	if err := json.Unmarshal(data, &e); err == nil {
+		if (e.BlockNumber == nil) == (e.BlockHash == nil) {
+			return errors.New("exactly one of blockNumber or blockHash must be specified")
+		}
+		bnh.BlockNumber, bnh.BlockHash, bnh.RequireCanonical = e.BlockNumber, e.BlockHash, e.RequireCanonical
+		return nil
+	}
+	var input string
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+	if len(input) == 2+2*common.HashLength {
+		hash := new(common.Hash)
+		if err := hash.UnmarshalText([]byte(input)); err != nil {
+			return err
+		}
+		bnh.BlockNumber, bnh.BlockHash, bnh.RequireCanonical = nil, hash, false
+		return nil
+	}
+	number := new(BlockNumber)
+	if err := number.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	bnh.BlockNumber, bnh.BlockHash, bnh.RequireCanonical = number, nil, false
+	return nil
+}
+
+// MarshalJSON encodes the block selector as a plain JSON string argument, or
+// as an object if a hash selector requires the block to be canonical.
+func (bnh BlockNumberOrHash) MarshalJSON() ([]byte, error) {
+	if bnh.BlockHash != nil && bnh.RequireCanonical {
+		return json.Marshal(map[string]interface{}{
+			"blockHash":        bnh.BlockHash,
+			"requireCanonical": true,
+		})
+	}
+	return json.Marshal(bnh.String())
+}
+
+// Number returns the block number if the block is selected by number.
+func (bnh *BlockNumberOrHash) Number() (BlockNumber, bool) {
+	if bnh.BlockNumber != nil {
+		return *bnh.BlockNumber, true
+	}
+	return BlockNumber(0), false
+}
+
+// Hash returns the block hash if the block is selected by hash.
+func (bnh *BlockNumberOrHash) Hash() (common.Hash, bool) {
+	if bnh.BlockHash != nil {
+		return *bnh.BlockHash, true
+	}
+	return common.Hash{}, false
+}
+
+// String returns the JSON argument representation of the block selector.
+func (bnh BlockNumberOrHash) String() string {
+	if bnh.BlockHash != nil {
+		return bnh.BlockHash.Hex()
+	}
+	if bnh.BlockNumber != nil {
+		return encodeBlockNumber(*bnh.BlockNumber)
+	}
+	return "nil"
+}
+
+// BlockNumberOrHashWithNumber creates a block selector for the given number.
+func BlockNumberOrHashWithNumber(blockNr BlockNumber) BlockNumberOrHash {
+	return BlockNumberOrHash{BlockNumber: &blockNr}
+}
+
+// BlockNumberOrHashWithHash creates a block selector for the given hash, which
+// optionally must be part of the canonical chain.
+func BlockNumberOrHashWithHash(hash common.Hash, canonical bool) BlockNumberOrHash {
+	return BlockNumberOrHash{BlockHash: &hash, RequireCanonical: canonical}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/common/math"
 )
 
@@ -51,3 +52,59 @@ func TestBlockNumberJSONUnmarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockNumberOrHashJSONUnmarshal(t *testing.T) {
+	hash := common.HexToHash("0x0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20")
+	tests := []struct {
+		input    string
+		mustFail bool
+		expected BlockNumberOrHash
+	}{
+		0:  {`"0x"`, true, BlockNumberOrHash{}},
+		1:  {`"0x0"`, false, BlockNumberOrHashWithNumber(0)},
+		2:  {`"0x12"`, false, BlockNumberOrHashWithNumber(18)},
+		3:  {`"pending"`, false, BlockNumberOrHashWithNumber(PendingBlockNumber)},
+		4:  {`"latest"`, false, BlockNumberOrHashWithNumber(LatestBlockNumber)},
+		5:  {`"earliest"`, false, BlockNumberOrHashWithNumber(EarliestBlockNumber)},
+		6:  {`"` + hash.Hex() + `"`, false, BlockNumberOrHashWithHash(hash, false)},
+		7:  {`"0x0102"`, true, BlockNumberOrHash{}},
+		8:  {`{"blockNumber":"0x12"}`, false, BlockNumberOrHashWithNumber(18)},
+		9:  {`{"blockNumber":"latest"}`, false, BlockNumberOrHashWithNumber(LatestBlockNumber)},
+		10: {`{"blockHash":"` + hash.Hex() + `"}`, false, BlockNumberOrHashWithHash(hash, false)},
+		11: {`{"blockNumber":"0x12","blockHash":"` + hash.Hex() + `"}`, true, BlockNumberOrHash{}},
+		12: {`{}`, true, BlockNumberOrHash{}},
+		13: {`"0x01020304050607080910111213141516171819202122232425262728293031zz"`, true, BlockNumberOrHash{}},
+		14: {`someString`, true, BlockNumberOrHash{}},
+		15: {``, true, BlockNumberOrHash{}},
+		16: {`{"blockHash":"` + hash.Hex() + `","requireCanonical":true}`, false, BlockNumberOrHashWithHash(hash, true)},
+		17: {`{"blockHash":"` + hash.Hex() + `","requireCanonical":false}`, false, BlockNumberOrHashWithHash(hash, false)},
+	}
+
+	for i, test := range tests {
+		var bnh BlockNumberOrHash
+		err := json.Unmarshal([]byte(test.input), &bnh)
+		if test.mustFail && err == nil {
+			t.Errorf("Test %d should fail", i)
+			continue
+		}
+		if !test.mustFail && err != nil {
+			t.Errorf("Test %d should pass but got err: %v", i, err)
+			continue
+		}
+		if test.mustFail {
+			continue
+		}
+		if bnh.String() != test.expected.String() || bnh.RequireCanonical != test.expected.RequireCanonical {
+			t.Errorf("Test %d got unexpected value, want %v, got %v", i, test.expected, bnh)
+		}
+		// Ensure the selector round-trips through its string encoding
+		var dec BlockNumberOrHash
+		if blob, err := json.Marshal(bnh); err != nil {
+			t.Errorf("Test %d failed to encode: %v", i, err)
+		} else if err := json.Unmarshal(blob, &dec); err != nil {
+			t.Errorf("Test %d failed to decode %s: %v", i, blob, err)
+		} else if dec.String() != bnh.String() || dec.RequireCanonical != bnh.RequireCanonical {
+			t.Errorf("Test %d round trip mismatch: have %v, want %v", i, dec, bnh)
+		}
+	}
+}
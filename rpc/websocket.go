@@ -44,7 +44,7 @@ var websocketJSONCodec = websocket.Codec{
 // To allow connections with any origin, pass "*".
 func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 	return websocket.Server{
-		Handshake: wsHandshakeValidator(allowedOrigins),
+		Handshake: wsHandshakeValidator(allowedOrigins, srv.auth),
 		Handler: func(conn *websocket.Conn) {
 			// Create a custom encode/decode pair to enforce payload size and number encoding
 			conn.MaxPayloadBytes = maxRequestContentLength
@@ -55,7 +55,18 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			codec := NewCodec(conn, encoder, decoder)
+			defer codec.Close()
+
+			ctx := withClientID(context.Background(), remoteIP(conn.Request().RemoteAddr))
+			if srv.auth != nil {
+				// The handshake already rejected unauthenticated clients
+				var err error
+				if ctx, err = srv.auth.authenticate(ctx, conn.Request()); err != nil {
+					return
+				}
+			}
+			srv.serveRequest(ctx, codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
@@ -67,10 +78,10 @@ func NewWSServer(allowedOrigins []string, srv *Server) *http.Server {
 	return &http.Server{Handler: srv.WebsocketHandler(allowedOrigins)}
 }
 
-// wsHandshakeValidator returns a handler that verifies the origin during the
-// websocket upgrade process. When a '*' is specified as an allowed origins all
-// connections are accepted.
-func wsHandshakeValidator(allowedOrigins []string) func(*websocket.Config, *http.Request) error {
+// wsHandshakeValidator returns a handler that verifies the origin and, if auth
+// is set, the credentials during the websocket upgrade process. When a '*' is
+// specified as an allowed origins all origins are accepted.
+func wsHandshakeValidator(allowedOrigins []string, auth *authenticator) func(*websocket.Config, *http.Request) error {
 	origins := mapset.NewSet()
 	allowAllOrigins := false
 
@@ -95,11 +106,17 @@ func wsHandshakeValidator(allowedOrigins []string) func(*websocket.Config, *http
 
 	f := func(cfg *websocket.Config, req *http.Request) error {
 		origin := strings.ToLower(req.Header.Get("Origin"))
-		if allowAllOrigins || origins.Contains(origin) {
-			return nil
+		if !allowAllOrigins && !origins.Contains(origin) {
+			log.Warn(fmt.Sprintf("origin '%s' not allowed on WS-RPC interface\n", origin))
+			return fmt.Errorf("origin %s not allowed", origin)
+		}
+		if auth != nil {
+			if _, err := auth.authenticate(req.Context(), req); err != nil {
+				log.Warn("Unauthenticated WS-RPC connection rejected", "remote", req.RemoteAddr, "err", err)
+				return err
+			}
 		}
-		log.Warn(fmt.Sprintf("origin '%s' not allowed on WS-RPC interface\n", origin))
-		return fmt.Errorf("origin %s not allowed", origin)
+		return nil
 	}
 
 	return f
@@ -109,8 +126,9 @@ func wsHandshakeValidator(allowedOrigins []string) func(*websocket.Config, *http
 // that is listening on the given endpoint.
 //
 // The context is used for the initial connection establishment. It does not
-// affect subsequent interactions with the client.
-func DialWebsocket(ctx context.Context, endpoint, origin string) (*Client, error) {
+// affect subsequent interactions with the client. The options may add extra
+// headers or credentials to the handshake of every (re)connection.
+func DialWebsocket(ctx context.Context, endpoint, origin string, opts ...ClientOption) (*Client, error) {
 	if origin == "" {
 		var err error
 		if origin, err = os.Hostname(); err != nil {
@@ -127,8 +145,14 @@ func DialWebsocket(ctx context.Context, endpoint, origin string) (*Client, error
 		return nil, err
 	}
 
+	options := newClientConfig(opts)
 	return newClient(ctx, func(ctx context.Context) (net.Conn, error) {
-		return wsDialContext(ctx, config)
+		dialConfig := *config
+		dialConfig.Header = make(http.Header)
+		if err := options.setHeaders(dialConfig.Header); err != nil {
+			return nil, err
+		}
+		return wsDialContext(ctx, &dialConfig)
 	})
 }
 
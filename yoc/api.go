@@ -70,6 +70,16 @@ func (api *PublicMinerAPI) Mining() bool {
 	return api.e.IsMining()
 }
 
+// RewardSchedule returns the block reward schedule of the chain, allowing pools
+// to compute the expected payouts of the blocks they mine. Chains not sealed by
+// proof-of-work pay no block rewards and have an empty schedule.
+func (api *PublicMinerAPI) RewardSchedule() []*params.RewardPeriod {
+	if api.e.chainConfig.Clique != nil {
+		return []*params.RewardPeriod{}
+	}
+	return api.e.chainConfig.RewardSchedule()
+}
+
 // PrivateMinerAPI provides private RPC methods to control the miner.
 // These methods can be abused by external users and must be considered insecure for use by untrusted users.
 type PrivateMinerAPI struct {
@@ -250,6 +260,13 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// AddressRules returns the address rules transactions are filtered by in the
+// upcoming block, both the consensus and the locally configured ones.
+func (api *PrivateAdminAPI) AddressRules() []*params.AddressRule {
+	next := new(big.Int).Add(api.yoc.BlockChain().CurrentBlock().Number(), common.Big1)
+	return api.yoc.TxPool().AddressPolicy().Rules(next)
+}
+
 // PublicDebugAPI is the collection of YoCoin full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
@@ -338,6 +355,31 @@ func (api *PrivateDebugAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs,
 	return results, nil
 }
 
+// IrregularStateChanges returns the audit records of all the balance rewrites the
+// node applied outside of transaction execution to the canonical blocks between
+// from and to (inclusive). Without an end block, the range ends at the head.
+func (api *PrivateDebugAPI) IrregularStateChanges(from rpc.BlockNumber, to *rpc.BlockNumber) ([]*types.BalanceRewrite, error) {
+	head := api.yoc.blockchain.CurrentBlock().NumberU64()
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number < 0 {
+			return head // Latest and pending both mean the current head
+		}
+		return uint64(number)
+	}
+	start, end := resolve(from), head
+	if to != nil {
+		end = resolve(*to)
+	}
+	if start > end {
+		return nil, fmt.Errorf("start block %d after end block %d", start, end)
+	}
+	rewrites := api.yoc.blockchain.GetBalanceRewrites(start, end)
+	if rewrites == nil {
+		rewrites = []*types.BalanceRewrite{}
+	}
+	return rewrites, nil
+}
+
 // StorageRangeResult is the result of a debug_storageRangeAt API call.
 type StorageRangeResult struct {
 	Storage storageMap   `json:"storage"`
@@ -5,6 +5,7 @@ package yoc
 
 import (
 	"context"
+	"errors"
 	"github.com/Yocoin15/Yocoin_Sources/miner"
 	"math/big"
 
@@ -90,6 +91,39 @@ func (b *YocAPIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.
 	return stateDb, header, err
 }
 
+func (b *YocAPIBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	if blockNr, ok := blockNrOrHash.Number(); ok {
+		return b.HeaderByNumber(ctx, blockNr)
+	}
+	hash, ok := blockNrOrHash.Hash()
+	if !ok {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	header, err := b.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("header for hash not found")
+	}
+	if blockNrOrHash.RequireCanonical && rawdb.ReadCanonicalHash(b.yoc.chainDb, header.Number.Uint64()) != hash {
+		return nil, errors.New("hash is not currently canonical")
+	}
+	return header, nil
+}
+
+func (b *YocAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	if blockNr, ok := blockNrOrHash.Number(); ok {
+		return b.StateAndHeaderByNumber(ctx, blockNr)
+	}
+	header, err := b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	stateDb, err := b.yoc.BlockChain().StateAt(header.Root)
+	return stateDb, header, err
+}
+
 func (b *YocAPIBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	return b.yoc.blockchain.GetBlockByHash(hash), nil
 }
@@ -122,7 +156,7 @@ func (b *YocAPIBackend) GetTd(blockHash common.Hash) *big.Int {
 }
 
 func (b *YocAPIBackend) GetYVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.YVM, func() error, error) {
-	state.SetBalance(msg.From(), math.MaxBig256, header.Number)
+	state.SetBalance(msg.From(), math.MaxBig256)
 	vmError := func() error { return nil }
 
 	context := core.NewYVMContext(msg, header, b.yoc.BlockChain(), nil)
@@ -197,6 +231,14 @@ func (b *YocAPIBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *YocAPIBackend) SuggestPrices(ctx context.Context) (*big.Int, *big.Int, *big.Int, error) {
+	return b.gpo.SuggestPrices(ctx)
+}
+
+func (b *YocAPIBackend) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []float64, error) {
+	return b.gpo.FeeHistory(ctx, blocks, lastBlock, rewardPercentiles)
+}
+
 func (b *YocAPIBackend) ChainDb() yocdb.Database {
 	return b.yoc.ChainDb()
 }
@@ -0,0 +1,365 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yoc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+)
+
+const (
+	// maxTraceFilterBlocks is the maximum number of blocks a trace filter may
+	// span, every block potentially having to be traced.
+	maxTraceFilterBlocks = 10000
+
+	// maxTraceFilterResults is the maximum number of traces a trace filter may
+	// return, larger result sets having to be paged through with after/count.
+	maxTraceFilterResults = 10000
+)
+
+// callTracerName is the tracer the flat call traces are assembled from.
+var callTracerName = "callTracer"
+
+// callTrace is the nested call tree produced by the call tracer.
+type callTrace struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Value   *hexutil.Big   `json:"value"`
+	Gas     hexutil.Uint64 `json:"gas"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Input   hexutil.Bytes  `json:"input"`
+	Output  hexutil.Bytes  `json:"output"`
+	Error   string         `json:"error"`
+	Calls   []*callTrace   `json:"calls"`
+}
+
+// FlatTraceAction is the call, contract creation or self destruct of a flat
+// call trace. Only the fields relevant to the trace type are set.
+type FlatTraceAction struct {
+	CallType string          `json:"callType,omitempty"`
+	From     *common.Address `json:"from,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	Address  *common.Address `json:"address,omitempty"`
+	Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+	Input    *hexutil.Bytes  `json:"input,omitempty"`
+	Init     *hexutil.Bytes  `json:"init,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+}
+
+// FlatTraceResult is the outcome of a successful flat call trace.
+type FlatTraceResult struct {
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+}
+
+// FlatCallTrace is a single call of a transaction, identified by its position
+// in the call tree, in the format of the parity trace module.
+type FlatCallTrace struct {
+	Type                string           `json:"type"`
+	Action              FlatTraceAction  `json:"action"`
+	Result              *FlatTraceResult `json:"result"`
+	Error               string           `json:"error,omitempty"`
+	Subtraces           int              `json:"subtraces"`
+	TraceAddress        []int            `json:"traceAddress"`
+	BlockHash           common.Hash      `json:"blockHash"`
+	BlockNumber         uint64           `json:"blockNumber"`
+	TransactionHash     common.Hash      `json:"transactionHash"`
+	TransactionPosition uint64           `json:"transactionPosition"`
+}
+
+// TraceFilterArgs are the criteria of trace_filter. The address lists match
+// the sender and the recipient of the calls respectively, and must both be
+// satisfied if set. The block range defaults to the latest block.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// PrivateTraceAPI is the collection of YoCoin APIs exposing flat call traces
+// of the canonical chain.
+type PrivateTraceAPI struct {
+	yoc   *YoCoin
+	debug *PrivateDebugAPI
+}
+
+// NewPrivateTraceAPI creates a new API definition for the flat call tracing
+// methods of the YoCoin service.
+func NewPrivateTraceAPI(yoc *YoCoin) *PrivateTraceAPI {
+	return &PrivateTraceAPI{yoc: yoc, debug: NewPrivateDebugAPI(yoc.chainConfig, yoc)}
+}
+
+// blockByNumber retrieves a canonical block, resolving the pending block to
+// the latest one.
+func (api *PrivateTraceAPI) blockByNumber(number rpc.BlockNumber) (*types.Block, error) {
+	var block *types.Block
+	switch number {
+	case rpc.PendingBlockNumber, rpc.LatestBlockNumber:
+		block = api.yoc.blockchain.CurrentBlock()
+	default:
+		block = api.yoc.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return block, nil
+}
+
+// Block returns the flat call traces of all the transactions of a block.
+func (api *PrivateTraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*FlatCallTrace, error) {
+	block, err := api.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.traceBlock(ctx, block)
+}
+
+// Transaction returns the flat call traces of a transaction.
+func (api *PrivateTraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*FlatCallTrace, error) {
+	_, blockHash, blockNumber, index := rawdb.ReadTransaction(api.yoc.ChainDb(), hash)
+	if blockHash == (common.Hash{}) {
+		return nil, fmt.Errorf("transaction %x not found", hash)
+	}
+	res, err := api.debug.TraceTransaction(ctx, hash, &TraceConfig{Tracer: &callTracerName})
+	if err != nil {
+		return nil, err
+	}
+	return flattenTrace(res.(json.RawMessage), blockHash, blockNumber, hash, index)
+}
+
+// Filter returns the flat call traces of the given block range matching the
+// sender and recipient criteria, skipping the first After matches and returning
+// at most Count ones. Blocks covered by the trace index are only traced if they
+// possibly contain matches. Both the block range and the number of results are
+// limited, requests exceeding the limits are rejected.
+func (api *PrivateTraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*FlatCallTrace, error) {
+	from, to := rpc.LatestBlockNumber, rpc.LatestBlockNumber
+	if args.FromBlock != nil {
+		from = *args.FromBlock
+	}
+	if args.ToBlock != nil {
+		to = *args.ToBlock
+	}
+	start, err := api.blockByNumber(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := api.blockByNumber(to)
+	if err != nil {
+		return nil, err
+	}
+	if start.NumberU64() > end.NumberU64() {
+		return nil, fmt.Errorf("invalid block range #%d-#%d", start.NumberU64(), end.NumberU64())
+	}
+	if end.NumberU64()-start.NumberU64() >= maxTraceFilterBlocks {
+		return nil, fmt.Errorf("block range #%d-#%d exceeds the limit of %d blocks", start.NumberU64(), end.NumberU64(), maxTraceFilterBlocks)
+	}
+	var (
+		skip    uint64
+		limit   = uint64(maxTraceFilterResults)
+		results = []*FlatCallTrace{}
+	)
+	if args.After != nil {
+		skip = *args.After
+	}
+	if args.Count != nil {
+		if *args.Count > maxTraceFilterResults {
+			return nil, fmt.Errorf("count %d exceeds the limit of %d traces", *args.Count, maxTraceFilterResults)
+		}
+		limit = *args.Count
+	}
+	if limit == 0 {
+		return results, nil
+	}
+	for number := start.NumberU64(); number <= end.NumberU64(); number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := api.yoc.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		if !api.mayMatch(block, &args) {
+			continue
+		}
+		traces, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range traces {
+			if !trace.matches(&args) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if uint64(len(results)) == limit {
+				return nil, fmt.Errorf("more than %d matching traces, page through them with after and count", maxTraceFilterResults)
+			}
+			results = append(results, trace)
+			if args.Count != nil && uint64(len(results)) == limit {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// mayMatch reports whether a block possibly contains call traces matching the
+// filter, according to the trace index. Blocks not indexed always may match.
+func (api *PrivateTraceAPI) mayMatch(block *types.Block, args *TraceFilterArgs) bool {
+	if len(block.Transactions()) == 0 {
+		return false
+	}
+	if api.yoc.traceIndexer == nil {
+		return true
+	}
+	bloom, ok := rawdb.ReadTraceBloom(api.yoc.ChainDb(), block.Hash(), block.NumberU64())
+	if !ok {
+		return true
+	}
+	return bloomMatches(bloom, args.FromAddress) && bloomMatches(bloom, args.ToAddress)
+}
+
+// bloomMatches reports whether any of the addresses is possibly in the bloom,
+// or if there are no addresses at all.
+func bloomMatches(bloom types.Bloom, addrs []common.Address) bool {
+	if len(addrs) == 0 {
+		return true
+	}
+	for _, addr := range addrs {
+		if types.BloomLookup(bloom, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// traceBlock traces all the transactions of a block with the call tracer and
+// flattens the results.
+func (api *PrivateTraceAPI) traceBlock(ctx context.Context, block *types.Block) ([]*FlatCallTrace, error) {
+	traces := []*FlatCallTrace{}
+	if len(block.Transactions()) == 0 {
+		return traces, nil
+	}
+	results, err := api.debug.traceBlock(ctx, block, &TraceConfig{Tracer: &callTracerName})
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range block.Transactions() {
+		if results[i].Error != "" {
+			return nil, fmt.Errorf("tracing transaction %x failed: %v", tx.Hash(), results[i].Error)
+		}
+		flat, err := flattenTrace(results[i].Result.(json.RawMessage), block.Hash(), block.NumberU64(), tx.Hash(), uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, flat...)
+	}
+	return traces, nil
+}
+
+// flattenTrace converts the result of the call tracer into flat call traces.
+func flattenTrace(res json.RawMessage, blockHash common.Hash, blockNumber uint64, txHash common.Hash, txIndex uint64) ([]*FlatCallTrace, error) {
+	root := new(callTrace)
+	if err := json.Unmarshal(res, root); err != nil {
+		return nil, err
+	}
+	var traces []*FlatCallTrace
+	var flatten func(call, parent *callTrace, address []int)
+	flatten = func(call, parent *callTrace, address []int) {
+		trace := &FlatCallTrace{
+			Error:               call.Error,
+			Subtraces:           len(call.Calls),
+			TraceAddress:        address,
+			BlockHash:           blockHash,
+			BlockNumber:         blockNumber,
+			TransactionHash:     txHash,
+			TransactionPosition: txIndex,
+		}
+		from, to, gas := call.From, call.To, call.Gas
+		input, output := call.Input, call.Output
+
+		switch call.Type {
+		case "CREATE":
+			trace.Type = "create"
+			trace.Action = FlatTraceAction{From: &from, Gas: &gas, Init: &input, Value: call.Value}
+			if call.Error == "" {
+				trace.Result = &FlatTraceResult{GasUsed: call.GasUsed, Address: &to, Code: &output}
+			}
+		case "SELFDESTRUCT":
+			// The call tracer doesn't report the beneficiary, only the contract
+			addr := parent.To
+			if parent.Type == "DELEGATECALL" || parent.Type == "CALLCODE" {
+				addr = parent.From
+			}
+			trace.Type = "suicide"
+			trace.Action = FlatTraceAction{Address: &addr}
+			trace.Result = new(FlatTraceResult)
+		default:
+			trace.Type = "call"
+			trace.Action = FlatTraceAction{CallType: strings.ToLower(call.Type), From: &from, To: &to, Gas: &gas, Input: &input, Value: call.Value}
+			if call.Error == "" {
+				trace.Result = &FlatTraceResult{GasUsed: call.GasUsed, Output: &output}
+			}
+		}
+		traces = append(traces, trace)
+
+		for i, inner := range call.Calls {
+			flatten(inner, call, append(append([]int{}, address...), i))
+		}
+	}
+	flatten(root, nil, []int{})
+	return traces, nil
+}
+
+// matches reports whether the call trace satisfies the sender and recipient
+// criteria of the filter.
+func (trace *FlatCallTrace) matches(args *TraceFilterArgs) bool {
+	var from, to *common.Address
+	switch trace.Type {
+	case "create":
+		from = trace.Action.From
+		if trace.Result != nil {
+			to = trace.Result.Address
+		}
+	case "suicide":
+		from = trace.Action.Address
+	default:
+		from, to = trace.Action.From, trace.Action.To
+	}
+	return addressMatches(from, args.FromAddress) && addressMatches(to, args.ToAddress)
+}
+
+// addressMatches reports whether the address is in the list, or if the list is
+// empty.
+func addressMatches(addr *common.Address, addrs []common.Address) bool {
+	if len(addrs) == 0 {
+		return true
+	}
+	if addr == nil {
+		return false
+	}
+	for _, a := range addrs {
+		if a == *addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yoc
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+)
+
+func TestFlattenTrace(t *testing.T) {
+	res := []byte(`{
+		"type": "CALL",
+		"from": "0x0000000000000000000000000000000000000001",
+		"to": "0x0000000000000000000000000000000000000002",
+		"value": "0x1",
+		"gas": "0x100",
+		"gasUsed": "0x80",
+		"input": "0x",
+		"output": "0x",
+		"calls": [
+			{
+				"type": "CREATE",
+				"from": "0x0000000000000000000000000000000000000002",
+				"to": "0x0000000000000000000000000000000000000003",
+				"value": "0x0",
+				"gas": "0x40",
+				"gasUsed": "0x20",
+				"input": "0x6000",
+				"output": "0x"
+			},
+			{
+				"type": "DELEGATECALL",
+				"from": "0x0000000000000000000000000000000000000002",
+				"to": "0x0000000000000000000000000000000000000004",
+				"input": "0x",
+				"error": "execution reverted",
+				"calls": [{"type": "SELFDESTRUCT"}]
+			}
+		]
+	}`)
+	traces, err := flattenTrace(res, common.Hash{1}, 10, common.Hash{2}, 3)
+	if err != nil {
+		t.Fatalf("failed to flatten trace: %v", err)
+	}
+	want := []struct {
+		typ       string
+		address   []int
+		subtraces int
+		from, to  common.Address
+		failed    bool
+	}{
+		{"call", []int{}, 2, common.Address{19: 1}, common.Address{19: 2}, false},
+		{"create", []int{0}, 0, common.Address{19: 2}, common.Address{19: 3}, false},
+		{"call", []int{1}, 1, common.Address{19: 2}, common.Address{19: 4}, true},
+		{"suicide", []int{1, 0}, 0, common.Address{19: 2}, common.Address{}, false},
+	}
+	if len(traces) != len(want) {
+		t.Fatalf("trace count mismatch: have %d, want %d", len(traces), len(want))
+	}
+	for i, trace := range traces {
+		if trace.Type != want[i].typ {
+			t.Errorf("trace %d: type mismatch: have %s, want %s", i, trace.Type, want[i].typ)
+		}
+		if !reflect.DeepEqual(trace.TraceAddress, want[i].address) {
+			t.Errorf("trace %d: address mismatch: have %v, want %v", i, trace.TraceAddress, want[i].address)
+		}
+		if trace.Subtraces != want[i].subtraces {
+			t.Errorf("trace %d: subtraces mismatch: have %d, want %d", i, trace.Subtraces, want[i].subtraces)
+		}
+		if (trace.Result == nil) != want[i].failed {
+			t.Errorf("trace %d: failure mismatch: have %v, want %v", i, trace.Result == nil, want[i].failed)
+		}
+		if trace.BlockNumber != 10 || trace.TransactionPosition != 3 {
+			t.Errorf("trace %d: position mismatch: have #%d/%d", i, trace.BlockNumber, trace.TransactionPosition)
+		}
+		if !trace.matches(&TraceFilterArgs{FromAddress: []common.Address{want[i].from}}) {
+			t.Errorf("trace %d: sender %x not matched", i, want[i].from)
+		}
+		if want[i].to != (common.Address{}) && !trace.matches(&TraceFilterArgs{ToAddress: []common.Address{want[i].to}}) {
+			t.Errorf("trace %d: recipient %x not matched", i, want[i].to)
+		}
+		if trace.matches(&TraceFilterArgs{ToAddress: []common.Address{{19: 5}}}) {
+			t.Errorf("trace %d: unrelated recipient matched", i)
+		}
+	}
+}
+
+// Tests that the trace filter returns the matching calls of the block range,
+// and rejects requests exceeding its limits.
+func TestTraceFilter(t *testing.T) {
+	yoc := newTestYoCoin(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(params.YOC)}}, maxTraceFilterBlocks, func(i int, b *core.BlockGen) {
+		if i >= 3 {
+			return
+		}
+		tx := types.NewTransaction(b.TxNonce(testBank), common.Address{19: byte(i + 1)}, big.NewInt(1), params.TxGas, big.NewInt(1), nil)
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testBankKey)
+		b.AddTx(tx)
+	})
+	api := NewPrivateTraceAPI(yoc)
+
+	var (
+		from  = rpc.BlockNumber(1)
+		to    = rpc.BlockNumber(3)
+		count = uint64(1)
+	)
+	traces, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to, ToAddress: []common.Address{{19: 2}}})
+	if err != nil {
+		t.Fatalf("failed to filter traces: %v", err)
+	}
+	if len(traces) != 1 || traces[0].BlockNumber != 2 || *traces[0].Action.From != testBank {
+		t.Fatalf("filtered traces mismatch: have %+v", traces)
+	}
+	traces, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to, Count: &count})
+	if err != nil {
+		t.Fatalf("failed to filter traces: %v", err)
+	}
+	if len(traces) != 1 || traces[0].BlockNumber != 1 {
+		t.Fatalf("counted traces mismatch: have %+v", traces)
+	}
+	// Requests without a block range must trace the latest block only
+	if _, err := api.Filter(context.Background(), TraceFilterArgs{}); err != nil {
+		t.Errorf("failed to filter traces of the latest block: %v", err)
+	}
+	// Requests exceeding the limits must be rejected
+	from, to = 0, maxTraceFilterBlocks
+	if _, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to}); err == nil {
+		t.Errorf("block range over the limit accepted")
+	}
+	from, to = 1, maxTraceFilterBlocks
+	if _, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to}); err != nil {
+		t.Errorf("block range at the limit rejected: %v", err)
+	}
+	count = maxTraceFilterResults + 1
+	if _, err := api.Filter(context.Background(), TraceFilterArgs{Count: &count}); err == nil {
+		t.Errorf("count over the limit accepted")
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/misc"
 	"github.com/Yocoin15/Yocoin_Sources/core"
 	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
 	"github.com/Yocoin15/Yocoin_Sources/core/state"
@@ -37,6 +38,10 @@ const (
 	// and reexecute to produce missing historical state necessary to run a specific
 	// trace.
 	defaultTraceReexec = uint64(128)
+
+	// stateDiffTracer is the name of the tracer reporting the state modified by
+	// a transaction instead of the steps of its execution.
+	stateDiffTracer = "stateDiff"
 )
 
 // TraceConfig holds extra parameters to trace functions.
@@ -51,15 +56,33 @@ type TraceConfig struct {
 type txTraceResult struct {
 	Result interface{} `json:"result,omitempty"` // Trace results produced by the tracer
 	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
+	Block  bool        `json:"block,omitempty"`  // Whether the result covers the changes made before the transactions instead
+}
+
+// stateDiffAccount is the state of an account in a state diff, holding only
+// the storage slots modified by the transaction.
+type stateDiffAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   hexutil.Uint64              `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// stateDiff is the state of an account before and after a transaction. The pre
+// state is null for created accounts and the post state for deleted ones.
+type stateDiff struct {
+	Pre  *stateDiffAccount `json:"pre"`
+	Post *stateDiffAccount `json:"post"`
 }
 
 // blockTraceTask represents a single block trace task when an entire chain is
 // being traced.
 type blockTraceTask struct {
-	statedb *state.StateDB   // Intermediate state prepped for tracing
-	block   *types.Block     // Block to trace the transactions from
-	rootref common.Hash      // Trie root reference held for this task
-	results []*txTraceResult // Trace results procudes by the task
+	statedb  *state.StateDB                        // Intermediate state prepped for tracing
+	block    *types.Block                          // Block to trace the transactions from
+	preamble map[common.Address]*state.AccountDiff // Changes made before the transactions of the block
+	rootref  common.Hash                           // Trie root reference held for this task
+	results  []*txTraceResult                      // Trace results procudes by the task
 }
 
 // blockTraceResult represets the results of tracing a single block when an entire
@@ -111,7 +134,8 @@ func (api *PrivateDebugAPI) TraceChain(ctx context.Context, start, end rpc.Block
 
 // traceChain configures a new tracer according to the provided configuration, and
 // executes all the transactions contained within. The return value will be one item
-// per transaction, dependent on the requestd tracer.
+// per transaction, dependent on the requestd tracer. State diffs are followed by a
+// block-level item if the block modified the state before its transactions.
 func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Block, config *TraceConfig) (*rpc.Subscription, error) {
 	// Tracing a chain is a **long** operation, only do with subscriptions
 	notifier, supported := rpc.NotifierFromContext(ctx)
@@ -192,6 +216,9 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 					task.statedb.Finalise(true)
 					task.results[i] = &txTraceResult{Result: res}
 				}
+				if res := preambleTrace(task.preamble, config); res != nil {
+					task.results = append(task.results, res)
+				}
 				// Stream the result back to the user or abort on teardown
 				select {
 				case results <- task:
@@ -255,8 +282,11 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 			if number > origin {
 				txs := block.Transactions()
 
+				taskdb := statedb.Copy()
+				preamble := api.applyBlockPreamble(block, taskdb)
+
 				select {
-				case tasks <- &blockTraceTask{statedb: statedb.Copy(), block: block, rootref: proot, results: make([]*txTraceResult, len(txs))}:
+				case tasks <- &blockTraceTask{statedb: taskdb, block: block, preamble: preamble, rootref: proot, results: make([]*txTraceResult, len(txs))}:
 				case <-notifier.Closed():
 					return
 				}
@@ -377,6 +407,10 @@ func (api *PrivateDebugAPI) TraceBlockFromFile(ctx context.Context, file string,
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requestd tracer.
 func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]*txTraceResult, error) {
+	// Serve the traces produced in the background if available
+	if results := api.cachedTraces(block, config); results != nil {
+		return results, nil
+	}
 	// Create the parent state database
 	if err := api.yoc.engine.VerifyHeader(api.yoc.blockchain, block.Header(), true); err != nil {
 		return nil, err
@@ -393,6 +427,8 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	if err != nil {
 		return nil, err
 	}
+	preamble := api.applyBlockPreamble(block, statedb)
+
 	// Execute all the transaction contained within the block concurrently
 	var (
 		signer = types.MakeSigner(api.config, block.Number())
@@ -451,9 +487,38 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	if failed != nil {
 		return nil, failed
 	}
+	if res := preambleTrace(preamble, config); res != nil {
+		results = append(results, res)
+	}
 	return results, nil
 }
 
+// applyBlockPreamble modifies the parent state of a block the way the state
+// processor does before executing the transactions of the block, i.e. applies
+// the DAO fork and the irregular state changes, returning the accounts modified.
+func (api *PrivateDebugAPI) applyBlockPreamble(block *types.Block, statedb *state.StateDB) map[common.Address]*state.AccountDiff {
+	if api.config.DAOForkSupport && api.config.DAOForkBlock != nil && api.config.DAOForkBlock.Cmp(block.Number()) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+	misc.ApplyIrregularStateChanges(api.config, statedb, block.Number())
+
+	// Finalize the state so the changes are not attributed to the first transaction
+	deleteEmptyObjects := api.config.IsEIP158(block.Number())
+	diff := statedb.Diff(deleteEmptyObjects)
+	statedb.Finalise(deleteEmptyObjects)
+	return diff
+}
+
+// preambleTrace returns the block-level entry of the state diff traces of a
+// block, reporting the changes made before its transactions, or nil if another
+// tracer was requested or there were no such changes.
+func preambleTrace(preamble map[common.Address]*state.AccountDiff, config *TraceConfig) *txTraceResult {
+	if config == nil || config.Tracer == nil || *config.Tracer != stateDiffTracer || len(preamble) == 0 {
+		return nil
+	}
+	return &txTraceResult{Result: formatStateDiff(preamble), Block: true}
+}
+
 // computeStateDB retrieves the state database associated with a certain block.
 // If no state is locally available for the given block, a number of blocks are
 // attempted to be reexecuted to generate the desired state.
@@ -529,6 +594,10 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	if tx == nil {
 		return nil, fmt.Errorf("transaction %x not found", hash)
 	}
+	// Serve the trace produced in the background if available
+	if res := api.cachedTxTrace(blockHash, index, config); res != nil {
+		return res, nil
+	}
 	reexec := defaultTraceReexec
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
@@ -541,6 +610,46 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	return api.traceTx(ctx, msg, vmctx, statedb, config)
 }
 
+// TraceCall returns the structured logs created during the execution of YVM if
+// the given call was executed on top of the given block, and returns them as a
+// JSON object.
+func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args yocapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (interface{}, error) {
+	// Fetch the block that we want to trace on top of
+	var block *types.Block
+
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block = api.yoc.blockchain.GetBlockByHash(hash)
+		if block != nil && blockNrOrHash.RequireCanonical && rawdb.ReadCanonicalHash(api.yoc.ChainDb(), block.NumberU64()) != hash {
+			return nil, fmt.Errorf("block %x is not canonical", hash)
+		}
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		switch number {
+		case rpc.PendingBlockNumber:
+			return nil, errors.New("tracing on top of the pending block is not supported")
+		case rpc.LatestBlockNumber:
+			block = api.yoc.blockchain.CurrentBlock()
+		default:
+			block = api.yoc.blockchain.GetBlockByNumber(uint64(number))
+		}
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %v not found", blockNrOrHash)
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, err := api.computeStateDB(block, reexec)
+	if err != nil {
+		return nil, err
+	}
+	// Execute the call on top of the block and return its trace
+	msg := args.ToMessage(api.yoc.AccountManager())
+	vmctx := core.NewYVMContext(msg, block.Header(), api.yoc.blockchain, nil)
+
+	return api.traceTx(ctx, msg, vmctx, statedb, config)
+}
+
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
@@ -551,6 +660,32 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 		err    error
 	)
 	switch {
+	case config != nil && config.Tracer != nil && *config.Tracer == stateDiffTracer:
+		// The state diff is collected from the state journal, no tracing needed
+		timeout := defaultTraceTimeout
+		if config.Timeout != nil {
+			if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+				return nil, err
+			}
+		}
+		vmenv := vm.NewYVM(vmctx, statedb, api.config, vm.Config{})
+
+		// Handle timeouts and RPC cancellations
+		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+		go func() {
+			<-deadlineCtx.Done()
+			vmenv.Cancel()
+		}()
+		defer cancel()
+
+		if _, _, _, err := core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas())); err != nil {
+			return nil, fmt.Errorf("tracing failed: %v", err)
+		}
+		if deadlineCtx.Err() != nil {
+			return nil, errors.New("execution timeout")
+		}
+		return formatStateDiff(statedb.Diff(api.config.IsEIP158(vmctx.BlockNumber))), nil
+
 	case config != nil && config.Tracer != nil:
 		// Define a meaningful timeout of a single transaction trace
 		timeout := defaultTraceTimeout
@@ -559,15 +694,15 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 				return nil, err
 			}
 		}
-		// Constuct the JavaScript tracer to execute with
-		if tracer, err = tracers.New(*config.Tracer); err != nil {
+		// Constuct the native or JavaScript tracer to execute with
+		if tracer, err = tracers.NewTracer(*config.Tracer); err != nil {
 			return nil, err
 		}
 		// Handle timeouts and RPC cancellations
 		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
 		go func() {
 			<-deadlineCtx.Done()
-			tracer.(*tracers.Tracer).Stop(errors.New("execution timeout"))
+			tracer.(tracers.Interface).Stop(errors.New("execution timeout"))
 		}()
 		defer cancel()
 
@@ -594,7 +729,7 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 			StructLogs:  yocapi.FormatLogs(tracer.StructLogs()),
 		}, nil
 
-	case *tracers.Tracer:
+	case tracers.Interface:
 		return tracer.GetResult()
 
 	default:
@@ -602,6 +737,27 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	}
 }
 
+// formatStateDiff converts the account modifications of a state into their RPC
+// representation.
+func formatStateDiff(diff map[common.Address]*state.AccountDiff) map[common.Address]*stateDiff {
+	format := func(account *state.AccountState) *stateDiffAccount {
+		if account == nil {
+			return nil
+		}
+		return &stateDiffAccount{
+			Balance: (*hexutil.Big)(account.Balance),
+			Nonce:   hexutil.Uint64(account.Nonce),
+			Code:    account.Code,
+			Storage: account.Storage,
+		}
+	}
+	result := make(map[common.Address]*stateDiff, len(diff))
+	for addr, account := range diff {
+		result[addr] = &stateDiff{Pre: format(account.Pre), Post: format(account.Post)}
+	}
+	return result
+}
+
 // computeTxEnv returns the execution environment of a certain transaction.
 func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, *state.StateDB, error) {
 	// Create the parent state database
@@ -617,6 +773,8 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, ree
 	if err != nil {
 		return nil, vm.Context{}, nil, err
 	}
+	api.applyBlockPreamble(block, statedb)
+
 	// Recompute transactions up to the target index.
 	signer := types.MakeSigner(api.config, block.Number())
 
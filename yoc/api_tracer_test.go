@@ -0,0 +1,230 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yoc
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+)
+
+var (
+	testTraceRecv  = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testTraceStore = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	testTraceLoop  = common.HexToAddress("0x3000000000000000000000000000000000000003")
+)
+
+// newStateDiffTestYoCoin creates a chain of two blocks: the first transferring
+// funds to testTraceRecv and then storing into testTraceStore, the second
+// running the endless loop of testTraceLoop until out of gas.
+func newStateDiffTestYoCoin(t *testing.T) (*YoCoin, []*types.Transaction) {
+	alloc := core.GenesisAlloc{
+		testBank: {Balance: big.NewInt(params.YOC)},
+		testTraceStore: {
+			// PUSH1 0x01 PUSH1 0x00 SSTORE
+			Balance: new(big.Int),
+			Code:    common.FromHex("0x6001600055"),
+		},
+		testTraceLoop: {
+			// JUMPDEST PUSH1 0x00 JUMP
+			Balance: new(big.Int),
+			Code:    common.FromHex("0x5b600056"),
+		},
+	}
+	var txs []*types.Transaction
+	yoc := newTestYoCoin(t, alloc, 2, func(i int, b *core.BlockGen) {
+		var batch []*types.Transaction
+		switch i {
+		case 0:
+			batch = []*types.Transaction{
+				types.NewTransaction(b.TxNonce(testBank), testTraceRecv, big.NewInt(1000), params.TxGas, big.NewInt(1), nil),
+				types.NewTransaction(b.TxNonce(testBank)+1, testTraceStore, big.NewInt(0), 100000, big.NewInt(1), nil),
+			}
+		case 1:
+			batch = []*types.Transaction{
+				types.NewTransaction(b.TxNonce(testBank), testTraceLoop, big.NewInt(0), 1000000, big.NewInt(1), nil),
+			}
+		}
+		for _, tx := range batch {
+			tx, err := types.SignTx(tx, types.HomesteadSigner{}, testBankKey)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			b.AddTx(tx)
+			txs = append(txs, tx)
+		}
+	})
+	return yoc, txs
+}
+
+// Tests that the state diff of a transaction only reports the changes made by
+// the transaction itself, whether traced alone on the state rebuilt up to it or
+// as part of its block on a copy of the intermediate state.
+func TestTraceStateDiff(t *testing.T) {
+	yoc, txs := newStateDiffTestYoCoin(t)
+	api := NewPrivateDebugAPI(yoc.chainConfig, yoc)
+
+	tracer := stateDiffTracer
+	config := &TraceConfig{Tracer: &tracer}
+
+	res, err := api.TraceTransaction(context.Background(), txs[1].Hash(), config)
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	diff := res.(map[common.Address]*stateDiff)
+	if _, ok := diff[testTraceRecv]; ok {
+		t.Errorf("changes of the previous transaction reported: %v", diff[testTraceRecv])
+	}
+	if bank := diff[testBank]; bank == nil || bank.Pre.Nonce != 1 || bank.Post.Nonce != 2 {
+		t.Errorf("sender diff mismatch: have %+v", bank)
+	}
+	store := diff[testTraceStore]
+	if store == nil || store.Pre.Storage[common.Hash{}] != (common.Hash{}) || store.Post.Storage[common.Hash{}] != (common.Hash{31: 1}) {
+		t.Errorf("storage diff mismatch: have %+v", store)
+	}
+	// Tracing the whole block must report the same diffs
+	results, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(1), config)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("block trace count mismatch: have %d, want 2", len(results))
+	}
+	for i, tx := range txs[:2] {
+		single, err := api.TraceTransaction(context.Background(), tx.Hash(), config)
+		if err != nil {
+			t.Fatalf("tx %d: failed to trace transaction: %v", i, err)
+		}
+		want, _ := json.Marshal(single)
+		have, _ := json.Marshal(results[i].Result)
+		if string(have) != string(want) {
+			t.Errorf("tx %d: block trace mismatch:\nhave %s\nwant %s", i, have, want)
+		}
+	}
+}
+
+// Tests that state diffs honour the trace timeout and the cancellation of the
+// request.
+func TestTraceStateDiffTimeout(t *testing.T) {
+	yoc, txs := newStateDiffTestYoCoin(t)
+	api := NewPrivateDebugAPI(yoc.chainConfig, yoc)
+
+	var (
+		tracer  = stateDiffTracer
+		timeout = "1ns"
+		invalid = "soon"
+	)
+	if _, err := api.TraceTransaction(context.Background(), txs[2].Hash(), &TraceConfig{Tracer: &tracer, Timeout: &timeout}); err == nil {
+		t.Errorf("trace exceeding the timeout succeeded")
+	}
+	if _, err := api.TraceTransaction(context.Background(), txs[2].Hash(), &TraceConfig{Tracer: &tracer, Timeout: &invalid}); err == nil {
+		t.Errorf("trace with invalid timeout succeeded")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := api.TraceTransaction(ctx, txs[2].Hash(), &TraceConfig{Tracer: &tracer}); err == nil {
+		t.Errorf("cancelled trace succeeded")
+	}
+	if _, err := api.TraceTransaction(context.Background(), txs[2].Hash(), &TraceConfig{Tracer: &tracer}); err != nil {
+		t.Errorf("failed to trace transaction: %v", err)
+	}
+}
+
+// newIrregularTestYoCoin creates a chain of a single block, moving half of the
+// funds of testBank to a new account by an irregular state change and sending
+// from the new account in a transaction.
+func newIrregularTestYoCoin(t *testing.T) (*YoCoin, *types.Transaction, common.Address) {
+	key, _ := crypto.GenerateKey()
+	funded := crypto.PubkeyToAddress(key.PublicKey)
+
+	config := *params.TestChainConfig
+	config.IrregularStateChanges = []*params.IrregularStateChange{{
+		Block: big.NewInt(1),
+		Moves: []params.IrregularBalance{{From: testBank, To: funded, Amount: big.NewInt(params.YOC / 2)}},
+	}}
+	alloc := core.GenesisAlloc{testBank: {Balance: big.NewInt(params.YOC)}}
+
+	var txs []*types.Transaction
+	yoc := newTestYoCoinWithConfig(t, &config, alloc, 1, func(i int, b *core.BlockGen) {
+		// The sender is only funded by the irregular state change of the block
+		tx, err := types.SignTx(types.NewTransaction(0, testTraceRecv, big.NewInt(1000), params.TxGas, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		b.AddTx(tx)
+		txs = append(txs, tx)
+	})
+	return yoc, txs[0], funded
+}
+
+// Tests that transactions are traced on the parent state modified by the
+// irregular state changes of their block, like when the block was processed.
+func TestTraceIrregularStateChange(t *testing.T) {
+	yoc, tx, funded := newIrregularTestYoCoin(t)
+	api := NewPrivateDebugAPI(yoc.chainConfig, yoc)
+
+	results, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(1), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(results) != 1 || results[0].Error != "" {
+		t.Fatalf("block trace mismatch: have %+v", results)
+	}
+	tracer := stateDiffTracer
+	res, err := api.TraceTransaction(context.Background(), tx.Hash(), &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	sender := res.(map[common.Address]*stateDiff)[funded]
+	if sender == nil || sender.Pre.Balance.ToInt().Cmp(big.NewInt(params.YOC/2)) != 0 {
+		t.Errorf("sender diff mismatch: have %+v", sender)
+	}
+}
+
+// Tests that the state diffs of a block report the changes made before its
+// transactions in a block-level entry following the transactions.
+func TestTraceStateDiffPreamble(t *testing.T) {
+	yoc, _, funded := newIrregularTestYoCoin(t)
+	api := NewPrivateDebugAPI(yoc.chainConfig, yoc)
+
+	tracer := stateDiffTracer
+	results, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(1), &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(results) != 2 || results[0].Block || !results[1].Block {
+		t.Fatalf("block trace mismatch: have %+v", results)
+	}
+	diff := results[1].Result.(map[common.Address]*stateDiff)
+	if len(diff) != 2 {
+		t.Errorf("block-level diff size mismatch: have %d, want 2", len(diff))
+	}
+	half := big.NewInt(params.YOC / 2)
+	if bank := diff[testBank]; bank == nil || bank.Pre.Balance.ToInt().Cmp(big.NewInt(params.YOC)) != 0 || bank.Post.Balance.ToInt().Cmp(half) != 0 {
+		t.Errorf("drained account diff mismatch: have %+v", bank)
+	}
+	if restored := diff[funded]; restored == nil || restored.Pre != nil || restored.Post.Balance.ToInt().Cmp(half) != 0 {
+		t.Errorf("funded account diff mismatch: have %+v", restored)
+	}
+	// The diff of the transaction must not include the block-level changes
+	if _, ok := results[0].Result.(map[common.Address]*stateDiff)[testBank]; ok {
+		t.Errorf("block-level changes reported for the transaction")
+	}
+	// Other tracers must not report a block-level entry
+	results, err = api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(1), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("block trace count mismatch: have %d, want 1", len(results))
+	}
+}
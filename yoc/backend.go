@@ -21,6 +21,7 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/core"
 	"github.com/Yocoin15/Yocoin_Sources/core/bloombits"
 	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/core/state/pruner"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/core/vm"
 	"github.com/Yocoin15/Yocoin_Sources/event"
@@ -35,6 +36,7 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/yoc/downloader"
 	"github.com/Yocoin15/Yocoin_Sources/yoc/filters"
 	"github.com/Yocoin15/Yocoin_Sources/yoc/gasprice"
+	"github.com/Yocoin15/Yocoin_Sources/yoc/tracers"
 	"github.com/Yocoin15/Yocoin_Sources/yocdb"
 )
 
@@ -68,6 +70,8 @@ type YoCoin struct {
 
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
+	traceIndexer  *core.ChainIndexer             // Call trace indexer operating during block imports, nil if disabled
+	traceCache    *blockTraceCache               // Background tracer of the new blocks, nil if disabled
 
 	APIBackend *YocAPIBackend
 
@@ -95,10 +99,20 @@ func New(ctx *node.ServiceContext, config *Config) (*YoCoin, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	// The trace indexer needs the state of blocks way past the in-memory ones
+	if config.TraceIndex && !config.NoPruning {
+		return nil, errors.New("trace index requires an archive node (--gcmode=archive)")
+	}
 	chainDb, err := CreateDB(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
 	}
+	// Finish any state pruning interrupted before the chain starts writing again
+	if db, ok := rawdb.KeyValueStore(chainDb).(*yocdb.LDBDatabase); ok {
+		if err := pruner.RecoverPruning(db, ctx.ResolvePath(pruner.BloomFileName)); err != nil {
+			return nil, err
+		}
+	}
 	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -131,7 +145,7 @@ func New(ctx *node.ServiceContext, config *Config) (*YoCoin, error) {
 	}
 	var (
 		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout, SnapshotLimit: config.SnapshotCache}
 	)
 	yoc.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, yoc.chainConfig, yoc.engine, vmConfig)
 	if err != nil {
@@ -145,9 +159,36 @@ func New(ctx *node.ServiceContext, config *Config) (*YoCoin, error) {
 	}
 	yoc.bloomIndexer.Start(yoc.blockchain)
 
+	if config.TraceIndex {
+		yoc.traceIndexer = NewTraceIndexer(yoc)
+		yoc.traceIndexer.Start(yoc.blockchain)
+	}
+	if config.TraceCache != "" {
+		if config.TraceCache != stateDiffTracer {
+			if _, err := tracers.NewTracer(config.TraceCache); err != nil {
+				return nil, fmt.Errorf("invalid trace cache tracer: %v", err)
+			}
+		}
+		blocks := config.TraceCacheBlocks
+		if blocks == 0 {
+			log.Warn("Sanitizing invalid trace cache retention", "provided", blocks, "updated", DefaultConfig.TraceCacheBlocks)
+			blocks = DefaultConfig.TraceCacheBlocks
+		}
+		yoc.traceCache = newBlockTraceCache(yoc, config.TraceCache, blocks)
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	config.TxPool.AddressRules = params.LocalAddressRules(genesisHash)
+	if config.TxPool.Blocklist != "" {
+		config.TxPool.Blocklist = ctx.ResolvePath(config.TxPool.Blocklist)
+		rules, err := core.LoadAddressRules(config.TxPool.Blocklist)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address rules file: %v", err)
+		}
+		config.TxPool.AddressRules = append(config.TxPool.AddressRules, rules...)
+	}
 	yoc.txPool = core.NewTxPool(config.TxPool, yoc.chainConfig, yoc.blockchain)
 
 	if yoc.protocolManager, err = NewProtocolManager(yoc.chainConfig, config.SyncMode, config.NetworkId, yoc.eventMux, yoc.txPool, yoc.engine, yoc.blockchain, chainDb); err != nil {
@@ -186,11 +227,11 @@ func makeExtraData(extra []byte) []byte {
 
 // CreateDB creates the chain database.
 func CreateDB(ctx *node.ServiceContext, config *Config, name string) (yocdb.Database, error) {
-	db, err := ctx.OpenDatabase(name, config.DatabaseCache, config.DatabaseHandles)
+	db, err := ctx.OpenDatabaseWithFreezer(name, config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/")
 	if err != nil {
 		return nil, err
 	}
-	if db, ok := db.(*yocdb.LDBDatabase); ok {
+	if db, ok := rawdb.KeyValueStore(db).(*yocdb.LDBDatabase); ok {
 		db.Meter("eth/db/chaindata/")
 	}
 	return db, nil
@@ -275,6 +316,10 @@ func (s *YoCoin) APIs() []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(s.chainConfig, s),
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   NewPrivateTraceAPI(s),
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -398,6 +443,12 @@ func (s *YoCoin) Start(srvr *p2p.Server) error {
 // YoCoin protocol.
 func (s *YoCoin) Stop() error {
 	s.bloomIndexer.Close()
+	if s.traceIndexer != nil {
+		s.traceIndexer.Close()
+	}
+	if s.traceCache != nil {
+		s.traceCache.stop()
+	}
 	s.blockchain.Stop()
 	s.engine.Close()
 	s.protocolManager.Stop()
@@ -38,6 +38,8 @@ var DefaultConfig = Config{
 	GasPrice:      big.NewInt(18 * params.Shannon),
 
 	TxPool: core.DefaultTxPoolConfig,
+
+	TraceCacheBlocks: 1024,
 	GPO: gasprice.Config{
 		Blocks:     20,
 		Percentile: 60,
@@ -78,8 +80,10 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	DatabaseFreezer    string
 	TrieCache          int
 	TrieTimeout        time.Duration
+	SnapshotCache      int // Memory allowance (MB) of the state snapshot (0 = disabled)
 
 	// Mining-related options
 	Yocbase      common.Address `toml:",omitempty"`
@@ -99,6 +103,14 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables indexing the addresses in the call traces of the blocks
+	TraceIndex bool
+
+	// Tracer to trace the new blocks with in the background, disabled if empty,
+	// and the number of recent blocks to keep the traces of
+	TraceCache       string `toml:",omitempty"`
+	TraceCacheBlocks uint64 `toml:",omitempty"`
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 }
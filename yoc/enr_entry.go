@@ -0,0 +1,31 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yoc
+
+import (
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/forkid"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+)
+
+// enrEntry is the node record entry which advertises the yoc protocol and the
+// fork the local chain is on.
+type enrEntry struct {
+	ForkID forkid.ID // Fork identifier per EIP-2124
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// ENRKey implements enr.Entry.
+func (e enrEntry) ENRKey() string {
+	return ProtocolName
+}
+
+// currentENREntry constructs an enrEntry based on the current state of the chain.
+func currentENREntry(chain *core.BlockChain) *enrEntry {
+	return &enrEntry{
+		ForkID: forkid.NewID(chain),
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/event"
+	"github.com/Yocoin15/Yocoin_Sources/internal/yocapi"
+	"github.com/Yocoin15/Yocoin_Sources/log"
 	"github.com/Yocoin15/Yocoin_Sources/rpc"
 	"github.com/Yocoin15/Yocoin_Sources/yocdb"
 )
@@ -120,27 +122,68 @@ func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 	return pendingTxSub.ID
 }
 
+// PendingTxArgs configures a pending transaction subscription. FullTx streams
+// full transaction objects instead of hashes. The other fields restrict the
+// transactions notified of to those matching every non-empty list: sent from one
+// of the From addresses, to one of the To addresses and calling one of the
+// 4 byte method selectors.
+type PendingTxArgs struct {
+	FullTx  bool             `json:"fullTx"`
+	From    []common.Address `json:"from"`
+	To      []common.Address `json:"to"`
+	Methods []hexutil.Bytes  `json:"methods"`
+}
+
+// criteria converts the arguments into pending transaction filter criteria.
+func (args *PendingTxArgs) criteria() (PendingTxCriteria, error) {
+	crit := PendingTxCriteria{From: args.From, To: args.To}
+	for _, method := range args.Methods {
+		if len(method) != 4 {
+			return PendingTxCriteria{}, fmt.Errorf("invalid method selector %s, want 4 bytes", method)
+		}
+		var selector [4]byte
+		copy(selector[:], method)
+		crit.Methods = append(crit.Methods, selector)
+	}
+	return crit, nil
+}
+
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
-func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+// The optional arguments request full transactions instead of hashes and filter
+// the transactions notified of.
+func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context, args *PendingTxArgs) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-
+	var (
+		crit   PendingTxCriteria
+		fullTx bool
+	)
+	if args != nil {
+		var err error
+		if crit, err = args.criteria(); err != nil {
+			return nil, err
+		}
+		fullTx = args.FullTx
+	}
 	rpcSub := notifier.CreateSubscription()
 
 	go func() {
-		txHashes := make(chan []common.Hash, 128)
-		pendingTxSub := api.events.SubscribePendingTxs(txHashes)
+		txs := make(chan []*types.Transaction, 128)
+		pendingTxSub := api.events.SubscribeFullPendingTxs(crit, txs)
 
 		for {
 			select {
-			case hashes := <-txHashes:
-				// To keep the original behaviour, send a single tx hash in one notification.
-				// TODO(rjl493456442) Send a batch of tx hashes in one notification
-				for _, h := range hashes {
-					notifier.Notify(rpcSub.ID, h)
+			case batch := <-txs:
+				// To keep the original behaviour, send a single tx in one notification.
+				for _, tx := range batch {
+					if fullTx {
+						notifier.Notify(rpcSub.ID, yocapi.NewRPCPendingTransaction(tx))
+					} else {
+						notifier.Notify(rpcSub.ID, tx.Hash())
+					}
 				}
 			case <-rpcSub.Err():
 				pendingTxSub.Unsubscribe()
@@ -220,6 +263,70 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 	return rpcSub, nil
 }
 
+// NewBlockReceipts sends a notification with the receipts of all the transactions
+// each time a new block is appended to the chain. The receipts are in the same
+// format as returned by yoc_getBlockReceipts.
+func (api *PublicFilterAPI) NewBlockReceipts(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		headers := make(chan *types.Header)
+		headersSub := api.events.SubscribeNewHeads(headers)
+
+		for {
+			select {
+			case h := <-headers:
+				receipts, err := api.blockReceipts(h.Hash())
+				if err != nil {
+					log.Warn("Failed to retrieve block receipts", "number", h.Number, "hash", h.Hash(), "err", err)
+					continue
+				}
+				notifier.Notify(rpcSub.ID, receipts)
+			case <-rpcSub.Err():
+				headersSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				headersSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// blockReceipts retrieves the receipts of the given block along with their
+// transaction context, in their RPC representation.
+func (api *PublicFilterAPI) blockReceipts(hash common.Hash) ([]map[string]interface{}, error) {
+	ctx := context.Background()
+
+	block, err := api.backend.GetBlock(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %x not found", hash)
+	}
+	receipts, err := api.backend.GetReceipts(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if len(txs) != len(receipts) {
+		return nil, fmt.Errorf("receipt count mismatch: %d transactions, %d receipts", len(txs), len(receipts))
+	}
+	result := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		result[i] = yocapi.MarshalReceipt(receipt, hash, block.NumberU64(), txs[i], i)
+	}
+	return result, nil
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
@@ -6,6 +6,7 @@
 package filters
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -35,8 +36,8 @@ const (
 	PendingLogsSubscription
 	// MinedAndPendingLogsSubscription queries for logs in mined and pending blocks.
 	MinedAndPendingLogsSubscription
-	// PendingTransactionsSubscription queries tx hashes for pending
-	// transactions entering the pending state
+	// PendingTransactionsSubscription queries tx hashes or full transactions
+	// for pending transactions entering the pending state
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
@@ -66,8 +67,10 @@ type subscription struct {
 	typ       Type
 	created   time.Time
 	logsCrit  yocoin.FilterQuery
+	txsCrit   PendingTxCriteria
 	logs      chan []*types.Log
 	hashes    chan []common.Hash
+	txs       chan []*types.Transaction
 	headers   chan *types.Header
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
@@ -161,6 +164,7 @@ func (sub *Subscription) Unsubscribe() {
 				break uninstallLoop
 			case <-sub.f.logs:
 			case <-sub.f.hashes:
+			case <-sub.f.txs:
 			case <-sub.f.headers:
 			}
 		}
@@ -228,6 +232,7 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit yocoin.FilterQuery, logs c
 		created:   time.Now(),
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -245,6 +250,7 @@ func (es *EventSystem) subscribeLogs(crit yocoin.FilterQuery, logs chan []*types
 		created:   time.Now(),
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -262,6 +268,7 @@ func (es *EventSystem) subscribePendingLogs(crit yocoin.FilterQuery, logs chan [
 		created:   time.Now(),
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -278,6 +285,7 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 		created:   time.Now(),
 		logs:      make(chan []*types.Log),
 		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
 		headers:   headers,
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -301,6 +309,85 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscript
 	return es.subscribe(sub)
 }
 
+// SubscribeFullPendingTxs creates a subscription that writes the transactions
+// entering the transaction pool which match the given criteria.
+func (es *EventSystem) SubscribeFullPendingTxs(crit PendingTxCriteria, txs chan []*types.Transaction) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       PendingTransactionsSubscription,
+		txsCrit:   crit,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       txs,
+		headers:   make(chan *types.Header),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// PendingTxCriteria restricts the transactions a pending transaction
+// subscription is notified of. A transaction matches if it satisfies every
+// non-empty list, i.e. it's sent from one of the From addresses, to one of the
+// To addresses and its input starts with one of the method selectors.
+type PendingTxCriteria struct {
+	From    []common.Address
+	To      []common.Address
+	Methods [][4]byte
+}
+
+// matches reports whether the transaction satisfies the criteria.
+func (crit *PendingTxCriteria) matches(tx *types.Transaction) bool {
+	if len(crit.To) > 0 {
+		to := tx.To()
+		if to == nil || !includes(crit.To, *to) {
+			return false
+		}
+	}
+	if len(crit.Methods) > 0 {
+		data := tx.Data()
+		if len(data) < 4 {
+			return false
+		}
+		var matched bool
+		for _, method := range crit.Methods {
+			if bytes.Equal(method[:], data[:4]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	// Recover the sender last, being the most expensive check
+	if len(crit.From) > 0 {
+		var signer types.Signer = types.FrontierSigner{}
+		if tx.Protected() {
+			signer = types.NewEIP155Signer(tx.ChainId())
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil || !includes(crit.From, from) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterTxs returns the transactions matching the given criteria.
+func filterTxs(txs []*types.Transaction, crit PendingTxCriteria) []*types.Transaction {
+	if len(crit.From) == 0 && len(crit.To) == 0 && len(crit.Methods) == 0 {
+		return txs
+	}
+	var ret []*types.Transaction
+	for _, tx := range txs {
+		if crit.matches(tx) {
+			ret = append(ret, tx)
+		}
+	}
+	return ret
+}
+
 type filterIndex map[Type]map[rpc.ID]*subscription
 
 // broadcast event to filters that match criteria.
@@ -340,7 +427,11 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 			hashes = append(hashes, tx.Hash())
 		}
 		for _, f := range filters[PendingTransactionsSubscription] {
-			f.hashes <- hashes
+			if f.txs == nil {
+				f.hashes <- hashes
+			} else if matched := filterTxs(e.Txs, f.txsCrit); len(matched) > 0 {
+				f.txs <- matched
+			}
 		}
 	case core.ChainEvent:
 		for _, f := range filters[BlocksSubscription] {
@@ -19,6 +19,7 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/core/bloombits"
 	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
 	"github.com/Yocoin15/Yocoin_Sources/event"
 	"github.com/Yocoin15/Yocoin_Sources/params"
 	"github.com/Yocoin15/Yocoin_Sources/rpc"
@@ -70,6 +71,13 @@ func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*type
 	return rawdb.ReadHeader(b.db, hash, *number), nil
 }
 
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if number := rawdb.ReadHeaderNumber(b.db, hash); number != nil {
+		return rawdb.ReadBlock(b.db, hash, *number), nil
+	}
+	return nil, nil
+}
+
 func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	if number := rawdb.ReadHeaderNumber(b.db, hash); number != nil {
 		return rawdb.ReadReceipts(b.db, hash, *number), nil
@@ -260,6 +268,77 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestFullPendingTxSubscription tests whether full pending transaction
+// subscriptions only receive the transactions matching their criteria.
+func TestFullPendingTxSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux        = new(event.TypeMux)
+		db         = yocdb.NewMemDatabase()
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		api        = NewPublicFilterAPI(backend, false)
+
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		signer   = types.NewEIP155Signer(big.NewInt(1))
+		target   = common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268")
+		selector = [4]byte{0xa9, 0x05, 0x9c, 0xbb}
+		call     = append(selector[:], make([]byte, 32)...)
+	)
+	sign := func(tx *types.Transaction) *types.Transaction {
+		signed, _ := types.SignTx(tx, signer, key)
+		return signed
+	}
+	transactions := []*types.Transaction{
+		sign(types.NewTransaction(0, target, new(big.Int), 0, new(big.Int), call)),
+		sign(types.NewTransaction(1, target, new(big.Int), 0, new(big.Int), nil)),
+		sign(types.NewTransaction(2, common.Address{}, new(big.Int), 0, new(big.Int), call)),
+		types.NewTransaction(3, target, new(big.Int), 0, new(big.Int), call),
+	}
+	tests := []struct {
+		crit PendingTxCriteria
+		want []*types.Transaction
+	}{
+		{PendingTxCriteria{}, transactions},
+		{PendingTxCriteria{To: []common.Address{target}}, []*types.Transaction{transactions[0], transactions[1], transactions[3]}},
+		{PendingTxCriteria{Methods: [][4]byte{selector}}, []*types.Transaction{transactions[0], transactions[2], transactions[3]}},
+		{PendingTxCriteria{From: []common.Address{sender}}, transactions[:3]},
+		{PendingTxCriteria{From: []common.Address{sender}, To: []common.Address{target}, Methods: [][4]byte{selector}}, transactions[:1]},
+	}
+	var (
+		chans = make([]chan []*types.Transaction, len(tests))
+		subs  = make([]*Subscription, len(tests))
+	)
+	for i, test := range tests {
+		chans[i] = make(chan []*types.Transaction, 1)
+		subs[i] = api.events.SubscribeFullPendingTxs(test.crit, chans[i])
+		defer subs[i].Unsubscribe()
+	}
+	txFeed.Send(core.NewTxsEvent{Txs: transactions})
+
+	for i, test := range tests {
+		select {
+		case have := <-chans[i]:
+			if len(have) != len(test.want) {
+				t.Errorf("test %d: invalid number of transactions, want %d, got %d", i, len(test.want), len(have))
+				continue
+			}
+			for j := range have {
+				if have[j].Hash() != test.want[j].Hash() {
+					t.Errorf("test %d: tx %d mismatch, want %x, got %x", i, j, test.want[j].Hash(), have[j].Hash())
+				}
+			}
+		case <-time.After(time.Second):
+			t.Errorf("test %d: timeout waiting for transactions", i)
+		}
+	}
+}
+
 // TestLogFilterCreation test whether a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
@@ -601,3 +680,75 @@ func TestPendingLogsSubscription(t *testing.T) {
 		}
 	}
 }
+
+// TestBlockReceiptsSubscription tests that a newBlockReceipts subscription
+// delivers the receipts of every block posted to the chain feed.
+func TestBlockReceiptsSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux        = new(event.TypeMux)
+		db         = yocdb.NewMemDatabase()
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		api        = NewPublicFilterAPI(backend, false)
+		key, _     = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr       = crypto.PubkeyToAddress(key.PublicKey)
+		genesis    = core.GenesisBlockForTesting(db, addr, big.NewInt(1000000000000000000))
+	)
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, yochash.NewFaker(), db, 3, func(i int, gen *core.BlockGen) {
+		for j := 0; j < i; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+			gen.AddTx(tx)
+		}
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	// Subscribe to the receipts through an in-process RPC server
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("yoc", api); err != nil {
+		t.Fatalf("failed to register filter API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan []*types.Receipt)
+	sub, err := client.YocSubscribe(context.Background(), ch, "newBlockReceipts")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	time.Sleep(100 * time.Millisecond)
+	for _, block := range chain {
+		chainFeed.Send(core.ChainEvent{Hash: block.Hash(), Block: block})
+	}
+	for i, block := range chain {
+		select {
+		case have := <-ch:
+			if len(have) != len(block.Transactions()) {
+				t.Fatalf("block %d: receipt count mismatch: have %d, want %d", i, len(have), len(block.Transactions()))
+			}
+			for j, receipt := range have {
+				if receipt.TxHash != block.Transactions()[j].Hash() {
+					t.Errorf("block %d, receipt %d: tx hash mismatch: have %x, want %x", i, j, receipt.TxHash, block.Transactions()[j].Hash())
+				}
+				if receipt.CumulativeGasUsed != receipts[i][j].CumulativeGasUsed {
+					t.Errorf("block %d, receipt %d: cumulative gas mismatch: have %d, want %d", i, j, receipt.CumulativeGasUsed, receipts[i][j].CumulativeGasUsed)
+				}
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("block %d: timeout waiting for receipts", i)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+)
+
+const (
+	// maxFeeHistory is the maximum number of blocks a fee history may span.
+	maxFeeHistory = 1024
+
+	// normalBlocks and slowBlocks are the number of blocks the pending pool is
+	// expected to be drained in at the normal and slow price suggestions.
+	normalBlocks = 3
+	slowBlocks   = 10
+)
+
+var errInvalidPercentile = errors.New("invalid reward percentile")
+
+// FeeHistory returns the gas used ratio and the requested gas price percentiles
+// of up to blocks consecutive blocks ending with lastBlock, along with the number
+// of the oldest block returned. The percentiles are weighted by the gas used by
+// the transactions of each block. The pending block is resolved to the latest.
+func (gpo *Oracle) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []float64, error) {
+	if blocks < 1 {
+		return new(big.Int), nil, nil, nil
+	}
+	if blocks > maxFeeHistory {
+		blocks = maxFeeHistory
+	}
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 {
+			return nil, nil, nil, fmt.Errorf("%v: %f", errInvalidPercentile, p)
+		}
+		if i > 0 && p < rewardPercentiles[i-1] {
+			return nil, nil, nil, fmt.Errorf("%v: #%d:%f > #%d:%f", errInvalidPercentile, i-1, rewardPercentiles[i-1], i, p)
+		}
+	}
+	if lastBlock == rpc.PendingBlockNumber {
+		lastBlock = rpc.LatestBlockNumber
+	}
+	head, err := gpo.backend.HeaderByNumber(ctx, lastBlock)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if head == nil {
+		return nil, nil, nil, fmt.Errorf("block #%d not found", lastBlock)
+	}
+	last := head.Number.Uint64()
+	if uint64(blocks) > last+1 {
+		blocks = int(last + 1)
+	}
+	oldest := last + 1 - uint64(blocks)
+
+	var (
+		reward       [][]*big.Int
+		gasUsedRatio = make([]float64, blocks)
+	)
+	if len(rewardPercentiles) > 0 {
+		reward = make([][]*big.Int, blocks)
+	}
+	for i := 0; i < blocks; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+		block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(oldest+uint64(i)))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if block == nil {
+			return nil, nil, nil, fmt.Errorf("block #%d not found", oldest+uint64(i))
+		}
+		if block.GasLimit() > 0 {
+			gasUsedRatio[i] = float64(block.GasUsed()) / float64(block.GasLimit())
+		}
+		if reward != nil {
+			if reward[i], err = gpo.blockRewards(ctx, block, rewardPercentiles); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	return new(big.Int).SetUint64(oldest), reward, gasUsedRatio, nil
+}
+
+// txGasAndPrice is the gas used and gas price of a single transaction.
+type txGasAndPrice struct {
+	gasUsed  uint64
+	gasPrice *big.Int
+}
+
+// blockRewards returns the gas prices paid at the given percentiles of the gas
+// used by the block, or zeroes if it is empty.
+func (gpo *Oracle) blockRewards(ctx context.Context, block *types.Block, percentiles []float64) ([]*big.Int, error) {
+	reward := make([]*big.Int, len(percentiles))
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		for i := range reward {
+			reward[i] = new(big.Int)
+		}
+		return reward, nil
+	}
+	receipts, err := gpo.backend.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(txs) {
+		return nil, fmt.Errorf("receipts of block #%d unavailable", block.NumberU64())
+	}
+	sorted := make([]txGasAndPrice, len(txs))
+	for i, tx := range txs {
+		sorted[i] = txGasAndPrice{gasUsed: receipts[i].GasUsed, gasPrice: tx.GasPrice()}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].gasPrice.Cmp(sorted[j].gasPrice) < 0 })
+
+	var txIndex int
+	sumGasUsed := sorted[0].gasUsed
+	for i, p := range percentiles {
+		threshold := uint64(float64(block.GasUsed()) * p / 100)
+		for sumGasUsed < threshold && txIndex < len(sorted)-1 {
+			txIndex++
+			sumGasUsed += sorted[txIndex].gasUsed
+		}
+		reward[i] = new(big.Int).Set(sorted[txIndex].gasPrice)
+	}
+	return reward, nil
+}
+
+// SuggestPrices returns slow, normal and fast gas price suggestions. They are
+// picked from the prices of recent blocks around the configured percentile, and
+// raised if needed to outbid the pending transactions expected to be included
+// in the next few blocks.
+func (gpo *Oracle) SuggestPrices(ctx context.Context) (slow, normal, fast *big.Int, err error) {
+	if normal, err = gpo.SuggestPrice(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+	gpo.cacheLock.RLock()
+	prices := gpo.lastPrices
+	gpo.cacheLock.RUnlock()
+
+	slow, fast = normal, normal
+	if n := len(prices); n > 0 {
+		slow = prices[(n-1)*gpo.percentile/200]
+		fast = prices[(n-1)*(100+gpo.percentile)/200]
+	}
+	// Outbid the pending transactions that fill the upcoming blocks
+	head, err := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if head != nil && head.GasLimit > 0 {
+		pending, _ := gpo.backend.TxPoolContent()
+		slow = maxBig(slow, poolPrice(pending, slowBlocks*head.GasLimit))
+		normal = maxBig(normal, poolPrice(pending, normalBlocks*head.GasLimit))
+		fast = maxBig(fast, poolPrice(pending, head.GasLimit))
+	}
+	return minBig(slow, maxPrice), minBig(normal, maxPrice), minBig(fast, maxPrice), nil
+}
+
+// poolPrice returns the gas price of the pending transaction at which the gas
+// limits of the transactions paying at least as much exceed the given allowance,
+// or zero if all of them fit.
+func poolPrice(pending map[common.Address]types.Transactions, gas uint64) *big.Int {
+	var txs []*types.Transaction
+	for _, list := range pending {
+		txs = append(txs, list...)
+	}
+	sort.Sort(sort.Reverse(transactionsByGasPrice(txs)))
+
+	var total uint64
+	for _, tx := range txs {
+		total += tx.Gas()
+		if total > gas {
+			return tx.GasPrice()
+		}
+	}
+	return new(big.Int)
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func minBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
@@ -0,0 +1,237 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/internal/yocapi"
+	"github.com/Yocoin15/Yocoin_Sources/params"
+	"github.com/Yocoin15/Yocoin_Sources/rpc"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+func TestPoolPrice(t *testing.T) {
+	tx := func(nonce uint64, gas uint64, price int64) *types.Transaction {
+		return types.NewTransaction(nonce, common.Address{}, new(big.Int), gas, big.NewInt(price), nil)
+	}
+	pending := map[common.Address]types.Transactions{
+		common.Address{1}: {tx(0, 21000, 10), tx(1, 21000, 30)},
+		common.Address{2}: {tx(0, 50000, 20)},
+	}
+	tests := []struct {
+		gas   uint64
+		price int64
+	}{
+		{100000, 0},  // everything fits
+		{92000, 0},   // exactly fits
+		{71000, 10},  // cheapest transaction left out
+		{21000, 20},  // only the most expensive one fits
+		{20000, 30},  // nothing fits
+		{0, 30},      // no allowance at all
+		{1 << 62, 0}, // huge allowance
+	}
+	for i, test := range tests {
+		if price := poolPrice(pending, test.gas); price.Int64() != test.price {
+			t.Errorf("test %d: price mismatch: have %v, want %d", i, price, test.price)
+		}
+	}
+}
+
+// testBackend serves the oracle from a chain of blocks generated in memory and a
+// fixed set of pending transactions. Calling any method not overridden below
+// panics.
+type testBackend struct {
+	yocapi.Backend
+
+	chain   *core.BlockChain
+	pending map[common.Address]types.Transactions
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock().Header(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.chain.GetReceiptsByHash(hash), nil
+}
+
+func (b *testBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
+	return b.pending, nil
+}
+
+// newTestBackend creates a backend over a chain of 8 blocks. Every block k but
+// the empty 4th one holds two transfers paying gas prices of k and 2k wei.
+func newTestBackend(t *testing.T, pending map[common.Address]types.Transactions) *testBackend {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		config = params.TestChainConfig
+		gspec  = &core.Genesis{
+			Config: config,
+			Alloc:  core.GenesisAlloc{addr: {Balance: big.NewInt(params.YOC)}},
+		}
+		db      = yocdb.NewMemDatabase()
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := core.GenerateChain(config, genesis, yochash.NewFaker(), db, 8, func(i int, b *core.BlockGen) {
+		number := int64(i + 1)
+		if number == 4 {
+			return
+		}
+		signer := types.MakeSigner(config, big.NewInt(number))
+		for _, price := range []int64{number, 2 * number} {
+			tx, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0xff}, big.NewInt(1), params.TxGas, big.NewInt(price), nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			b.AddTx(tx)
+		}
+	})
+	chain, err := core.NewBlockChain(db, nil, config, yochash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	return &testBackend{chain: chain, pending: pending}
+}
+
+func TestFeeHistory(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	defer backend.chain.Stop()
+
+	oracle := NewOracle(backend, Config{Blocks: 5, Percentile: 60})
+
+	tests := []struct {
+		blocks      int
+		last        rpc.BlockNumber
+		percentiles []float64
+		oldest      uint64
+		count       int       // number of blocks expected in the history
+		reward      [][]int64 // expected rewards, nil if not requested
+		err         bool
+	}{
+		{blocks: 0, last: rpc.LatestBlockNumber, oldest: 0, count: 0},
+		{blocks: 3, last: rpc.LatestBlockNumber, oldest: 6, count: 3},
+		{blocks: 1, last: rpc.PendingBlockNumber, oldest: 8, count: 1},
+		{blocks: 100, last: 2, oldest: 0, count: 3},
+		{blocks: 2 * maxFeeHistory, last: rpc.LatestBlockNumber, oldest: 0, count: 9},
+		{blocks: 3, last: 5, percentiles: []float64{0, 50, 100}, oldest: 3, count: 3, reward: [][]int64{{3, 3, 6}, {0, 0, 0}, {5, 5, 10}}},
+		{blocks: 1, last: 8, percentiles: []float64{25, 51, 75}, oldest: 8, count: 1, reward: [][]int64{{8, 16, 16}}},
+		{blocks: 1, last: 0, percentiles: []float64{50}, oldest: 0, count: 1, reward: [][]int64{{0}}},
+		{blocks: 1, last: 20, err: true},
+		{blocks: 1, last: 8, percentiles: []float64{50, 10}, err: true},
+		{blocks: 1, last: 8, percentiles: []float64{-1}, err: true},
+		{blocks: 1, last: 8, percentiles: []float64{101}, err: true},
+	}
+	for i, tt := range tests {
+		oldest, reward, ratio, err := oracle.FeeHistory(context.Background(), tt.blocks, tt.last, tt.percentiles)
+		if tt.err {
+			if err == nil {
+				t.Errorf("test %d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to retrieve fee history: %v", i, err)
+			continue
+		}
+		if oldest.Uint64() != tt.oldest {
+			t.Errorf("test %d: oldest block mismatch: have %v, want %d", i, oldest, tt.oldest)
+		}
+		if len(ratio) != tt.count {
+			t.Errorf("test %d: gas used ratio count mismatch: have %d, want %d", i, len(ratio), tt.count)
+		}
+		for j := range ratio {
+			block := backend.chain.GetBlockByNumber(tt.oldest + uint64(j))
+			if want := float64(block.GasUsed()) / float64(block.GasLimit()); ratio[j] != want {
+				t.Errorf("test %d, block %d: gas used ratio mismatch: have %f, want %f", i, block.NumberU64(), ratio[j], want)
+			}
+		}
+		if tt.reward == nil {
+			if reward != nil {
+				t.Errorf("test %d: unexpected rewards: %v", i, reward)
+			}
+			continue
+		}
+		if len(reward) != len(tt.reward) {
+			t.Errorf("test %d: reward count mismatch: have %d, want %d", i, len(reward), len(tt.reward))
+			continue
+		}
+		for j, want := range tt.reward {
+			for k, price := range want {
+				if reward[j][k].Int64() != price {
+					t.Errorf("test %d, block %d, percentile %v: reward mismatch: have %v, want %d", i, tt.oldest+uint64(j), tt.percentiles[k], reward[j][k], price)
+				}
+			}
+		}
+	}
+}
+
+func TestSuggestPrices(t *testing.T) {
+	// Pending transactions each take up the gas of an entire block
+	probe := newTestBackend(t, nil)
+	gasLimit := probe.chain.CurrentBlock().GasLimit()
+	probe.chain.Stop()
+
+	tx := func(nonce uint64, price int64) *types.Transaction {
+		return types.NewTransaction(nonce, common.Address{}, new(big.Int), gasLimit, big.NewInt(price), nil)
+	}
+	tests := []struct {
+		pending            map[common.Address]types.Transactions
+		slow, normal, fast int64
+	}{
+		// Block prices are 5, 6, 7 and 8 wei, the empty 4th block is skipped and
+		// the 60th percentile of them is suggested regardless of the pool
+		{nil, 5, 6, 7},
+		// Pending transactions overflowing the next block raise the fast price
+		{map[common.Address]types.Transactions{{1}: {tx(0, 100)}, {2}: {tx(0, 50)}}, 5, 6, 50},
+		// ... and the normal one too when overflowing the next few blocks
+		{map[common.Address]types.Transactions{{1}: {tx(0, 100), tx(1, 90), tx(2, 80)}, {2}: {tx(0, 50)}}, 5, 50, 90},
+		// Prices are capped by the maximum
+		{map[common.Address]types.Transactions{{1}: {tx(0, 1000*params.Shannon), tx(1, 1000*params.Shannon)}}, 5, 6, maxPrice.Int64()},
+	}
+	for i, tt := range tests {
+		backend := newTestBackend(t, tt.pending)
+		oracle := NewOracle(backend, Config{Blocks: 5, Percentile: 60})
+
+		price, err := oracle.SuggestPrice(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: failed to suggest price: %v", i, err)
+		}
+		if price.Int64() != 6 {
+			t.Errorf("test %d: suggested price mismatch: have %v, want %d", i, price, 6)
+		}
+		slow, normal, fast, err := oracle.SuggestPrices(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: failed to suggest prices: %v", i, err)
+		}
+		if slow.Int64() != tt.slow || normal.Int64() != tt.normal || fast.Int64() != tt.fast {
+			t.Errorf("test %d: suggestions mismatch: have %v/%v/%v, want %d/%d/%d", i, slow, normal, fast, tt.slow, tt.normal, tt.fast)
+		}
+		backend.chain.Stop()
+	}
+}
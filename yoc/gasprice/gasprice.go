@@ -27,11 +27,12 @@ type Config struct {
 // Oracle recommends gas prices based on the content of recent
 // blocks. Suitable for both light and full clients.
 type Oracle struct {
-	backend   yocapi.Backend
-	lastHead  common.Hash
-	lastPrice *big.Int
-	cacheLock sync.RWMutex
-	fetchLock sync.Mutex
+	backend    yocapi.Backend
+	lastHead   common.Hash
+	lastPrice  *big.Int
+	lastPrices []*big.Int // sorted block prices the last price was picked from
+	cacheLock  sync.RWMutex
+	fetchLock  sync.Mutex
 
 	checkBlocks, maxEmpty, maxBlocks int
 	percentile                       int
@@ -130,6 +131,7 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	gpo.cacheLock.Lock()
 	gpo.lastHead = headHash
 	gpo.lastPrice = price
+	gpo.lastPrices = blockPrices
 	gpo.cacheLock.Unlock()
 	return price, nil
 }
@@ -25,6 +25,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SkipBcVersionCheck      bool `toml:"-"`
 		DatabaseHandles         int  `toml:"-"`
 		DatabaseCache           int
+		DatabaseFreezer         string
+		SnapshotCache           int
 		Yocbase                 common.Address `toml:",omitempty"`
 		MinerThreads            int            `toml:",omitempty"`
 		ExtraData               hexutil.Bytes  `toml:",omitempty"`
@@ -33,6 +35,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
+		TraceIndex              bool
+		TraceCache              string `toml:",omitempty"`
+		TraceCacheBlocks        uint64 `toml:",omitempty"`
 		DocRoot                 string `toml:"-"`
 	}
 	var enc Config
@@ -44,6 +49,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
+	enc.DatabaseFreezer = c.DatabaseFreezer
+	enc.SnapshotCache = c.SnapshotCache
 	enc.Yocbase = c.Yocbase
 	enc.MinerThreads = c.MinerThreads
 	enc.ExtraData = c.ExtraData
@@ -52,6 +59,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.TraceIndex = c.TraceIndex
+	enc.TraceCache = c.TraceCache
+	enc.TraceCacheBlocks = c.TraceCacheBlocks
 	enc.DocRoot = c.DocRoot
 	return &enc, nil
 }
@@ -66,6 +76,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SkipBcVersionCheck      *bool `toml:"-"`
 		DatabaseHandles         *int  `toml:"-"`
 		DatabaseCache           *int
+		DatabaseFreezer         *string
+		SnapshotCache           *int
 		Yocbase                 *common.Address `toml:",omitempty"`
 		MinerThreads            *int            `toml:",omitempty"`
 		ExtraData               *hexutil.Bytes  `toml:",omitempty"`
@@ -74,6 +86,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
+		TraceIndex              *bool
+		TraceCache              *string `toml:",omitempty"`
+		TraceCacheBlocks        *uint64 `toml:",omitempty"`
 		DocRoot                 *string `toml:"-"`
 	}
 	var dec Config
@@ -104,6 +119,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseCache != nil {
 		c.DatabaseCache = *dec.DatabaseCache
 	}
+	if dec.DatabaseFreezer != nil {
+		c.DatabaseFreezer = *dec.DatabaseFreezer
+	}
+	if dec.SnapshotCache != nil {
+		c.SnapshotCache = *dec.SnapshotCache
+	}
 	if dec.Yocbase != nil {
 		c.Yocbase = *dec.Yocbase
 	}
@@ -128,6 +149,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.TraceIndex != nil {
+		c.TraceIndex = *dec.TraceIndex
+	}
+	if dec.TraceCache != nil {
+		c.TraceCache = *dec.TraceCache
+	}
+	if dec.TraceCacheBlocks != nil {
+		c.TraceCacheBlocks = *dec.TraceCacheBlocks
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
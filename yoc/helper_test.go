@@ -17,6 +17,7 @@ import (
 	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/consensus/yochash"
 	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/forkid"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/core/vm"
 	"github.com/Yocoin15/Yocoin_Sources/crypto"
@@ -61,6 +62,33 @@ func newTestProtocolManager(mode downloader.SyncMode, blocks int, generator func
 	return pm, db, nil
 }
 
+// newTestYoCoin creates a YoCoin service backed by a chain generated in memory
+// from the given genesis allocation, sufficient to serve the tracing APIs.
+func newTestYoCoin(t *testing.T, alloc core.GenesisAlloc, blocks int, generator func(int, *core.BlockGen)) *YoCoin {
+	return newTestYoCoinWithConfig(t, params.TestChainConfig, alloc, blocks, generator)
+}
+
+// newTestYoCoinWithConfig is like newTestYoCoin, but runs the chain with the given
+// configuration.
+func newTestYoCoinWithConfig(t *testing.T, config *params.ChainConfig, alloc core.GenesisAlloc, blocks int, generator func(int, *core.BlockGen)) *YoCoin {
+	var (
+		engine = yochash.NewFaker()
+		db     = yocdb.NewMemDatabase()
+		gspec  = &core.Genesis{Config: config, Alloc: alloc}
+	)
+	genesis := gspec.MustCommit(db)
+	chain, _ := core.GenerateChain(gspec.Config, genesis, engine, db, blocks, generator)
+
+	blockchain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	return &YoCoin{chainDb: db, chainConfig: gspec.Config, engine: engine, blockchain: blockchain}
+}
+
 // newTestProtocolManagerMust creates a new protocol manager for testing purposes,
 // with the given number of blocks already known, and potential notification
 // channels for different events. In case of an error, the constructor force-
@@ -158,20 +186,32 @@ func newTestPeer(name string, version int, pm *ProtocolManager, shake bool) (*te
 			head    = pm.blockchain.CurrentHeader()
 			td      = pm.blockchain.GetTd(head.Hash(), head.Number.Uint64())
 		)
-		tp.handshake(nil, td, head.Hash(), genesis.Hash())
+		tp.handshake(nil, td, head.Hash(), genesis.Hash(), forkid.NewID(pm.blockchain))
 	}
 	return tp, errc
 }
 
 // handshake simulates a trivial handshake that expects the same state from the
 // remote side as we are simulating locally.
-func (p *testPeer) handshake(t *testing.T, td *big.Int, head common.Hash, genesis common.Hash) {
-	msg := &statusData{
-		ProtocolVersion: uint32(p.version),
-		NetworkId:       DefaultConfig.NetworkId,
-		TD:              td,
-		CurrentBlock:    head,
-		GenesisBlock:    genesis,
+func (p *testPeer) handshake(t *testing.T, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID) {
+	var msg interface{}
+	if p.version >= yoc64 {
+		msg = &statusData64{
+			ProtocolVersion: uint32(p.version),
+			NetworkId:       DefaultConfig.NetworkId,
+			TD:              td,
+			CurrentBlock:    head,
+			GenesisBlock:    genesis,
+			ForkID:          forkID,
+		}
+	} else {
+		msg = &statusData{
+			ProtocolVersion: uint32(p.version),
+			NetworkId:       DefaultConfig.NetworkId,
+			TD:              td,
+			CurrentBlock:    head,
+			GenesisBlock:    genesis,
+		}
 	}
 	if err := p2p.ExpectMsg(p.app, StatusMsg, msg); err != nil {
 		t.Fatalf("status recv: %v", err)
@@ -5,12 +5,12 @@ package yoc
 
 import (
 	"fmt"
-	"github.com/Yocoin15/Yocoin_Sources/nov2019"
 	"io"
 	"math/big"
 
 	"github.com/Yocoin15/Yocoin_Sources/common"
 	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/forkid"
 	"github.com/Yocoin15/Yocoin_Sources/core/types"
 	"github.com/Yocoin15/Yocoin_Sources/event"
 	"github.com/Yocoin15/Yocoin_Sources/rlp"
@@ -20,31 +20,17 @@ import (
 const (
 	yoc62 = 62
 	yoc63 = 63
+	yoc64 = 64
 )
 
-// Official short name of the protocol used during capability negotiation.
-var (
-	ProtocolName string
+// ProtocolName is the official short name of the protocol used during capability negotiation.
+const ProtocolName = "eth"
 
-	// ProtocolVersions are the supported versions of the yoc protocol (first is primary).
-	ProtocolVersions []uint
+// ProtocolVersions are the supported versions of the yoc protocol (first is primary).
+var ProtocolVersions = []uint{yoc64, yoc63, yoc62}
 
-	// ProtocolLengths are the number of implemented message corresponding to different protocol versions.
-	ProtocolLengths []uint64
-)
-
-func init() {
-	// смена протокола на несовместимый со старым, пока что при NOV2019_ENABLE_NET_UPGRADE=1
-	if nov2019.GetNov2019MigrationManager().IsNetUpgradeToIncompatible() {
-		ProtocolName = "y21"
-		ProtocolVersions = []uint{90}
-	} else {
-		ProtocolName = "eth"
-		ProtocolVersions = []uint{yoc62, yoc63}
-	}
-	// пока оставим
-	ProtocolLengths = []uint64{17, 8}
-}
+// ProtocolLengths are the number of implemented message corresponding to different protocol versions.
+var ProtocolLengths = []uint64{17, 17, 8}
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -79,6 +65,7 @@ const (
 	ErrNoStatusMsg
 	ErrExtraStatusMsg
 	ErrSuspendedPeer
+	ErrForkIDRejected
 )
 
 func (e errCode) String() string {
@@ -96,6 +83,7 @@ var errorToString = map[int]string{
 	ErrNoStatusMsg:             "No status message",
 	ErrExtraStatusMsg:          "Extra status message",
 	ErrSuspendedPeer:           "Suspended peer",
+	ErrForkIDRejected:          "Fork ID rejected",
 }
 
 type txPool interface {
@@ -120,6 +108,17 @@ type statusData struct {
 	GenesisBlock    common.Hash
 }
 
+// statusData64 is the network packet for the status message since yoc/64,
+// extending the original one with the fork identifier of the chain.
+type statusData64 struct {
+	ProtocolVersion uint32
+	NetworkId       uint64
+	TD              *big.Int
+	CurrentBlock    common.Hash
+	GenesisBlock    common.Hash
+	ForkID          forkid.ID
+}
+
 // newBlockHashesData is the network packet for the block announcements.
 type newBlockHashesData []struct {
 	Hash   common.Hash // Hash of one particular block being announced
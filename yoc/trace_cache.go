@@ -0,0 +1,246 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yoc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+	"github.com/hashicorp/golang-lru"
+)
+
+// traceCacheChanSize is the size of channel listening to ChainHeadEvent.
+const traceCacheChanSize = 10
+
+// cachedBlockTraces is the database representation of the traces of a block.
+type cachedBlockTraces struct {
+	Tracer string            `json:"tracer"`          // Tracer the traces were produced with
+	Traces []json.RawMessage `json:"traces"`          // Results of the transactions of the block
+	Block  json.RawMessage   `json:"block,omitempty"` // Result of the changes made before the transactions
+}
+
+// blockTraceCache traces the newly imported blocks in the background with the
+// configured tracer, keeping the results of the recent blocks in a separate
+// database table for the tracing APIs to serve without re-executing them.
+type blockTraceCache struct {
+	db        yocdb.Database // Separate table holding the cached traces
+	chain     *core.BlockChain
+	api       *PrivateDebugAPI // Tracing API to produce the traces with
+	tracer    string           // Name of the tracer producing the cached traces
+	blocks    uint64           // Number of recent blocks to keep the traces of
+	processed *lru.Cache       // Hashes of the blocks already attempted to trace
+
+	ctx    context.Context    // Context interrupting the tracing on shutdown
+	cancel context.CancelFunc // Function to interrupt the tracing
+	wg     sync.WaitGroup
+}
+
+// newBlockTraceCache creates a trace cache and starts tracing the new heads of
+// the chain in the background.
+func newBlockTraceCache(yoc *YoCoin, tracer string, blocks uint64) *blockTraceCache {
+	processed, _ := lru.New(int(blocks))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cache := &blockTraceCache{
+		db:        yocdb.NewTable(yoc.chainDb, string(rawdb.TraceCachePrefix)),
+		chain:     yoc.blockchain,
+		api:       NewPrivateDebugAPI(yoc.chainConfig, yoc),
+		tracer:    tracer,
+		blocks:    blocks,
+		processed: processed,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	cache.wg.Add(1)
+	go cache.loop()
+
+	return cache
+}
+
+// stop interrupts any tracing in progress and terminates the background loop.
+func (c *blockTraceCache) stop() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// loop traces the blocks up to every new head of the chain, falling behind to
+// the latest head if tracing is slower than the block imports.
+func (c *blockTraceCache) loop() {
+	defer c.wg.Done()
+
+	headCh := make(chan core.ChainHeadEvent, traceCacheChanSize)
+	sub := c.chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-headCh:
+			for drained := false; !drained; {
+				select {
+				case ev = <-headCh:
+				default:
+					drained = true
+				}
+			}
+			c.update(ev.Block)
+
+		case <-sub.Err():
+			return
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// update traces the recent blocks leading up to the head not yet processed, and
+// drops the traces of the blocks falling out of the retention window.
+func (c *blockTraceCache) update(head *types.Block) {
+	var blocks []*types.Block
+	for block := head; block != nil && uint64(len(blocks)) < c.blocks; {
+		if c.processed.Contains(block.Hash()) || c.get(block, c.tracer) != nil {
+			break
+		}
+		blocks = append(blocks, block)
+		if block.NumberU64() == 0 {
+			break
+		}
+		block = c.chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if c.ctx.Err() != nil {
+			return
+		}
+		block := blocks[i]
+		c.processed.Add(block.Hash(), struct{}{})
+
+		results, err := c.api.traceBlock(c.ctx, block, &TraceConfig{Tracer: &c.tracer})
+		if err != nil {
+			log.Debug("Failed to trace block", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+			continue
+		}
+		c.put(block, results)
+	}
+	c.prune(head.NumberU64())
+}
+
+// get retrieves the traces of a block from the cache, or nil if the block was
+// not traced with the given tracer.
+func (c *blockTraceCache) get(block *types.Block, tracer string) []*txTraceResult {
+	hash, blob := rawdb.ReadBlockTraces(c.db, block.NumberU64())
+	if hash != block.Hash() {
+		return nil
+	}
+	var cached cachedBlockTraces
+	if err := json.Unmarshal(blob, &cached); err != nil {
+		log.Error("Invalid cached block traces", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+		return nil
+	}
+	if cached.Tracer != tracer || len(cached.Traces) != len(block.Transactions()) {
+		return nil
+	}
+	results := make([]*txTraceResult, len(cached.Traces))
+	for i, trace := range cached.Traces {
+		results[i] = &txTraceResult{Result: trace}
+	}
+	if cached.Block != nil {
+		results = append(results, &txTraceResult{Result: cached.Block, Block: true})
+	}
+	return results
+}
+
+// put stores the traces of a block into the cache, unless any of them failed,
+// leaving the block to be traced on demand.
+func (c *blockTraceCache) put(block *types.Block, results []*txTraceResult) {
+	cached := cachedBlockTraces{
+		Tracer: c.tracer,
+		Traces: make([]json.RawMessage, 0, len(results)),
+	}
+	for i, res := range results {
+		if res.Error != "" {
+			log.Debug("Not caching failed block trace", "number", block.NumberU64(), "hash", block.Hash(), "tx", i, "err", res.Error)
+			return
+		}
+		blob, err := json.Marshal(res.Result)
+		if err != nil {
+			log.Error("Failed to encode block trace", "number", block.NumberU64(), "hash", block.Hash(), "tx", i, "err", err)
+			return
+		}
+		if res.Block {
+			cached.Block = blob
+			continue
+		}
+		cached.Traces = append(cached.Traces, blob)
+	}
+	blob, err := json.Marshal(&cached)
+	if err != nil {
+		log.Error("Failed to encode block traces", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+		return
+	}
+	if rawdb.ReadTraceCacheTail(c.db) == nil {
+		rawdb.WriteTraceCacheTail(c.db, block.NumberU64())
+	}
+	rawdb.WriteBlockTraces(c.db, block.Hash(), block.NumberU64(), blob)
+}
+
+// prune deletes the traces of the blocks older than the retention window of
+// the given head.
+func (c *blockTraceCache) prune(head uint64) {
+	if head < c.blocks {
+		return
+	}
+	limit := head - c.blocks + 1
+
+	tail := rawdb.ReadTraceCacheTail(c.db)
+	if tail == nil || *tail >= limit {
+		return
+	}
+	batch := c.db.NewBatch()
+	for number := *tail; number < limit; number++ {
+		rawdb.DeleteBlockTraces(batch, number)
+		if batch.ValueSize() >= yocdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Error("Failed to prune trace cache", "err", err)
+				return
+			}
+			batch.Reset()
+		}
+	}
+	rawdb.WriteTraceCacheTail(batch, limit)
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to prune trace cache", "err", err)
+	}
+}
+
+// cachedTraces retrieves the traces of a block produced in the background, if
+// the trace cache is enabled and ran the tracer requested by the config.
+func (api *PrivateDebugAPI) cachedTraces(block *types.Block, config *TraceConfig) []*txTraceResult {
+	if api.yoc.traceCache == nil || config == nil || config.Tracer == nil {
+		return nil
+	}
+	return api.yoc.traceCache.get(block, *config.Tracer)
+}
+
+// cachedTxTrace retrieves the trace of a transaction produced in the background,
+// if the trace cache is enabled and ran the tracer requested by the config.
+func (api *PrivateDebugAPI) cachedTxTrace(blockHash common.Hash, index uint64, config *TraceConfig) interface{} {
+	if api.yoc.traceCache == nil {
+		return nil
+	}
+	block := api.yoc.blockchain.GetBlockByHash(blockHash)
+	if block == nil {
+		return nil
+	}
+	results := api.cachedTraces(block, config)
+	if index >= uint64(len(results)) {
+		return nil
+	}
+	return results[index].Result
+}
@@ -0,0 +1,69 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yoc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// Tests that block traces are served from the cache only for the tracer they
+// were produced with, and dropped once out of the retention window.
+func TestBlockTraceCache(t *testing.T) {
+	cache := &blockTraceCache{
+		db:     yocdb.NewMemDatabase(),
+		tracer: "callTracer",
+		blocks: 2,
+	}
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+
+	blocks := make([]*types.Block, 4)
+	for i := range blocks {
+		blocks[i] = types.NewBlock(&types.Header{Number: big.NewInt(int64(i + 1))}, []*types.Transaction{tx}, nil, nil)
+		cache.put(blocks[i], []*txTraceResult{{Result: json.RawMessage(`{"type":"CALL"}`)}})
+	}
+	// Failed traces must not be cached
+	failed := types.NewBlock(&types.Header{Number: big.NewInt(5)}, []*types.Transaction{tx}, nil, nil)
+	cache.put(failed, []*txTraceResult{{Error: "execution timeout"}})
+	if results := cache.get(failed, "callTracer"); results != nil {
+		t.Fatalf("failed traces cached: %v", results)
+	}
+	// Traces must only be served for the same block and tracer
+	results := cache.get(blocks[0], "callTracer")
+	if len(results) != 1 || string(results[0].Result.(json.RawMessage)) != `{"type":"CALL"}` {
+		t.Fatalf("cached traces mismatch: have %v", results)
+	}
+	if results := cache.get(blocks[0], "prestateTracer"); results != nil {
+		t.Errorf("traces of other tracer served: %v", results)
+	}
+	sidechain := types.NewBlock(&types.Header{Number: big.NewInt(1), Extra: []byte("side")}, []*types.Transaction{tx}, nil, nil)
+	if results := cache.get(sidechain, "callTracer"); results != nil {
+		t.Errorf("traces of other block served: %v", results)
+	}
+	// Block-level state diffs must be served after the transaction traces
+	preamble := types.NewBlock(&types.Header{Number: big.NewInt(4), Extra: []byte("preamble")}, []*types.Transaction{tx}, nil, nil)
+	cache.put(preamble, []*txTraceResult{{Result: json.RawMessage(`{}`)}, {Result: json.RawMessage(`{"0x01":{}}`), Block: true}})
+	results = cache.get(preamble, "callTracer")
+	if len(results) != 2 || results[0].Block || !results[1].Block || string(results[1].Result.(json.RawMessage)) != `{"0x01":{}}` {
+		t.Fatalf("cached block-level trace mismatch: have %v", results)
+	}
+	cache.put(blocks[3], []*txTraceResult{{Result: json.RawMessage(`{"type":"CALL"}`)}})
+
+	// Pruning must keep the traces of the retention window only
+	cache.prune(4)
+	for i, block := range blocks {
+		if cached := cache.get(block, "callTracer") != nil; cached != (i >= 2) {
+			t.Errorf("block #%d: cached mismatch: have %v, want %v", block.NumberU64(), cached, i >= 2)
+		}
+	}
+	if tail := rawdb.ReadTraceCacheTail(cache.db); tail == nil || *tail != 3 {
+		t.Errorf("tail mismatch: have %v, want 3", tail)
+	}
+}
@@ -0,0 +1,94 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yoc
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core"
+	"github.com/Yocoin15/Yocoin_Sources/core/rawdb"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/log"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+const (
+	// traceIndexSectionSize is the number of blocks in a trace index section.
+	traceIndexSectionSize = 4096
+
+	// traceIndexConfirms is the number of confirmation blocks before a trace
+	// index section is considered probably final and is indexed. The state of
+	// the blocks this far back is only retained by archive nodes.
+	traceIndexConfirms = 256
+
+	// traceIndexThrottling is the time to wait between processing two consecutive
+	// trace index sections, tracing being expensive.
+	traceIndexThrottling = 100 * time.Millisecond
+)
+
+// TraceIndexer implements a core.ChainIndexer, building up an index of blooms
+// of the addresses appearing in the call traces of every block, permitting the
+// trace filter to skip tracing blocks without matches.
+type TraceIndexer struct {
+	db    yocdb.Database // database instance to write index data into
+	chain *core.BlockChain
+	api   *PrivateTraceAPI // tracing API to produce the call traces with
+
+	batch yocdb.Batch // batch collecting the blooms of the current section
+}
+
+// NewTraceIndexer returns a chain indexer that generates the call trace blooms
+// of the canonical chain for fast trace filtering.
+func NewTraceIndexer(yoc *YoCoin) *core.ChainIndexer {
+	backend := &TraceIndexer{
+		db:    yoc.chainDb,
+		chain: yoc.blockchain,
+		api:   NewPrivateTraceAPI(yoc),
+	}
+	table := yocdb.NewTable(yoc.chainDb, string(rawdb.TraceIndexPrefix))
+
+	return core.NewChainIndexer(yoc.chainDb, table, backend, traceIndexSectionSize, traceIndexConfirms, traceIndexThrottling, "traces")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new trace index section.
+func (t *TraceIndexer) Reset(section uint64, lastSectionHead common.Hash) error {
+	t.batch = t.db.NewBatch()
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, tracing a block and adding the
+// bloom of its call traces into the index. Blocks failing to trace are left out
+// of the index, making the trace filter trace them on demand.
+func (t *TraceIndexer) Process(header *types.Header) {
+	block := t.chain.GetBlock(header.Hash(), header.Number.Uint64())
+	if block == nil || len(block.Transactions()) == 0 {
+		return
+	}
+	traces, err := t.api.traceBlock(context.Background(), block)
+	if err != nil {
+		log.Warn("Failed to index block traces", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+		return
+	}
+	bloom := new(big.Int)
+	for _, trace := range traces {
+		for _, addr := range []*common.Address{trace.Action.From, trace.Action.To, trace.Action.Address} {
+			if addr != nil {
+				bloom.Or(bloom, types.Bloom9(addr.Bytes()))
+			}
+		}
+		if trace.Result != nil && trace.Result.Address != nil {
+			bloom.Or(bloom, types.Bloom9(trace.Result.Address.Bytes()))
+		}
+	}
+	rawdb.WriteTraceBloom(t.batch, block.Hash(), block.NumberU64(), types.BytesToBloom(bloom.Bytes()))
+}
+
+// Commit implements core.ChainIndexerBackend, writing the blooms of the section
+// out into the database.
+func (t *TraceIndexer) Commit() error {
+	return t.batch.Write()
+}
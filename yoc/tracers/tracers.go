@@ -1,19 +1,36 @@
 // Authored and revised by YOC team, 2017-2018
 // License placeholder #1
 
-// Package tracers is a collection of JavaScript transaction tracers.
+// Package tracers is a collection of JavaScript and native transaction tracers.
 package tracers
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
+	"github.com/Yocoin15/Yocoin_Sources/core/vm"
 	"github.com/Yocoin15/Yocoin_Sources/yoc/tracers/internal/tracers"
 )
 
+// Interface is implemented by both the JavaScript and the native tracers,
+// producing a JSON result and interruptible mid-execution.
+type Interface interface {
+	vm.Tracer
+	GetResult() (json.RawMessage, error)
+	Stop(err error)
+}
+
 // all contains all the built in JavaScript tracers by name.
 var all = make(map[string]string)
 
+// native contains the constructors of the tracers implemented in Go by name.
+// They take precedence over the JavaScript tracers of the same name.
+var native = map[string]func() Interface{
+	"callTracer":     newCallTracer,
+	"prestateTracer": newPrestateTracer,
+}
+
 // camel converts a snake cased input string into a camel cased output.
 func camel(str string) string {
 	pieces := strings.Split(str, "_")
@@ -38,3 +55,17 @@ func tracer(name string) (string, bool) {
 	}
 	return "", false
 }
+
+// NewTracer instantiates the tracer of the given name, or a JavaScript tracer
+// evaluating the given code if there is no such tracer. Native implementations
+// are preferred over JavaScript ones.
+func NewTracer(code string) (Interface, error) {
+	if constructor, ok := native[code]; ok {
+		return constructor(), nil
+	}
+	tracer, err := New(code)
+	if err != nil {
+		return nil, err
+	}
+	return tracer, nil
+}
@@ -103,77 +103,137 @@ type callTracerTest struct {
 	Result  *callTrace    `json:"result"`
 }
 
-// Iterates over all the input-output datasets in the tracer test harness and
-// runs the JavaScript tracers against them.
-func TestCallTracer(t *testing.T) {
+// loadCallTracerTests reads all the call tracer tests from the test harness.
+func loadCallTracerTests(t *testing.T) map[string]*callTracerTest {
 	files, err := ioutil.ReadDir("testdata")
 	if err != nil {
 		t.Fatalf("failed to retrieve tracer test suite: %v", err)
 	}
+	tests := make(map[string]*callTracerTest)
 	for _, file := range files {
 		if !strings.HasPrefix(file.Name(), "call_tracer_") {
 			continue
 		}
-		file := file // capture range variable
-		t.Run(camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json")), func(t *testing.T) {
-			t.Parallel()
+		blob, err := ioutil.ReadFile(filepath.Join("testdata", file.Name()))
+		if err != nil {
+			t.Fatalf("failed to read testcase: %v", err)
+		}
+		test := new(callTracerTest)
+		if err := json.Unmarshal(blob, test); err != nil {
+			t.Fatalf("failed to parse testcase: %v", err)
+		}
+		tests[camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json"))] = test
+	}
+	return tests
+}
 
-			// Call tracer test found, read if from disk
-			blob, err := ioutil.ReadFile(filepath.Join("testdata", file.Name()))
-			if err != nil {
-				t.Fatalf("failed to read testcase: %v", err)
-			}
-			test := new(callTracerTest)
-			if err := json.Unmarshal(blob, test); err != nil {
-				t.Fatalf("failed to parse testcase: %v", err)
-			}
-			// Configure a blockchain with the given prestate
-			tx := new(types.Transaction)
-			if err := rlp.DecodeBytes(common.FromHex(test.Input), tx); err != nil {
-				t.Fatalf("failed to parse testcase input: %v", err)
-			}
-			signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
-			origin, _ := signer.Sender(tx)
-
-			context := vm.Context{
-				CanTransfer: core.CanTransfer,
-				Transfer:    core.Transfer,
-				Origin:      origin,
-				Coinbase:    test.Context.Miner,
-				BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
-				Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
-				Difficulty:  (*big.Int)(test.Context.Difficulty),
-				GasLimit:    uint64(test.Context.GasLimit),
-				GasPrice:    tx.GasPrice(),
-			}
-			statedb := tests.MakePreState(yocdb.NewMemDatabase(), test.Genesis.Alloc)
+// runTracer executes the transaction of a call tracer test with the given
+// tracer attached, returning the trace result.
+func runTracer(t *testing.T, test *callTracerTest, tracer Interface) json.RawMessage {
+	// Configure a blockchain with the given prestate
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(common.FromHex(test.Input), tx); err != nil {
+		t.Fatalf("failed to parse testcase input: %v", err)
+	}
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	origin, _ := signer.Sender(tx)
+
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Origin:      origin,
+		Coinbase:    test.Context.Miner,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		Difficulty:  (*big.Int)(test.Context.Difficulty),
+		GasLimit:    uint64(test.Context.GasLimit),
+		GasPrice:    tx.GasPrice(),
+	}
+	statedb := tests.MakePreState(yocdb.NewMemDatabase(), test.Genesis.Alloc)
 
-			// Create the tracer, the YVM environment and run it
-			tracer, err := New("callTracer")
-			if err != nil {
-				t.Fatalf("failed to create call tracer: %v", err)
-			}
-			yvm := vm.NewYVM(context, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+	// Create the YVM environment and run the tracer
+	yvm := vm.NewYVM(context, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(yvm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	if _, _, _, err = st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	// Retrieve the trace result
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	return res
+}
+
+// Iterates over all the input-output datasets in the tracer test harness and
+// runs the JavaScript and native call tracers against them.
+func TestCallTracer(t *testing.T) {
+	for name, test := range loadCallTracerTests(t) {
+		test := test // capture range variable
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-			msg, err := tx.AsMessage(signer)
+			js, err := New("callTracer")
 			if err != nil {
-				t.Fatalf("failed to prepare transaction for tracing: %v", err)
-			}
-			st := core.NewStateTransition(yvm, msg, new(core.GasPool).AddGas(tx.Gas()))
-			if _, _, _, err = st.TransitionDb(); err != nil {
-				t.Fatalf("failed to execute transaction: %v", err)
+				t.Fatalf("failed to create call tracer: %v", err)
 			}
-			// Retrieve the trace result and compare against the etalon
-			res, err := tracer.GetResult()
+			native, err := NewTracer("callTracer")
 			if err != nil {
-				t.Fatalf("failed to retrieve trace result: %v", err)
+				t.Fatalf("failed to create native call tracer: %v", err)
 			}
-			ret := new(callTrace)
-			if err := json.Unmarshal(res, ret); err != nil {
-				t.Fatalf("failed to unmarshal trace result: %v", err)
+			for _, tracer := range []Interface{js, native} {
+				res := runTracer(t, test, tracer)
+
+				// Compare the trace result against the etalon
+				ret := new(callTrace)
+				if err := json.Unmarshal(res, ret); err != nil {
+					t.Fatalf("failed to unmarshal trace result: %v", err)
+				}
+				if !reflect.DeepEqual(ret, test.Result) {
+					t.Fatalf("%T trace mismatch: have %+v, want %+v", tracer, ret, test.Result)
+				}
 			}
-			if !reflect.DeepEqual(ret, test.Result) {
-				t.Fatalf("trace mismatch: have %+v, want %+v", ret, test.Result)
+		})
+	}
+}
+
+// Tests that the native tracers produce the same results as their JavaScript
+// counterparts.
+func TestNativeTracers(t *testing.T) {
+	for name, test := range loadCallTracerTests(t) {
+		test := test // capture range variable
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, name := range []string{"callTracer", "prestateTracer"} {
+				js, err := New(name)
+				if err != nil {
+					t.Fatalf("failed to create %s: %v", name, err)
+				}
+				native, err := NewTracer(name)
+				if err != nil {
+					t.Fatalf("failed to create native %s: %v", name, err)
+				}
+				var have, want interface{}
+				if err := json.Unmarshal(runTracer(t, test, native), &have); err != nil {
+					t.Fatalf("failed to unmarshal native %s result: %v", name, err)
+				}
+				if err := json.Unmarshal(runTracer(t, test, js), &want); err != nil {
+					t.Fatalf("failed to unmarshal %s result: %v", name, err)
+				}
+				// The execution time naturally differs between the runs
+				if name == "callTracer" {
+					delete(have.(map[string]interface{}), "time")
+					delete(want.(map[string]interface{}), "time")
+				}
+				if !reflect.DeepEqual(have, want) {
+					t.Fatalf("%s result mismatch: have %v, want %v", name, have, want)
+				}
 			}
 		})
 	}
@@ -254,6 +254,17 @@ func (ec *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*
 	return r, err
 }
 
+// BlockReceipts returns the receipts of all the transactions in the block
+// identified by number or hash.
+func (ec *Client) BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	var r []*types.Receipt
+	err := ec.c.CallContext(ctx, &r, "yoc_getBlockReceipts", blockNrOrHash)
+	if err == nil && r == nil {
+		return nil, yocoin.NotFound
+	}
+	return r, err
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
@@ -300,6 +311,12 @@ func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header)
 	return ec.c.YocSubscribe(ctx, ch, "newHeads")
 }
 
+// SubscribeNewBlockReceipts subscribes to notifications about the receipts of
+// the transactions in each new block appended to the chain.
+func (ec *Client) SubscribeNewBlockReceipts(ctx context.Context, ch chan<- []*types.Receipt) (yocoin.Subscription, error) {
+	return ec.c.YocSubscribe(ctx, ch, "newBlockReceipts")
+}
+
 // State Access
 
 // NetworkID returns the network ID (also known as the chain ID) for this chain.
@@ -412,7 +429,11 @@ func (ec *Client) PendingTransactionCount(ctx context.Context) (uint, error) {
 	return uint(num), err
 }
 
-// TODO: SubscribePendingTransactions (needs server side)
+// SubscribePendingTransactions subscribes to notifications about the transactions
+// entering the pending state.
+func (ec *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (yocoin.Subscription, error) {
+	return ec.c.YocSubscribe(ctx, ch, "newPendingTransactions", map[string]interface{}{"fullTx": true})
+}
 
 // Contract Calling
 
@@ -452,6 +473,49 @@ func (ec *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	return (*big.Int)(&hex), nil
 }
 
+// SuggestGasPrices retrieves slow, normal and fast gas price suggestions, taking
+// the content of the pending transaction pool into account.
+func (ec *Client) SuggestGasPrices(ctx context.Context) (*yocoin.GasPrices, error) {
+	var res struct {
+		Slow   *hexutil.Big `json:"slow"`
+		Normal *hexutil.Big `json:"normal"`
+		Fast   *hexutil.Big `json:"fast"`
+	}
+	if err := ec.c.CallContext(ctx, &res, "yoc_gasPrices"); err != nil {
+		return nil, err
+	}
+	return &yocoin.GasPrices{
+		Slow:   (*big.Int)(res.Slow),
+		Normal: (*big.Int)(res.Normal),
+		Fast:   (*big.Int)(res.Fast),
+	}, nil
+}
+
+// FeeHistory retrieves the fee market history of up to blockCount blocks ending
+// with lastBlock, including the gas prices paid at the given percentiles.
+func (ec *Client) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*yocoin.FeeHistory, error) {
+	var res struct {
+		OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+		Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+		GasUsedRatio []float64        `json:"gasUsedRatio"`
+	}
+	if err := ec.c.CallContext(ctx, &res, "yoc_feeHistory", hexutil.Uint(blockCount), toBlockNumArg(lastBlock), rewardPercentiles); err != nil {
+		return nil, err
+	}
+	reward := make([][]*big.Int, len(res.Reward))
+	for i, prices := range res.Reward {
+		reward[i] = make([]*big.Int, len(prices))
+		for j, price := range prices {
+			reward[i][j] = (*big.Int)(price)
+		}
+	}
+	return &yocoin.FeeHistory{
+		OldestBlock:  (*big.Int)(res.OldestBlock),
+		Reward:       reward,
+		GasUsedRatio: res.GasUsedRatio,
+	}, nil
+}
+
 // EstimateGas tries to estimate the gas needed to execute a specific transaction based on
 // the current pending state of the backend blockchain. There is no guarantee that this is
 // the true gas limit requirement as other transactions may be added or removed by miners,
@@ -0,0 +1,174 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yocclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/common/hexutil"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/rlp"
+	"github.com/Yocoin15/Yocoin_Sources/trie"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// AccountResult is the result of a GetProof operation: the account fields along
+// with the merkle proofs of the account and the requested storage slots.
+type AccountResult struct {
+	Address      common.Address
+	AccountProof [][]byte
+	Balance      *big.Int
+	CodeHash     common.Hash
+	Nonce        uint64
+	StorageHash  common.Hash
+	StorageProof []StorageResult
+}
+
+// StorageResult is the value and merkle proof of a single storage slot.
+type StorageResult struct {
+	Key   common.Hash
+	Value *big.Int
+	Proof [][]byte
+}
+
+type rpcAccountResult struct {
+	Address      common.Address     `json:"address"`
+	AccountProof []hexutil.Bytes    `json:"accountProof"`
+	Balance      *hexutil.Big       `json:"balance"`
+	CodeHash     common.Hash        `json:"codeHash"`
+	Nonce        hexutil.Uint64     `json:"nonce"`
+	StorageHash  common.Hash        `json:"storageHash"`
+	StorageProof []rpcStorageResult `json:"storageProof"`
+}
+
+type rpcStorageResult struct {
+	Key   string          `json:"key"`
+	Value *hexutil.Big    `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// GetProof returns the account and storage values of the specified account
+// including the merkle proofs. The block number can be nil, in which case the
+// value is taken from the latest known block.
+func (ec *Client) GetProof(ctx context.Context, account common.Address, keys []common.Hash, blockNumber *big.Int) (*AccountResult, error) {
+	hexKeys := make([]string, len(keys))
+	for i, key := range keys {
+		hexKeys[i] = key.Hex()
+	}
+	var res rpcAccountResult
+	if err := ec.c.CallContext(ctx, &res, "yoc_getProof", account, hexKeys, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	if res.Balance == nil {
+		return nil, fmt.Errorf("missing account balance in proof of %x", account)
+	}
+	result := &AccountResult{
+		Address:      res.Address,
+		AccountProof: toByteSlices(res.AccountProof),
+		Balance:      (*big.Int)(res.Balance),
+		CodeHash:     res.CodeHash,
+		Nonce:        uint64(res.Nonce),
+		StorageHash:  res.StorageHash,
+		StorageProof: make([]StorageResult, len(res.StorageProof)),
+	}
+	for i, slot := range res.StorageProof {
+		if slot.Value == nil {
+			return nil, fmt.Errorf("missing value of storage slot %s in proof of %x", slot.Key, account)
+		}
+		result.StorageProof[i] = StorageResult{
+			Key:   common.HexToHash(slot.Key),
+			Value: (*big.Int)(slot.Value),
+			Proof: toByteSlices(slot.Proof),
+		}
+	}
+	return result, nil
+}
+
+// VerifyProof checks that the account and storage values of a GetProof result
+// are proven by its merkle proofs against the given state root, which should be
+// taken from a trusted block header. Proofs of absence are accepted for accounts
+// and slots reported as empty.
+func VerifyProof(root common.Hash, result *AccountResult) error {
+	// Verify the account against the state root
+	value, _, err := trie.VerifyProof(root, crypto.Keccak256(result.Address.Bytes()), newProofSet(result.AccountProof))
+	if err != nil {
+		return fmt.Errorf("invalid account proof: %v", err)
+	}
+	want := state.Account{
+		Nonce:    result.Nonce,
+		Balance:  result.Balance,
+		Root:     result.StorageHash,
+		CodeHash: result.CodeHash.Bytes(),
+	}
+	if value == nil {
+		// The account doesn't exist, which is only fine if it was reported empty
+		empty := state.Account{
+			Balance:  new(big.Int),
+			Root:     types.EmptyRootHash,
+			CodeHash: crypto.Keccak256(nil),
+		}
+		if !accountEqual(&want, &empty) {
+			return fmt.Errorf("account %x proven absent but reported %+v", result.Address, want)
+		}
+	} else {
+		var have state.Account
+		if err := rlp.DecodeBytes(value, &have); err != nil {
+			return fmt.Errorf("invalid account encoding: %v", err)
+		}
+		if !accountEqual(&have, &want) {
+			return fmt.Errorf("account %x mismatch: proven %+v, reported %+v", result.Address, have, want)
+		}
+	}
+	// Verify each storage slot against the account storage root
+	for _, slot := range result.StorageProof {
+		have := new(big.Int)
+		if len(slot.Proof) > 0 {
+			value, _, err := trie.VerifyProof(result.StorageHash, crypto.Keccak256(slot.Key.Bytes()), newProofSet(slot.Proof))
+			if err != nil {
+				return fmt.Errorf("invalid proof of storage slot %x: %v", slot.Key, err)
+			}
+			if value != nil {
+				var content []byte
+				if err := rlp.DecodeBytes(value, &content); err != nil {
+					return fmt.Errorf("invalid encoding of storage slot %x: %v", slot.Key, err)
+				}
+				have.SetBytes(content)
+			}
+		} else if result.StorageHash != types.EmptyRootHash {
+			return fmt.Errorf("missing proof of storage slot %x", slot.Key)
+		}
+		if have.Cmp(slot.Value) != 0 {
+			return fmt.Errorf("storage slot %x mismatch: proven %v, reported %v", slot.Key, have, slot.Value)
+		}
+	}
+	return nil
+}
+
+// newProofSet creates a database of proof nodes keyed by their hashes.
+func newProofSet(proof [][]byte) *yocdb.MemDatabase {
+	db := yocdb.NewMemDatabase()
+	for _, node := range proof {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	return db
+}
+
+// accountEqual reports whether two accounts have identical consensus fields.
+func accountEqual(a, b *state.Account) bool {
+	return a.Nonce == b.Nonce && a.Balance.Cmp(b.Balance) == 0 && a.Root == b.Root && bytes.Equal(a.CodeHash, b.CodeHash)
+}
+
+func toByteSlices(items []hexutil.Bytes) [][]byte {
+	res := make([][]byte, len(items))
+	for i, item := range items {
+		res[i] = item
+	}
+	return res
+}
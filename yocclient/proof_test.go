@@ -0,0 +1,108 @@
+// Authored and revised by YOC team, 2019
+// License placeholder #1
+
+package yocclient
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/Yocoin15/Yocoin_Sources/core/state"
+	"github.com/Yocoin15/Yocoin_Sources/core/types"
+	"github.com/Yocoin15/Yocoin_Sources/crypto"
+	"github.com/Yocoin15/Yocoin_Sources/yocdb"
+)
+
+// newProofState creates a committed state with a single contract account with
+// some storage, returning the state and its root.
+func newProofState(t *testing.T, addr common.Address, slot, value common.Hash) (*state.StateDB, common.Hash) {
+	db := state.NewDatabase(yocdb.NewMemDatabase())
+	statedb, _ := state.New(common.Hash{}, db)
+	statedb.SetBalance(addr, big.NewInt(42))
+	statedb.SetNonce(addr, 7)
+	statedb.SetCode(addr, []byte{0x60, 0x00})
+	statedb.SetState(addr, slot, value)
+
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	statedb, _ = state.New(root, db)
+	return statedb, root
+}
+
+// makeProof assembles a GetProof result straight from the state, the same way
+// the RPC API does.
+func makeProof(t *testing.T, statedb *state.StateDB, addr common.Address, keys ...common.Hash) *AccountResult {
+	accountProof, err := statedb.GetProof(addr)
+	if err != nil {
+		t.Fatalf("failed to prove account: %v", err)
+	}
+	result := &AccountResult{
+		Address:      addr,
+		AccountProof: accountProof,
+		Balance:      statedb.GetBalance(addr),
+		CodeHash:     crypto.Keccak256Hash(nil),
+		Nonce:        statedb.GetNonce(addr),
+		StorageHash:  types.EmptyRootHash,
+	}
+	storageTrie := statedb.StorageTrie(addr)
+	if storageTrie != nil {
+		result.CodeHash = statedb.GetCodeHash(addr)
+		result.StorageHash = storageTrie.Hash()
+	}
+	for _, key := range keys {
+		slot := StorageResult{Key: key, Value: new(big.Int)}
+		if storageTrie != nil {
+			if slot.Proof, err = statedb.GetStorageProof(addr, key); err != nil {
+				t.Fatalf("failed to prove slot %x: %v", key, err)
+			}
+			slot.Value = statedb.GetState(addr, key).Big()
+		}
+		result.StorageProof = append(result.StorageProof, slot)
+	}
+	return result
+}
+
+// Tests that account and storage proofs produced from a state verify against
+// its root, and that tampered results are rejected.
+func TestVerifyProof(t *testing.T) {
+	var (
+		addr  = common.HexToAddress("0x1234")
+		slot  = common.HexToHash("0x01")
+		value = common.HexToHash("0xbeef")
+	)
+	statedb, root := newProofState(t, addr, slot, value)
+
+	// Existing account with an existing and a missing slot
+	result := makeProof(t, statedb, addr, slot, common.HexToHash("0x02"))
+	if err := VerifyProof(root, result); err != nil {
+		t.Fatalf("failed to verify valid proof: %v", err)
+	}
+	if result.StorageProof[0].Value.Cmp(value.Big()) != 0 {
+		t.Errorf("slot value mismatch: have %v, want %v", result.StorageProof[0].Value, value.Big())
+	}
+	// Non-existent account
+	if err := VerifyProof(root, makeProof(t, statedb, common.HexToAddress("0xdead"), slot)); err != nil {
+		t.Fatalf("failed to verify proof of absence: %v", err)
+	}
+	// Tampered account and storage values
+	tampered := makeProof(t, statedb, addr, slot)
+	tampered.Balance = big.NewInt(43)
+	if err := VerifyProof(root, tampered); err == nil {
+		t.Errorf("tampered balance accepted")
+	}
+	tampered = makeProof(t, statedb, addr, slot)
+	tampered.StorageProof[0].Value = big.NewInt(1)
+	if err := VerifyProof(root, tampered); err == nil {
+		t.Errorf("tampered slot value accepted")
+	}
+	// Proof against a different root
+	if err := VerifyProof(common.HexToHash("0xff"), makeProof(t, statedb, addr)); err == nil {
+		t.Errorf("proof accepted against wrong root")
+	}
+}
@@ -115,6 +115,41 @@ func (db *LDBDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
 	return db.db.NewIterator(util.BytesPrefix(prefix), nil)
 }
 
+// HasAncient returns an error as the leveldb database has no ancient store attached.
+func (db *LDBDatabase) HasAncient(kind string, number uint64) (bool, error) {
+	return false, errNotSupported
+}
+
+// Ancient returns an error as the leveldb database has no ancient store attached.
+func (db *LDBDatabase) Ancient(kind string, number uint64) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// Ancients returns an error as the leveldb database has no ancient store attached.
+func (db *LDBDatabase) Ancients() (uint64, error) {
+	return 0, errNotSupported
+}
+
+// AncientSize returns an error as the leveldb database has no ancient store attached.
+func (db *LDBDatabase) AncientSize(kind string) (uint64, error) {
+	return 0, errNotSupported
+}
+
+// AppendAncient returns an error as the leveldb database has no ancient store attached.
+func (db *LDBDatabase) AppendAncient(number uint64, hash, header, body, receipts, td []byte) error {
+	return errNotSupported
+}
+
+// TruncateAncients returns an error as the leveldb database has no ancient store attached.
+func (db *LDBDatabase) TruncateAncients(items uint64) error {
+	return errNotSupported
+}
+
+// Sync returns an error as the leveldb database has no ancient store attached.
+func (db *LDBDatabase) Sync() error {
+	return errNotSupported
+}
+
 func (db *LDBDatabase) Close() {
 	// Stop the metrics collection to avoid internal database races
 	db.quitLock.Lock()
@@ -401,10 +436,80 @@ func (dt *table) Delete(key []byte) error {
 	return dt.db.Delete(append([]byte(dt.prefix), key...))
 }
 
+// NewIteratorWithPrefix returns an iterator over the table content with a
+// particular prefix, stripping the table prefix from the iterated keys.
+func (dt *table) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
+	return &tableIterator{
+		Iterator: dt.db.NewIteratorWithPrefix(append([]byte(dt.prefix), prefix...)),
+		prefix:   dt.prefix,
+	}
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists in
+// the ancient store of the underlying database.
+func (dt *table) HasAncient(kind string, number uint64) (bool, error) {
+	return dt.db.HasAncient(kind, number)
+}
+
+// Ancient retrieves an ancient binary blob from the underlying database.
+func (dt *table) Ancient(kind string, number uint64) ([]byte, error) {
+	return dt.db.Ancient(kind, number)
+}
+
+// Ancients returns the number of items in the ancient store of the underlying
+// database.
+func (dt *table) Ancients() (uint64, error) {
+	return dt.db.Ancients()
+}
+
+// AncientSize returns the ancient size of the specified category in the
+// underlying database.
+func (dt *table) AncientSize(kind string) (uint64, error) {
+	return dt.db.AncientSize(kind)
+}
+
+// AppendAncient injects all binary blobs belonging to a block at the end of the
+// ancient store of the underlying database.
+func (dt *table) AppendAncient(number uint64, hash, header, body, receipts, td []byte) error {
+	return dt.db.AppendAncient(number, hash, header, body, receipts, td)
+}
+
+// TruncateAncients discards all but the first n ancient data from the ancient
+// store of the underlying database.
+func (dt *table) TruncateAncients(items uint64) error {
+	return dt.db.TruncateAncients(items)
+}
+
+// Sync flushes all in-memory ancient data of the underlying database to disk.
+func (dt *table) Sync() error {
+	return dt.db.Sync()
+}
+
 func (dt *table) Close() {
 	// Do nothing; don't close the underlying DB.
 }
 
+// tableIterator wraps an iterator of the host database, hiding the table prefix.
+type tableIterator struct {
+	iterator.Iterator
+	prefix string
+}
+
+// Key returns the key of the current entry without the table prefix.
+func (it *tableIterator) Key() []byte {
+	key := it.Iterator.Key()
+	if key == nil {
+		return nil
+	}
+	return key[len(it.prefix):]
+}
+
+// Seek moves the iterator to the first entry with a key at or after the given
+// table key.
+func (it *tableIterator) Seek(key []byte) bool {
+	return it.Iterator.Seek(append([]byte(it.prefix), key...))
+}
+
 type tableBatch struct {
 	batch  Batch
 	prefix string
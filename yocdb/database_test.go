@@ -197,3 +197,43 @@ func testParallelPutGet(db yocdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_IteratorWithPrefix(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIteratorWithPrefix(db, t)
+}
+
+func TestMemoryDB_IteratorWithPrefix(t *testing.T) {
+	testIteratorWithPrefix(yocdb.NewMemDatabase(), t)
+}
+
+func TestTable_IteratorWithPrefix(t *testing.T) {
+	db := yocdb.NewMemDatabase()
+	db.Put([]byte("ab"), []byte("outside"))
+	testIteratorWithPrefix(yocdb.NewTable(db, "t-"), t)
+}
+
+func testIteratorWithPrefix(db yocdb.Database, t *testing.T) {
+	for _, k := range []string{"b2", "a", "b1", "c", "b3", "ba"} {
+		if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	it := db.NewIteratorWithPrefix([]byte("b"))
+	defer it.Release()
+
+	var keys []string
+	for it.Next() {
+		if want := "v" + string(it.Key()); string(it.Value()) != want {
+			t.Errorf("value mismatch for %q: have %q, want %q", it.Key(), it.Value(), want)
+		}
+		keys = append(keys, string(it.Key()))
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if have, want := fmt.Sprint(keys), "[b1 b2 b3 ba]"; have != want {
+		t.Errorf("iterated keys mismatch: have %s, want %s", have, want)
+	}
+}
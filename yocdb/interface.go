@@ -3,10 +3,20 @@
 
 package yocdb
 
+import (
+	"errors"
+
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+)
+
 // Code using batches should try to add this much data to the batch.
 // The value was determined empirically.
 const IdealBatchSize = 100 * 1024
 
+// errNotSupported is returned by the ancient store methods of databases which
+// don't have an ancient store attached.
+var errNotSupported = errors.New("this operation is not supported")
+
 // Putter wraps the database write operation supported by both batches and regular databases.
 type Putter interface {
 	Put(key []byte, value []byte) error
@@ -17,10 +27,58 @@ type Deleter interface {
 	Delete(key []byte) error
 }
 
+// Iteratee wraps the iteration over a subset of the database content, supported
+// by regular databases.
+type Iteratee interface {
+	// NewIteratorWithPrefix creates a binary-alphabetical iterator over the subset
+	// of database content with a particular key prefix.
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
+
+// AncientReader contains the methods required to read from immutable ancient
+// chain data, kept outside of the key-value store.
+type AncientReader interface {
+	// HasAncient returns an indicator whether the specified data exists in the
+	// ancient store.
+	HasAncient(kind string, number uint64) (bool, error)
+
+	// Ancient retrieves an ancient binary blob from the append-only immutable files.
+	Ancient(kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the ancient item numbers in the ancient store.
+	Ancients() (uint64, error)
+
+	// AncientSize returns the ancient size of the specified category.
+	AncientSize(kind string) (uint64, error)
+}
+
+// AncientWriter contains the methods required to write to immutable ancient
+// chain data.
+type AncientWriter interface {
+	// AppendAncient injects all binary blobs belong to block at the end of the
+	// append-only immutable table files.
+	AppendAncient(number uint64, hash, header, body, receipts, td []byte) error
+
+	// TruncateAncients discards all but the first n ancient data from the ancient store.
+	TruncateAncients(n uint64) error
+
+	// Sync flushes all in-memory ancient store data to disk.
+	Sync() error
+}
+
+// AncientStore contains all the methods required to allow handling different
+// ancient data stores backing immutable chain data store.
+type AncientStore interface {
+	AncientReader
+	AncientWriter
+}
+
 // Database wraps all database operations. All methods are safe for concurrent use.
 type Database interface {
 	Putter
 	Deleter
+	Iteratee
+	AncientStore
 	Get(key []byte) ([]byte, error)
 	Has(key []byte) (bool, error)
 	Close()
@@ -4,10 +4,14 @@
 package yocdb
 
 import (
+	"bytes"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/Yocoin15/Yocoin_Sources/common"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 )
 
 /*
@@ -67,6 +71,29 @@ func (db *MemDatabase) Keys() [][]byte {
 	return keys
 }
 
+// NewIteratorWithPrefix returns an iterator over a snapshot of the database
+// content with a particular prefix.
+func (db *MemDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var (
+		pr     = string(prefix)
+		keys   = make([]string, 0, len(db.db))
+		values = make([][]byte, 0, len(db.db))
+	)
+	for key := range db.db {
+		if strings.HasPrefix(key, pr) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values = append(values, common.CopyBytes(db.db[key]))
+	}
+	return iterator.NewArrayIterator(&memSnapshot{keys: keys, values: values})
+}
+
 func (db *MemDatabase) Delete(key []byte) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
@@ -75,6 +102,41 @@ func (db *MemDatabase) Delete(key []byte) error {
 	return nil
 }
 
+// HasAncient returns an error as the memory database has no ancient store attached.
+func (db *MemDatabase) HasAncient(kind string, number uint64) (bool, error) {
+	return false, errNotSupported
+}
+
+// Ancient returns an error as the memory database has no ancient store attached.
+func (db *MemDatabase) Ancient(kind string, number uint64) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// Ancients returns an error as the memory database has no ancient store attached.
+func (db *MemDatabase) Ancients() (uint64, error) {
+	return 0, errNotSupported
+}
+
+// AncientSize returns an error as the memory database has no ancient store attached.
+func (db *MemDatabase) AncientSize(kind string) (uint64, error) {
+	return 0, errNotSupported
+}
+
+// AppendAncient returns an error as the memory database has no ancient store attached.
+func (db *MemDatabase) AppendAncient(number uint64, hash, header, body, receipts, td []byte) error {
+	return errNotSupported
+}
+
+// TruncateAncients returns an error as the memory database has no ancient store attached.
+func (db *MemDatabase) TruncateAncients(items uint64) error {
+	return errNotSupported
+}
+
+// Sync returns an error as the memory database has no ancient store attached.
+func (db *MemDatabase) Sync() error {
+	return errNotSupported
+}
+
 func (db *MemDatabase) Close() {}
 
 func (db *MemDatabase) NewBatch() Batch {
@@ -83,6 +145,25 @@ func (db *MemDatabase) NewBatch() Batch {
 
 func (db *MemDatabase) Len() int { return len(db.db) }
 
+// memSnapshot is a sorted, immutable copy of a subset of the memory database
+// content, iterated over by the database iterators.
+type memSnapshot struct {
+	keys   []string
+	values [][]byte
+}
+
+func (s *memSnapshot) Len() int { return len(s.keys) }
+
+func (s *memSnapshot) Search(key []byte) int {
+	return sort.Search(len(s.keys), func(i int) bool {
+		return bytes.Compare([]byte(s.keys[i]), key) >= 0
+	})
+}
+
+func (s *memSnapshot) Index(i int) ([]byte, []byte) {
+	return []byte(s.keys[i]), s.values[i]
+}
+
 type kv struct {
 	k, v []byte
 	del  bool